@@ -0,0 +1,369 @@
+package controllers
+
+import (
+	"strconv"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetUsers method to get list of users by given filter (with pagination).
+func (h *Handler) GetUsers(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "users", err.Error())
+	}
+
+	// Create a new users filter struct with default pagination.
+	usersFilter := &models.UsersFilter{Page: 1, Limit: 20}
+
+	// Checking received data from URL query.
+	if err := c.QueryParser(usersFilter); err != nil {
+		return utilities.CheckForError(c, err, 400, "users filter", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(usersFilter); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "users filter")
+	}
+
+	// Get users by filter.
+	foundedUsers, status, err := h.DB.GetUsers(c.Context(), usersFilter)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "users", err.Error())
+	}
+
+	// Get count of users by filter.
+	count, status, err := h.DB.GetUsersCount(c.Context(), usersFilter)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "users", err.Error())
+	}
+
+	// Clear no needed fields from JSON output.
+	for index := range foundedUsers {
+		foundedUsers[index].PasswordHash = ""
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"users":  foundedUsers,
+		"count":  count,
+		"page":   usersFilter.Page,
+		"limit":  usersFilter.Limit,
+	})
+}
+
+// GetUser method to get one user by given ID.
+func (h *Handler) GetUser(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user", err.Error())
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Clear no needed fields from JSON output.
+	foundedUser.PasswordHash = ""
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"user":   foundedUser,
+	})
+}
+
+// UpdateUserStatus method to change user status (activate, block or unblock) by given ID.
+func (h *Handler) UpdateUserStatus(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user status", err.Error())
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Checking, if admin is trying to change own status.
+	if userID == claims.UserID {
+		return utilities.ThrowJSONError(c, 403, "user status", "can't change own status")
+	}
+
+	// Create a new UpdateUserStatus struct.
+	updateStatus := &models.UpdateUserStatus{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(updateStatus); err != nil {
+		return utilities.CheckForError(c, err, 400, "user status", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(updateStatus); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "user status")
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Update user status and revoke sessions of the blocked user in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Update user status.
+		if err := r.UpdateUserStatus(c.Context(), foundedUser.ID, updateStatus.UserStatus); err != nil {
+			return helpers.NewTxError(err, 400, "user status")
+		}
+
+		// Revoke all sessions of the blocked user.
+		if updateStatus.UserStatus == 2 {
+			if err := r.DeleteSessionsByUserID(c.Context(), foundedUser.ID); err != nil {
+				return helpers.NewTxError(err, 400, "sessions")
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventUserStatusChange, claims.UserID, foundedUser.ID, models.AuditEventDetails{
+		"user_status": strconv.Itoa(updateStatus.UserStatus),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// UpdateUserRole method to change user role by given ID.
+func (h *Handler) UpdateUserRole(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user role", err.Error())
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Checking, if admin is trying to change own role.
+	if userID == claims.UserID {
+		return utilities.ThrowJSONError(c, 403, "user role", "can't change own role")
+	}
+
+	// Create a new UpdateUserRole struct.
+	updateRole := &models.UpdateUserRole{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(updateRole); err != nil {
+		return utilities.CheckForError(c, err, 400, "user role", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(updateRole); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "user role")
+	}
+
+	// Get role by given ID.
+	foundedRole, status, err := h.DB.GetRoleByID(c.Context(), updateRole.UserRole)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "role", err.Error())
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Update user role.
+	if err := h.Users.UpdateUserRole(c.Context(), foundedUser.ID, foundedRole.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "user role", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventUserRoleChange, claims.UserID, foundedUser.ID, models.AuditEventDetails{
+		"user_role": strconv.Itoa(foundedRole.ID),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// ForceUserPasswordReset method to reset user password by given ID
+// (current password stops working, all sessions are revoked and a new reset code is created).
+func (h *Handler) ForceUserPasswordReset(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user password", err.Error())
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Generate a new random string for the password with nanoID (nobody knows it).
+	randomString, err := utilities.GenerateNewNanoID("", 32)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+	}
+
+	// Create a new hash for the random password.
+	passwordHash, err := helpers.GeneratePasswordHash(randomString)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+	}
+
+	// Generate a new reset code with nanoID.
+	randomResetCode, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 14)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+	}
+
+	// Create a new ResetCode struct for reset code.
+	resetCode := &models.ResetCode{}
+
+	// Set data for reset code:
+	resetCode.Code = randomResetCode
+	resetCode.ExpireAt = time.Now().Add(time.Hour * 2) // set 2 hour expiration time
+	resetCode.Email = foundedUser.Email
+
+	// Reset password, revoke sessions and replace reset codes of the user in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Update user password to random generated by nanoID.
+		if err := r.UpdateUserPassword(c.Context(), foundedUser.ID, passwordHash); err != nil {
+			return helpers.NewTxError(err, 400, "user")
+		}
+
+		// Revoke all sessions of the user.
+		if err := r.DeleteSessionsByUserID(c.Context(), foundedUser.ID); err != nil {
+			return helpers.NewTxError(err, 400, "sessions")
+		}
+
+		// Deleting all previously created reset codes for the user email.
+		if err := r.DeleteResetCodesByEmail(c.Context(), foundedUser.Email); err != nil {
+			return helpers.NewTxError(err, 400, "reset code")
+		}
+
+		// Create a new reset code.
+		if err := r.CreateNewResetCode(c.Context(), resetCode); err != nil {
+			return helpers.NewTxError(err, 400, "reset code")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Record audit event.
+	audit.Success(c, audit.EventUserPasswordReset, claims.UserID, foundedUser.ID)
+
+	// Return status 201 created.
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// DeleteUserSessions method to revoke all sessions of the user by given ID.
+func (h *Handler) DeleteUserSessions(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "sessions", err.Error())
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Revoke all sessions of the user.
+	if err := h.Sessions.DeleteSessionsByUserID(c.Context(), foundedUser.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "sessions", err.Error())
+	}
+
+	// Record audit event.
+	audit.Success(c, audit.EventUserSessionsRevoke, claims.UserID, foundedUser.ID)
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package controllers
+
+import (
+	"Komentory/auth/app/models"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetSecurityLog method to get audit events of the current user (with pagination).
+func (h *Handler) GetSecurityLog(c *fiber.Ctx) error {
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "security log", err.Error())
+	}
+
+	// Create a new audit events filter struct with default pagination.
+	eventsFilter := &models.AuditEventsFilter{Page: 1, Limit: 20}
+
+	// Checking received data from URL query.
+	if err := c.QueryParser(eventsFilter); err != nil {
+		return utilities.CheckForError(c, err, 400, "security log filter", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(eventsFilter); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "security log filter")
+	}
+
+	// Get audit events of the user from JWT.
+	foundedEvents, status, err := h.DB.GetAuditEventsByUserID(c.Context(), claims.UserID, eventsFilter)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "security log", err.Error())
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"events": foundedEvents,
+		"page":   eventsFilter.Page,
+		"limit":  eventsFilter.Limit,
+	})
+}
+
+// GetAuditEvents method to get audit events by given filter (with pagination).
+func (h *Handler) GetAuditEvents(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("audit_events", "read", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "audit events", err.Error())
+	}
+
+	// Create a new audit events filter struct with default pagination.
+	eventsFilter := &models.AuditEventsFilter{Page: 1, Limit: 20}
+
+	// Checking received data from URL query.
+	if err := c.QueryParser(eventsFilter); err != nil {
+		return utilities.CheckForError(c, err, 400, "audit events filter", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(eventsFilter); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "audit events filter")
+	}
+
+	// Get audit events by filter.
+	foundedEvents, status, err := h.DB.GetAuditEvents(c.Context(), eventsFilter)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "audit events", err.Error())
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"events": foundedEvents,
+		"page":   eventsFilter.Page,
+		"limit":  eventsFilter.Limit,
+	})
+}
@@ -0,0 +1,344 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/pkg/mailer"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreateNewEmailChange method to create a new request to change email of the current user.
+// Confirm code is sent to the new email, revert link is sent to the old one.
+func (h *Handler) CreateNewEmailChange(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("user_email", "update", true),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "update user email", err.Error())
+	}
+
+	// Create a new email change struct.
+	newEmailChange := &models.NewEmailChange{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(newEmailChange); err != nil {
+		return utilities.CheckForError(c, err, 400, "email change", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(newEmailChange); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "email change")
+	}
+
+	// Get user by ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Compare given user password with stored hash of the found user.
+	matchUserPassword, _, status, err := h.comparePassword(c.Context(), foundedUser.ID, newEmailChange.Password)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+	if !matchUserPassword {
+		// Record audit event.
+		audit.Failure(c, audit.EventEmailChangeRequest, claims.UserID, foundedUser.ID, "wrong password")
+
+		return utilities.ThrowJSONError(c, 403, "user", "email or password")
+	}
+
+	// Checking, if the new email is the same.
+	if newEmailChange.NewEmail == foundedUser.Email {
+		return utilities.ThrowJSONError(c, 400, "email change", "new email is the same")
+	}
+
+	// Checking, if the new email is already taken.
+	_, status, err = h.Users.GetUserByEmail(c.Context(), newEmailChange.NewEmail)
+	if err == nil {
+		return utilities.ThrowJSONError(c, 400, "email change", "email is already taken")
+	}
+	if status != 404 {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Generate a new confirm code with nanoID.
+	confirmCode, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 14)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+	}
+
+	// Generate a new revert code with nanoID.
+	revertCode, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 32)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+	}
+
+	// Set now time.
+	now := time.Now()
+
+	// Create a new EmailChange struct.
+	emailChange := &models.EmailChange{
+		ID:              uuid.New(),
+		CreatedAt:       &now,
+		UserID:          foundedUser.ID,
+		OldEmail:        foundedUser.Email,
+		NewEmail:        newEmailChange.NewEmail,
+		ConfirmCode:     confirmCode,
+		ConfirmExpireAt: now.Add(time.Hour * 2), // set 2 hour expiration time
+		RevertCode:      revertCode,
+		RevertExpireAt:  now.Add(time.Hour * 24), // set 24 hour expiration time
+	}
+
+	// Validate email change fields.
+	if err := validate.Struct(emailChange); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "email change")
+	}
+
+	// Create a new email change with validated data
+	// (previously created, but not confirmed email changes of the user are deleted in transaction).
+	if err := h.EmailChanges.CreateNewEmailChange(c.Context(), emailChange); err != nil {
+		return utilities.CheckForError(c, err, 400, "email change", err.Error())
+	}
+
+	// Send confirm code to the new email.
+	if err := mailer.Send(&mailer.Message{
+		To:       emailChange.NewEmail,
+		Subject:  "Confirm your new email",
+		TextBody: "Your code to confirm the new email of your account: " + confirmCode + " (valid for 2 hours).",
+	}); err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "mailer", err.Error())
+	}
+
+	// Send revert link to the old email (errors are only logged).
+	if err := mailer.Send(&mailer.Message{
+		To:      emailChange.OldEmail,
+		Subject: "Email of your account is being changed",
+		TextBody: "Email of your account is being changed to " + emailChange.NewEmail +
+			". If it wasn't you, please revert it (valid for 24 hours): " + os.Getenv("EMAIL_REVERT_URL") + "?code=" + revertCode,
+	}); err != nil {
+		log.Printf("Oops... Email change notification is not sent! Reason: %v", err)
+	}
+
+	// Record audit event (emails are personal data, so only ID of the change is stored in append-only log).
+	audit.SuccessWithDetails(c, audit.EventEmailChangeRequest, claims.UserID, foundedUser.ID, map[string]string{
+		"email_change_id": emailChange.ID.String(),
+	})
+
+	// Return status 201 created.
+	return c.SendStatus(fiber.StatusCreated)
+}
+
+// ConfirmEmailChange method to replace user email by the given confirm code.
+func (h *Handler) ConfirmEmailChange(c *fiber.Ctx) error {
+	// Create a new apply code struct.
+	applyCode := &models.ApplyEmailChangeCode{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(applyCode); err != nil {
+		return utilities.CheckForError(c, err, 400, "email change", err.Error())
+	}
+
+	// Get email change by given code.
+	foundedEmailChange, status, err := h.EmailChanges.GetEmailChangeByConfirmCode(c.Context(), applyCode.Code)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "email change", err.Error())
+	}
+
+	// Checking, if confirm code was expired.
+	if time.Now().After(foundedEmailChange.ConfirmExpireAt) {
+		// Record audit event.
+		audit.Failure(c, audit.EventEmailChangeConfirm, uuid.Nil, foundedEmailChange.UserID, "confirm code was expired")
+
+		return utilities.ThrowJSONError(c, 403, "email change", "confirm code was expired")
+	}
+
+	// Replace user email in one transaction (uniqueness of the new email is re-checked).
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Get storage of the email changes in transaction.
+		emailChanges, err := emailChangesOf(r)
+		if err != nil {
+			return err
+		}
+
+		// Lock email change by given code (it can be applied only once).
+		emailChange, status, err := emailChanges.GetEmailChangeByConfirmCode(c.Context(), applyCode.Code)
+		if err != nil {
+			return helpers.NewTxError(err, status, "email change")
+		}
+		if emailChange.ConfirmedAt != nil || emailChange.RevertedAt != nil {
+			return helpers.NewTxError(fmt.Errorf("email change was already applied"), 404, "email change")
+		}
+
+		// Replace the old user email by the new one.
+		if err := replaceUserEmail(c.Context(), r, emailChanges, emailChange.UserID, emailChange.OldEmail, emailChange.NewEmail); err != nil {
+			return err
+		}
+
+		// Mark email change as confirmed.
+		if err := emailChanges.MarkEmailChangeConfirmed(c.Context(), emailChange.ID); err != nil {
+			return helpers.NewTxError(err, 400, "email change")
+		}
+
+		return nil
+	}); err != nil {
+		// Record audit event.
+		audit.Failure(c, audit.EventEmailChangeConfirm, uuid.Nil, foundedEmailChange.UserID, err.Error())
+
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Remove changed user from cache (email is updated without storage of the users).
+	h.invalidateUser(foundedEmailChange.UserID)
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventEmailChangeConfirm, uuid.Nil, foundedEmailChange.UserID, map[string]string{
+		"email_change_id": foundedEmailChange.ID.String(),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// RevertEmailChange method to cancel email change (or return the old email) by the given revert code.
+// All sessions of the user are revoked, because account may be compromised.
+func (h *Handler) RevertEmailChange(c *fiber.Ctx) error {
+	// Create a new apply code struct.
+	applyCode := &models.ApplyEmailChangeCode{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(applyCode); err != nil {
+		return utilities.CheckForError(c, err, 400, "email change", err.Error())
+	}
+
+	// Get email change by given code.
+	foundedEmailChange, status, err := h.EmailChanges.GetEmailChangeByRevertCode(c.Context(), applyCode.Code)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "email change", err.Error())
+	}
+
+	// Checking, if revert code was expired.
+	if time.Now().After(foundedEmailChange.RevertExpireAt) {
+		// Record audit event.
+		audit.Failure(c, audit.EventEmailChangeRevert, uuid.Nil, foundedEmailChange.UserID, "revert code was expired")
+
+		return utilities.ThrowJSONError(c, 403, "email change", "revert code was expired")
+	}
+
+	// Return the old user email (if change was confirmed) and revoke all sessions of the user
+	// in one transaction (uniqueness of the old email is re-checked).
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Get storage of the email changes in transaction.
+		emailChanges, err := emailChangesOf(r)
+		if err != nil {
+			return err
+		}
+
+		// Lock email change by given code (it can be reverted only once).
+		emailChange, status, err := emailChanges.GetEmailChangeByRevertCode(c.Context(), applyCode.Code)
+		if err != nil {
+			return helpers.NewTxError(err, status, "email change")
+		}
+		if emailChange.RevertedAt != nil {
+			return helpers.NewTxError(fmt.Errorf("email change was already reverted"), 404, "email change")
+		}
+
+		// Replace the new user email by the old one (not confirmed change is only cancelled).
+		if emailChange.ConfirmedAt != nil {
+			if err := replaceUserEmail(c.Context(), r, emailChanges, emailChange.UserID, emailChange.NewEmail, emailChange.OldEmail); err != nil {
+				return err
+			}
+		}
+
+		// Mark email change as reverted.
+		if err := emailChanges.MarkEmailChangeReverted(c.Context(), emailChange.ID); err != nil {
+			return helpers.NewTxError(err, 400, "email change")
+		}
+
+		// Revoke all sessions of the user, because account may be compromised.
+		if err := r.DeleteSessionsByUserID(c.Context(), emailChange.UserID); err != nil {
+			return helpers.NewTxError(err, 400, "sessions")
+		}
+
+		return nil
+	}); err != nil {
+		// Record audit event.
+		audit.Failure(c, audit.EventEmailChangeRevert, uuid.Nil, foundedEmailChange.UserID, err.Error())
+
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Remove changed user from cache (email is updated without storage of the users).
+	h.invalidateUser(foundedEmailChange.UserID)
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventEmailChangeRevert, uuid.Nil, foundedEmailChange.UserID, map[string]string{
+		"email_change_id": foundedEmailChange.ID.String(),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// emailChangesOf func for getting storage of the email changes in the given transaction.
+func emailChangesOf(r database.Repository) (database.EmailChangeRepository, error) {
+	emailChanges, ok := database.Unwrap(r).(database.EmailChangeRepository)
+	if !ok {
+		return nil, helpers.NewTxError(fmt.Errorf("email changes are not supported by the storage"), 500, "email change")
+	}
+
+	return emailChanges, nil
+}
+
+// replaceUserEmail func for replacing email of the user in transaction (only, if it's not changed again).
+// The new email is locked for other transactions and its uniqueness is re-checked,
+// reset codes of the previous email are deleted.
+func replaceUserEmail(ctx context.Context, r database.Repository, emailChanges database.EmailChangeRepository, userID uuid.UUID, from, to string) error {
+	// Lock the email for other transactions (until the end of this one).
+	if err := emailChanges.LockEmail(ctx, to); err != nil {
+		return helpers.NewTxError(err, 400, "email change")
+	}
+
+	// Checking, if the email is already taken by another user.
+	foundedUser, status, err := r.GetUserByEmail(ctx, to)
+	if err == nil && foundedUser.ID != userID {
+		return helpers.NewTxError(fmt.Errorf("email is already taken"), 400, "email change")
+	}
+	if err != nil && status != 404 {
+		return helpers.NewTxError(err, status, "user")
+	}
+
+	// Replace user email.
+	isReplaced, err := emailChanges.UpdateUserEmail(ctx, userID, from, to)
+	if err != nil {
+		return helpers.NewTxError(err, 400, "email change")
+	}
+	if !isReplaced {
+		return helpers.NewTxError(fmt.Errorf("email was changed again"), 400, "email change")
+	}
+
+	// Delete reset codes of the previous email.
+	if err := r.DeleteResetCodesByEmail(ctx, from); err != nil {
+		return helpers.NewTxError(err, 400, "reset code")
+	}
+
+	return nil
+}
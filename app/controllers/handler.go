@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+	"context"
+	"log"
+
+	"github.com/google/uuid"
+)
+
+// Handler struct to describe dependencies of all controllers.
+type Handler struct {
+	DB              *database.Queries                 // shared connection pool (for queries without repository)
+	Users           database.UserRepository           // storage of the users
+	Usernames       database.UsernameRepository       // storage of the usernames and redirects from the old ones
+	EmailChanges    database.EmailChangeRepository    // storage of the email changes
+	ActivationCodes database.ActivationCodeRepository // storage of the activation codes
+	ResetCodes      database.ResetCodeRepository      // storage of the reset codes
+	Sessions        database.SessionRepository        // storage of the sessions
+	Tx              database.UnitOfWork               // runner of the multi-step operations in one transaction
+	UserCache       database.UserCache                // cache of the users (invalidated after changes by DB queries)
+}
+
+// NewHandler func for create a new handler with the given dependencies.
+func NewHandler(db *database.Queries) *Handler {
+	return &Handler{
+		DB:              db,
+		Users:           db,
+		Usernames:       db,
+		EmailChanges:    db,
+		ActivationCodes: db,
+		ResetCodes:      db,
+		Sessions:        db,
+		Tx:              db,
+		UserCache:       database.NopUserCache{},
+	}
+}
+
+// NewHandlerWithRepository func for create a new handler with the given storage
+// for users, codes and sessions (for example, in-memory for tests).
+func NewHandlerWithRepository(db *database.Queries, repository database.Repository) *Handler {
+	// Invalidate cached users, if storage has cache.
+	userCache, ok := repository.(database.UserCache)
+	if !ok {
+		userCache = database.NopUserCache{}
+	}
+
+	// Use storage of the usernames, if it's supported (database queries otherwise).
+	var usernames database.UsernameRepository = db
+	if repository, ok := repository.(database.UsernameRepository); ok {
+		usernames = repository
+	}
+
+	// Use storage of the email changes, if it's supported (database queries otherwise).
+	var emailChanges database.EmailChangeRepository = db
+	if repository, ok := repository.(database.EmailChangeRepository); ok {
+		emailChanges = repository
+	}
+
+	return &Handler{
+		DB:              db,
+		Users:           repository,
+		Usernames:       usernames,
+		EmailChanges:    emailChanges,
+		ActivationCodes: repository,
+		ResetCodes:      repository,
+		Sessions:        repository,
+		Tx:              repository,
+		UserCache:       userCache,
+	}
+}
+
+// invalidateUser method to remove user from cache after changes without repository
+// (errors are only logged, cached user is expired after TTL).
+func (h *Handler) invalidateUser(id uuid.UUID) {
+	if err := h.UserCache.InvalidateUser(context.Background(), id); err != nil {
+		log.Printf("Oops... User is not removed from cache! Reason: %v", err)
+	}
+}
+
+// comparePassword method to compare the given password with hash of the user by given ID.
+// Hash is got from storage by its own query (cached users have no hash). Returns true as the second value,
+// if hash must be replaced by the current hasher, and status with error, if hash is not got.
+func (h *Handler) comparePassword(ctx context.Context, id uuid.UUID, password string) (bool, bool, int, error) {
+	// Get password hash of the user.
+	passwordHash, status, err := h.Users.GetUserPasswordHash(ctx, id)
+	if err != nil {
+		return false, false, status, err
+	}
+
+	// Compare hash with the given password.
+	match, needsRehash := helpers.ComparePasswordHash(passwordHash, password)
+
+	return match, needsRehash, status, nil
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ImpersonateUser method to issue a short-lived access token for the user by given ID
+// (admin sees exactly what the user sees, each impersonation is stored in audit trail).
+func (h *Handler) ImpersonateUser(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "impersonate", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "impersonation", err.Error())
+	}
+
+	// Checking, if current token is already an impersonation token.
+	if _, err := helpers.ExtractImpersonationID(c); err == nil {
+		return utilities.ThrowJSONError(c, 403, "impersonation", "nested impersonation is not allowed")
+	}
+
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Checking, if admin is trying to impersonate own user.
+	if userID == claims.UserID {
+		return utilities.ThrowJSONError(c, 403, "impersonation", "can't impersonate own user")
+	}
+
+	// Set expires minutes count for impersonation key from .env file.
+	minutesCount, err := strconv.Atoi(os.Getenv("JWT_IMPERSONATION_KEY_EXPIRE_MINUTES_COUNT"))
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "minutes count", err.Error())
+	}
+
+	// Get user by given ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Checking, if user was blocked.
+	if foundedUser.UserStatus == 2 {
+		return utilities.ThrowJSONError(c, 403, "user", "was blocked")
+	}
+
+	// Get credentials of the user role.
+	userCredentials, err := helpers.GetCredentialsByRole(foundedUser.UserRole)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "credentials", err.Error())
+	}
+
+	// Checking, if user is an admin (impersonation can't be used to get admin credentials).
+	if helpers.HasAdminCredentials(userCredentials) {
+		// Record audit event.
+		audit.Failure(c, audit.EventImpersonationStart, claims.UserID, foundedUser.ID, "user has admin credentials")
+
+		return utilities.ThrowJSONError(c, 403, "impersonation", "can't impersonate user with admin credentials")
+	}
+
+	// Set expiration time.
+	expireAt := time.Now().Add(time.Minute * time.Duration(minutesCount))
+
+	// Create a new impersonation object for audit trail.
+	impersonation := helpers.NewImpersonation(c, claims.UserID, foundedUser.ID, expireAt)
+
+	// Create a new impersonation (start of the impersonation).
+	if err := h.DB.CreateNewImpersonation(c.Context(), impersonation); err != nil {
+		return utilities.CheckForError(c, err, 400, "impersonation", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventImpersonationStart, claims.UserID, foundedUser.ID, models.AuditEventDetails{
+		"impersonation_id": impersonation.ID.String(),
+	})
+
+	// Generate a new access token for the user (without refresh token).
+	token, err := helpers.GenerateNewImpersonationAccessToken(
+		foundedUser.ID.String(), claims.UserID.String(), impersonation.ID.String(), userCredentials,
+	)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "jwt", err.Error())
+	}
+
+	// Return status 201 created and new access token with expiration time.
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":        fiber.StatusCreated,
+		"impersonation": impersonation,
+		"jwt": fiber.Map{
+			"expire": expireAt.Unix(),
+			"token":  token,
+		},
+	})
+}
+
+// EndImpersonation method to end the current impersonation (token stops working).
+func (h *Handler) EndImpersonation(c *fiber.Ctx) error {
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "impersonation", err.Error())
+	}
+
+	// Get impersonation ID from the current token.
+	impersonationID, err := helpers.ExtractImpersonationID(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "impersonation", err.Error())
+	}
+
+	// Get impersonation by ID.
+	foundedImpersonation, status, err := h.DB.GetImpersonationByID(c.Context(), impersonationID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "impersonation", err.Error())
+	}
+
+	// End impersonation (end of the impersonation).
+	if err := h.DB.EndImpersonation(c.Context(), foundedImpersonation.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "impersonation", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventImpersonationEnd, foundedImpersonation.AdminID, foundedImpersonation.UserID, models.AuditEventDetails{
+		"impersonation_id": foundedImpersonation.ID.String(),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -0,0 +1,28 @@
+package controllers
+
+import (
+	"Komentory/auth/pkg/jobs"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+)
+
+// GetJobsMetrics method to get metrics of the background jobs of this app instance.
+func (h *Handler) GetJobsMetrics(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("users", "manage", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "jobs", err.Error())
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"jobs":   jobs.Metrics(),
+	})
+}
@@ -0,0 +1,166 @@
+package controllers
+
+import (
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreateNewPersonalAccessToken method to create a new personal access token for the user.
+func (h *Handler) CreateNewPersonalAccessToken(c *fiber.Ctx) error {
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "personal access token", err.Error())
+	}
+
+	// Create a new personal access token creation struct.
+	newToken := &models.CreateNewPersonalAccessToken{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(newToken); err != nil {
+		return utilities.CheckForError(c, err, 400, "personal access token", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(newToken); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "personal access token")
+	}
+
+	// Create a new variable for timestamp, because time fields in model are pointers.
+	now := time.Now()
+
+	// Checking, if expiration time is in the past.
+	if newToken.ExpireAt != nil && newToken.ExpireAt.Before(now) {
+		return utilities.ThrowJSONError(c, 400, "personal access token", "expiration time is in the past")
+	}
+
+	// Get user by ID from JWT.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Get credentials of the user role.
+	userCredentials, err := helpers.GetCredentialsByRole(foundedUser.UserRole)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "credentials", err.Error())
+	}
+
+	// Checking, if all of the given credentials are allowed for the user.
+	for _, credential := range newToken.Credentials {
+		if !utilities.SearchStringInArray(credential, userCredentials) {
+			return utilities.ThrowJSONError(c, 403, "credentials", credential)
+		}
+	}
+
+	// Generate a new personal access token and its hash.
+	token, tokenHash, err := helpers.GenerateNewPersonalAccessToken()
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+	}
+
+	// Create a new PersonalAccessToken struct.
+	personalAccessToken := &models.PersonalAccessToken{}
+
+	// Set data for personal access token:
+	personalAccessToken.ID = uuid.New()
+	personalAccessToken.CreatedAt = &now
+	personalAccessToken.UserID = foundedUser.ID
+	personalAccessToken.Name = newToken.Name
+	personalAccessToken.TokenHash = tokenHash
+	personalAccessToken.Credentials = newToken.Credentials
+	personalAccessToken.ExpireAt = newToken.ExpireAt
+
+	// Validate personal access token fields.
+	if err := validate.Struct(personalAccessToken); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "personal access token")
+	}
+
+	// Create a new personal access token with validated data.
+	if err := h.DB.CreateNewPersonalAccessToken(c.Context(), personalAccessToken); err != nil {
+		return utilities.CheckForError(c, err, 400, "personal access token", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventPersonalTokenCreate, claims.UserID, claims.UserID, models.AuditEventDetails{
+		"token_id": personalAccessToken.ID.String(),
+	})
+
+	// Return status 201 created.
+	// Token is shown only once, database stores only its hash.
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":                fiber.StatusCreated,
+		"token":                 token,
+		"personal_access_token": personalAccessToken,
+	})
+}
+
+// GetPersonalAccessTokens method to get all personal access tokens of the user.
+func (h *Handler) GetPersonalAccessTokens(c *fiber.Ctx) error {
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "personal access tokens", err.Error())
+	}
+
+	// Get all personal access tokens by user ID from JWT.
+	foundedTokens, status, err := h.DB.GetPersonalAccessTokensByUserID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "personal access tokens", err.Error())
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status":                 fiber.StatusOK,
+		"personal_access_tokens": foundedTokens,
+	})
+}
+
+// DeletePersonalAccessToken method to revoke personal access token of the user by given ID.
+func (h *Handler) DeletePersonalAccessToken(c *fiber.Ctx) error {
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "personal access token", err.Error())
+	}
+
+	// Parse personal access token ID from URL.
+	tokenID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "personal access token", err.Error())
+	}
+
+	// Get personal access token by given ID.
+	foundedToken, status, err := h.DB.GetPersonalAccessTokenByID(c.Context(), tokenID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "personal access token", err.Error())
+	}
+
+	// Checking, if personal access token belongs to the user from JWT.
+	if foundedToken.UserID != claims.UserID {
+		return utilities.ThrowJSONError(c, 404, "personal access token", "not owned by user")
+	}
+
+	// Delete personal access token.
+	if err := h.DB.DeletePersonalAccessToken(c.Context(), foundedToken.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "personal access token", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventPersonalTokenRevoke, claims.UserID, claims.UserID, models.AuditEventDetails{
+		"token_id": foundedToken.ID.String(),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
@@ -6,15 +6,17 @@ import (
 	"time"
 
 	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
 	"Komentory/auth/pkg/helpers"
 	"Komentory/auth/platform/database"
 
 	"github.com/Komentory/utilities"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // CreateNewResetCode method to create a new request to reset user password by given email.
-func CreateNewResetCode(c *fiber.Ctx) error {
+func (h *Handler) CreateNewResetCode(c *fiber.Ctx) error {
 	// Create a new reset code struct.
 	newResetCode := &models.NewResetCode{}
 
@@ -31,24 +33,12 @@ func CreateNewResetCode(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "reset code")
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
-	}
-
 	// Get user by email.
-	foundedUser, status, err := db.GetUserByEmail(newResetCode.Email)
+	foundedUser, status, err := h.Users.GetUserByEmail(c.Context(), newResetCode.Email)
 	if err != nil {
 		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
 
-	// Deleting all previously created reset codes for the given email.
-	err = db.DeleteResetCodesByEmail(foundedUser.Email)
-	if err != nil {
-		return utilities.CheckForError(c, err, 400, "reset code", err.Error())
-	}
-
 	// Generate a new reset code with nanoID.
 	randomResetCode, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 14)
 	if err != nil {
@@ -68,17 +58,32 @@ func CreateNewResetCode(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "reset code")
 	}
 
-	// Create a new reset code with validated data.
-	if err := db.CreateNewResetCode(resetCode); err != nil {
-		return utilities.CheckForError(c, err, 400, "reset code", err.Error())
+	// Replace all previously created reset codes for the given email in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Deleting all previously created reset codes for the given email.
+		if err := r.DeleteResetCodesByEmail(c.Context(), foundedUser.Email); err != nil {
+			return helpers.NewTxError(err, 400, "reset code")
+		}
+
+		// Create a new reset code with validated data.
+		if err := r.CreateNewResetCode(c.Context(), resetCode); err != nil {
+			return helpers.NewTxError(err, 400, "reset code")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
 	}
 
+	// Record audit event.
+	audit.Success(c, audit.EventResetRequest, uuid.Nil, foundedUser.ID)
+
 	// Return status 201 created.
 	return c.SendStatus(fiber.StatusCreated)
 }
 
 // ApplyResetCode method for reset password by given code.
-func ApplyResetCode(c *fiber.Ctx) error {
+func (h *Handler) ApplyResetCode(c *fiber.Ctx) error {
 	// Get now time.
 	now := time.Now().Unix()
 
@@ -90,14 +95,8 @@ func ApplyResetCode(c *fiber.Ctx) error {
 		return utilities.CheckForError(c, err, 400, "reset code", err.Error())
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
-	}
-
 	// Get code by given string.
-	foundedCode, status, err := db.GetResetCode(applyResetCode.Code)
+	foundedCode, status, err := h.ResetCodes.GetResetCode(c.Context(), applyResetCode.Code)
 	if err != nil {
 		return utilities.CheckForError(c, err, status, "reset code", err.Error())
 	}
@@ -105,28 +104,45 @@ func ApplyResetCode(c *fiber.Ctx) error {
 	// Checking, if now time greather than activation code expiration time.
 	if now < foundedCode.ExpireAt.Unix() {
 		// Get user by email.
-		foundedUser, status, err := db.GetUserByEmail(foundedCode.Email)
+		foundedUser, status, err := h.Users.GetUserByEmail(c.Context(), foundedCode.Email)
 		if err != nil {
 			return utilities.CheckForError(c, err, status, "user", err.Error())
 		}
 
-		// Generate a new random string for the password with nanoID.
-		randomString, err := utilities.GenerateNewNanoID("", 14)
-		if err != nil {
-			return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
-		}
+		// Checking, if user was blocked (reset code can't be used to login).
+		if foundedUser.UserStatus == 2 {
+			// Record audit event.
+			audit.Failure(c, audit.EventResetApply, uuid.Nil, foundedUser.ID, "user was blocked")
 
-		// Create a new hash for the given password.
-		newPassword := utilities.GeneratePassword(randomString)
+			return utilities.ThrowJSONError(c, 403, "user", "was blocked")
+		}
 
-		// Update user password to random generated by nanoID.
-		if err := db.UpdateUserPassword(foundedUser.ID, newPassword); err != nil {
-			return utilities.CheckForError(c, err, 400, "user", err.Error())
+		// Set the given password (random string is returned to the user only, if password wasn't given).
+		password, randomString := applyResetCode.NewPassword, ""
+		if password != "" {
+			// Checking new password by the password policy.
+			violations, err := helpers.CheckPassword(
+				applyResetCode.NewPassword, foundedUser.Email, foundedUser.UserAttrs.FirstName, foundedUser.UserAttrs.LastName,
+			)
+			if err != nil {
+				return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+			}
+			if len(violations) > 0 {
+				return helpers.ThrowPasswordPolicyError(c, "reset code", "new_password", violations)
+			}
+		} else {
+			// Generate a new random string for the password with nanoID.
+			randomString, err = utilities.GenerateNewNanoID("", 14)
+			if err != nil {
+				return utilities.CheckForErrorWithStatusCode(c, err, 500, "nanoid", err.Error())
+			}
+			password = randomString
 		}
 
-		// Delete activation code.
-		if err := db.DeleteResetCode(applyResetCode.Code); err != nil {
-			return utilities.CheckForError(c, err, 400, "reset code", err.Error())
+		// Create a new hash for the given (or random) password.
+		newPassword, err := helpers.GeneratePasswordHash(password)
+		if err != nil {
+			return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
 		}
 
 		// Generate a new pair of access and refresh tokens.
@@ -135,28 +151,59 @@ func ApplyResetCode(c *fiber.Ctx) error {
 			return utilities.CheckForError(c, err, 400, "tokens", err.Error())
 		}
 
-		// Define user ID.
-		// userID := foundedUser.ID.String()
-
-		// Create a new Redis connection.
-		// connRedis, errRedisConnection := cache.RedisConnection()
-		// if errRedisConnection != nil {
-		// 	// Return status 500 and Redis connection error.
-		// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 		"error": true,
-		// 		"msg":   errRedisConnection.Error(),
-		// 	})
-		// }
-
-		// Set refresh token to Redis.
-		// errSetToRedis := connRedis.Set(context.Background(), userID, tokens.Refresh, 0).Err()
-		// if errSetToRedis != nil {
-		// 	// Return status 500 and Redis connection error.
-		// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 		"error": true,
-		// 		"msg":   errSetToRedis.Error(),
-		// 	})
-		// }
+		// Create a new session for the refresh token.
+		session, err := helpers.NewSession(c, foundedUser.ID, tokens.Refresh)
+		if err != nil {
+			return utilities.CheckForError(c, err, 400, "session", err.Error())
+		}
+
+		// Update password, delete reset code and replace sessions of the user in one transaction.
+		if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+			// Get code again (it's locked until the end of transaction, so it's applied only once).
+			if _, status, err := r.GetResetCode(c.Context(), applyResetCode.Code); err != nil {
+				return helpers.NewTxError(err, status, "reset code")
+			}
+
+			// Update user password to the new one.
+			if err := r.UpdateUserPassword(c.Context(), foundedUser.ID, newPassword); err != nil {
+				return helpers.NewTxError(err, 400, "user")
+			}
+
+			// Delete reset code.
+			if err := r.DeleteResetCode(c.Context(), applyResetCode.Code); err != nil {
+				return helpers.NewTxError(err, 400, "reset code")
+			}
+
+			// Revoke all previous sessions of the user.
+			if err := r.DeleteSessionsByUserID(c.Context(), foundedUser.ID); err != nil {
+				return helpers.NewTxError(err, 400, "sessions")
+			}
+
+			// Save session with refresh token hash.
+			if err := r.CreateNewSession(c.Context(), session); err != nil {
+				return helpers.NewTxError(err, 400, "session")
+			}
+
+			// Cancel deletion of the user account, if it was requested.
+			if foundedUser.DeletionRequestedAt != nil {
+				if err := r.CancelUserDeletion(c.Context(), foundedUser.ID); err != nil {
+					return helpers.NewTxError(err, 400, "user")
+				}
+			}
+
+			return nil
+		}); err != nil {
+			return helpers.CheckForTxError(c, err)
+		}
+
+		// Record audit event.
+		audit.Success(c, audit.EventResetApply, foundedUser.ID, foundedUser.ID)
+
+		// Record audit event of the cancelled deletion.
+		if foundedUser.DeletionRequestedAt != nil {
+			foundedUser.DeletionRequestedAt = nil
+			audit.Success(c, audit.EventAccountDeletionCancel, foundedUser.ID, foundedUser.ID)
+		}
 
 		// Set expires minutes count for secret key from .env file.
 		minutesCount, err := strconv.Atoi(os.Getenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT"))
@@ -191,13 +238,16 @@ func ApplyResetCode(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
 			"status":       fiber.StatusOK,
 			"user":         foundedUser,
-			"new_password": randomString, // for "old_password" field on frontend (manual re-creation)
+			"new_password": randomString, // for "old_password" field on frontend (empty, if password was given)
 			"jwt": fiber.Map{
 				"expire": time.Now().Add(time.Minute * time.Duration(minutesCount)).Unix(),
 				"token":  tokens.Access,
 			},
 		})
 	} else {
+		// Record audit event (user is unknown).
+		audit.Failure(c, audit.EventResetApply, uuid.Nil, uuid.Nil, "reset code was expired")
+
 		// Return status 403 and forbidden error message.
 		return utilities.ThrowJSONError(c, 403, "reset code", "was expired")
 	}
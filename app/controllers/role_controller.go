@@ -0,0 +1,368 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// GetRoles method to get all roles with their credentials.
+func (h *Handler) GetRoles(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "roles", err.Error())
+	}
+
+	// Get all roles.
+	foundedRoles, status, err := h.DB.GetRoles(c.Context())
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "roles", err.Error())
+	}
+
+	// Get credentials of all roles.
+	foundedCredentials, status, err := h.DB.GetRoleCredentials(c.Context())
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "credentials", err.Error())
+	}
+
+	// Set credentials for each role.
+	for index := range foundedRoles {
+		foundedRoles[index].Credentials = []string{}
+		for _, credential := range foundedCredentials {
+			if credential.RoleID == foundedRoles[index].ID {
+				foundedRoles[index].Credentials = append(foundedRoles[index].Credentials, credential.Credential)
+			}
+		}
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"roles":  foundedRoles,
+	})
+}
+
+// CreateNewRole method to create a new role (without any credentials).
+func (h *Handler) CreateNewRole(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "role", err.Error())
+	}
+
+	// Create a new role creation struct.
+	newRole := &models.CreateNewRole{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(newRole); err != nil {
+		return utilities.CheckForError(c, err, 400, "role", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(newRole); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "role")
+	}
+
+	// Create a new variable for timestamp, because time fields in Role model are pointers.
+	now := time.Now()
+
+	// Create a new Role struct.
+	role := &models.Role{
+		ID:          newRole.ID,
+		CreatedAt:   &now,
+		Name:        newRole.Name,
+		Credentials: []string{},
+	}
+
+	// Create a new role with validated data.
+	if err := h.DB.CreateNewRole(c.Context(), role); err != nil {
+		return utilities.CheckForError(c, err, 400, "role", err.Error())
+	}
+
+	// Reset credentials cache.
+	helpers.ResetCredentialsCache()
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventRoleCreate, claims.UserID, uuid.Nil, models.AuditEventDetails{
+		"role_id": strconv.Itoa(role.ID),
+	})
+
+	// Return status 201 created.
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status": fiber.StatusCreated,
+		"role":   role,
+	})
+}
+
+// UpdateRolePermissions method to replace all credentials of the role by given ID.
+func (h *Handler) UpdateRolePermissions(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "role permissions", err.Error())
+	}
+
+	// Parse role ID from URL.
+	roleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "role", err.Error())
+	}
+
+	// Create a new UpdateRolePermissions struct.
+	updatePermissions := &models.UpdateRolePermissions{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(updatePermissions); err != nil {
+		return utilities.CheckForError(c, err, 400, "role permissions", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(updatePermissions); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "role permissions")
+	}
+
+	// Get role by given ID.
+	foundedRole, status, err := h.DB.GetRoleByID(c.Context(), roleID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "role", err.Error())
+	}
+
+	// Replace role permissions in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Get database queries in transaction.
+		db, ok := database.Unwrap(r).(*database.Queries)
+		if !ok {
+			return helpers.NewTxError(fmt.Errorf("roles are not supported by the storage"), 500, "role permissions")
+		}
+
+		// Delete old permissions of the role.
+		if err := db.DeleteRolePermissions(c.Context(), foundedRole.ID); err != nil {
+			return helpers.NewTxError(err, 400, "role permissions")
+		}
+
+		// Add new permissions (each credential must be exists).
+		for _, credential := range updatePermissions.Credentials {
+			if err := db.AddRolePermission(c.Context(), foundedRole.ID, credential); err != nil {
+				return helpers.NewTxError(err, 400, "role permissions")
+			}
+		}
+
+		// Update time of the last change of the role.
+		if err := db.TouchRole(c.Context(), foundedRole.ID); err != nil {
+			return helpers.NewTxError(err, 400, "role")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Reset credentials cache.
+	helpers.ResetCredentialsCache()
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventRolePermissionsChange, claims.UserID, uuid.Nil, models.AuditEventDetails{
+		"role_id":     strconv.Itoa(foundedRole.ID),
+		"credentials": strings.Join(updatePermissions.Credentials, ","),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// DeleteRole method to delete role by given ID.
+func (h *Handler) DeleteRole(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "role", err.Error())
+	}
+
+	// Parse role ID from URL.
+	roleID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "role", err.Error())
+	}
+
+	// Get role by given ID.
+	foundedRole, status, err := h.DB.GetRoleByID(c.Context(), roleID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "role", err.Error())
+	}
+
+	// Delete role (fails, if role is still used by users).
+	if err := h.DB.DeleteRole(c.Context(), foundedRole.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "role", err.Error())
+	}
+
+	// Reset credentials cache.
+	helpers.ResetCredentialsCache()
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventRoleDelete, claims.UserID, uuid.Nil, models.AuditEventDetails{
+		"role_id": strconv.Itoa(foundedRole.ID),
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// GetPermissions method to get all permissions.
+func (h *Handler) GetPermissions(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	_, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "permissions", err.Error())
+	}
+
+	// Get all permissions.
+	foundedPermissions, status, err := h.DB.GetPermissions(c.Context())
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "permissions", err.Error())
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status":      fiber.StatusOK,
+		"permissions": foundedPermissions,
+	})
+}
+
+// CreateNewPermission method to create a new permission.
+func (h *Handler) CreateNewPermission(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "permission", err.Error())
+	}
+
+	// Create a new permission creation struct.
+	newPermission := &models.CreateNewPermission{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(newPermission); err != nil {
+		return utilities.CheckForError(c, err, 400, "permission", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(newPermission); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "permission")
+	}
+
+	// Create a new variable for timestamp, because time fields in Permission model are pointers.
+	now := time.Now()
+
+	// Create a new Permission struct.
+	permission := &models.Permission{
+		CreatedAt:  &now,
+		Credential: newPermission.Credential,
+	}
+
+	// Create a new permission with validated data.
+	if err := h.DB.CreateNewPermission(c.Context(), permission); err != nil {
+		return utilities.CheckForError(c, err, 400, "permission", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventPermissionCreate, claims.UserID, uuid.Nil, models.AuditEventDetails{
+		"credential": permission.Credential,
+	})
+
+	// Return status 201 created.
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":     fiber.StatusCreated,
+		"permission": permission,
+	})
+}
+
+// DeletePermission method to delete permission by given ID (from all roles too).
+func (h *Handler) DeletePermission(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("roles", "manage", false),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "permission", err.Error())
+	}
+
+	// Parse permission ID from URL.
+	permissionID, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "permission", err.Error())
+	}
+
+	// Get permission by given ID.
+	foundedPermission, status, err := h.DB.GetPermissionByID(c.Context(), permissionID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "permission", err.Error())
+	}
+
+	// Delete permission.
+	if err := h.DB.DeletePermission(c.Context(), foundedPermission.ID); err != nil {
+		return utilities.CheckForError(c, err, 400, "permission", err.Error())
+	}
+
+	// Reset credentials cache.
+	helpers.ResetCredentialsCache()
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventPermissionDelete, claims.UserID, uuid.Nil, models.AuditEventDetails{
+		"credential": foundedPermission.Credential,
+	})
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
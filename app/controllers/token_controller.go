@@ -1,20 +1,24 @@
 package controllers
 
 import (
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
 	"Komentory/auth/pkg/helpers"
 	"Komentory/auth/platform/database"
 
 	"github.com/Komentory/utilities"
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 // RenewTokens method for renew access and refresh tokens.
-func RenewTokens(c *fiber.Ctx) error {
+func (h *Handler) RenewTokens(c *fiber.Ctx) error {
 	// Get old refresh token from client.
 	oldRefreshToken := c.Cookies("refresh_token", "")
 
@@ -35,53 +39,80 @@ func RenewTokens(c *fiber.Ctx) error {
 
 	// Checking, if now time greather than Refresh token expiration time.
 	if now < expires {
-		// Create database connection.
-		db, err := database.OpenDBConnection()
+		// Get session by refresh token hash.
+		foundedSession, status, err := h.Sessions.GetSessionByRefreshTokenHash(c.Context(), helpers.HashToken(oldRefreshToken))
 		if err != nil {
-			return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
+			if status == 404 {
+				// Record audit event.
+				audit.Failure(c, audit.EventTokenRenewal, uuid.Nil, userID, "session was ended")
+
+				// Return status 401 and unauthorized error message.
+				return utilities.ThrowJSONError(c, 401, "refresh token", "session was ended")
+			}
+			return utilities.CheckForError(c, err, status, "session", err.Error())
+		}
+
+		// Checking, if session belongs to the user from refresh token.
+		if foundedSession.UserID != userID {
+			// Record audit event.
+			audit.Failure(c, audit.EventTokenRenewal, uuid.Nil, userID, "session belongs to another user")
+
+			return utilities.ThrowJSONError(c, 401, "refresh token", "session was ended")
 		}
 
 		// Get user by ID.
-		foundedUser, status, err := db.GetUserByID(userID)
+		foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
 		if err != nil {
 			return utilities.CheckForError(c, err, status, "user", err.Error())
 		}
 
+		// Checking, if user was blocked.
+		if foundedUser.UserStatus == 2 {
+			// Record audit event.
+			audit.Failure(c, audit.EventTokenRenewal, uuid.Nil, foundedUser.ID, "user was blocked")
+
+			return utilities.ThrowJSONError(c, 403, "user", "was blocked")
+		}
+
 		// Generate JWT Access & Refresh tokens.
-		tokens, err := helpers.GenerateNewTokens(userID.String(), foundedUser.UserRole)
+		tokens, err := helpers.GenerateNewUserTokens(&foundedUser)
 		if err != nil {
 			return utilities.CheckForError(c, err, 400, "jwt", err.Error())
 		}
 
-		// Create a new Redis connection.
-		// connRedis, err := cache.RedisConnection()
-		// if err != nil {
-		// 	// Return status 500 and Redis connection error.
-		// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 		"error": true,
-		// 		"msg":   err.Error(),
-		// 	})
-		// }
-
-		// Checking refresh token.
-		// _, err = connRedis.Get(context.Background(), userID.String()).Result()
-		// if err == redis.Nil {
-		// 	// Return status 401 and unauthorized error message.
-		// 	return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-		// 		"error": true,
-		// 		"msg":   repository.UnauthorizedSessionEnded,
-		// 	})
-		// }
-
-		// Save refresh token to Redis.
-		// errRedis := connRedis.Set(context.Background(), userID.String(), tokens.Refresh, 0).Err()
-		// if errRedis != nil {
-		// 	// Return status 500 and Redis connection error.
-		// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-		// 		"error": true,
-		// 		"msg":   errRedis.Error(),
-		// 	})
-		// }
+		// Create a new session for the new refresh token.
+		session, err := helpers.NewSession(c, foundedUser.ID, tokens.Refresh)
+		if err != nil {
+			return utilities.CheckForError(c, err, 400, "session", err.Error())
+		}
+
+		// Replace old session with the new one in one transaction.
+		if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+			// Get old session again (it's locked until the end of transaction, so refresh token is used only once).
+			if _, status, err := r.GetSessionByRefreshTokenHash(c.Context(), foundedSession.RefreshTokenHash); err != nil {
+				if status == 404 {
+					return helpers.NewTxError(fmt.Errorf("session was ended"), 401, "refresh token")
+				}
+				return helpers.NewTxError(err, status, "session")
+			}
+
+			// Delete old session (refresh token can be used only once).
+			if err := r.DeleteSession(c.Context(), foundedSession.ID); err != nil {
+				return helpers.NewTxError(err, 400, "session")
+			}
+
+			// Save session with refresh token hash.
+			if err := r.CreateNewSession(c.Context(), session); err != nil {
+				return helpers.NewTxError(err, 400, "session")
+			}
+
+			return nil
+		}); err != nil {
+			return helpers.CheckForTxError(c, err)
+		}
+
+		// Record audit event.
+		audit.Success(c, audit.EventTokenRenewal, foundedUser.ID, foundedUser.ID)
 
 		// Set expires minutes count for secret key from .env file.
 		minutesCount, err := strconv.Atoi(os.Getenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT"))
@@ -106,18 +137,7 @@ func RenewTokens(c *fiber.Ctx) error {
 		})
 
 		// Remap needed user fields from original User model output.
-		authenticatedUser := &models.AuthenticatedUser{
-			ID:         foundedUser.ID,
-			Email:      foundedUser.Email,
-			FirstName:  foundedUser.UserAttrs.FirstName,
-			LastName:   foundedUser.UserAttrs.LastName,
-			AboutMe:    foundedUser.UserAttrs.AboutMe,
-			Picture:    foundedUser.UserAttrs.Picture,
-			WebsiteURL: foundedUser.UserAttrs.WebsiteURL,
-			Abilities:  foundedUser.UserAttrs.Abilities,
-			Status:     foundedUser.UserStatus,
-			Settings:   foundedUser.UserSettings,
-		}
+		authenticatedUser := helpers.NewAuthenticatedUser(&foundedUser)
 
 		// Return status 200 OK and new access token with expiration time and user data.
 		return c.JSON(fiber.Map{
@@ -129,7 +149,93 @@ func RenewTokens(c *fiber.Ctx) error {
 			"user": authenticatedUser,
 		})
 	} else {
+		// Record audit event.
+		audit.Failure(c, audit.EventTokenRenewal, uuid.Nil, userID, "refresh token was expired")
+
 		// Return status 401 and unauthorized error message.
 		return utilities.ThrowJSONError(c, 401, "refresh token", "was expired")
 	}
 }
+
+// CreateNewClientToken method for issue a new access token for the service client
+// by the given client credentials (no refresh token).
+func (h *Handler) CreateNewClientToken(c *fiber.Ctx) error {
+	// Create a new client credentials struct.
+	clientCredentials := &models.ClientCredentials{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(clientCredentials); err != nil {
+		return utilities.CheckForError(c, err, 400, "client credentials", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(clientCredentials); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "client credentials")
+	}
+
+	// Get service client by given client ID.
+	foundedClient, status, err := h.DB.GetServiceClientByID(c.Context(), clientCredentials.ClientID)
+	if err != nil && status != 404 {
+		return utilities.CheckForError(c, err, status, "service client", err.Error())
+	}
+
+	// Compare given client secret with stored in found service client.
+	compareClientSecret, _ := helpers.ComparePasswordHash(foundedClient.SecretHash, clientCredentials.ClientSecret)
+	if status == 404 || !compareClientSecret || foundedClient.ClientStatus != 1 {
+		// Record audit event (service client is not a user, so it's given in details).
+		audit.FailureWithDetails(c, audit.EventClientTokenIssue, uuid.Nil, uuid.Nil, "wrong client id or secret", models.AuditEventDetails{
+			"client_id": clientCredentials.ClientID,
+		})
+
+		return utilities.ThrowJSONError(c, 401, "client credentials", "client id or secret")
+	}
+
+	// Set all allowed scopes by default.
+	scopes := foundedClient.Scopes
+
+	// Checking, if all of the requested scopes are allowed for the service client.
+	if len(clientCredentials.Scopes) > 0 {
+		for _, scope := range clientCredentials.Scopes {
+			if !utilities.SearchStringInArray(scope, foundedClient.Scopes) {
+				// Record audit event.
+				audit.FailureWithDetails(c, audit.EventClientTokenIssue, uuid.Nil, uuid.Nil, "scope is not allowed", models.AuditEventDetails{
+					"client_id": foundedClient.ClientID,
+					"scope":     scope,
+				})
+
+				return utilities.ThrowJSONError(c, 403, "scopes", scope)
+			}
+		}
+		scopes = clientCredentials.Scopes
+	}
+
+	// Generate a new access token for the service client.
+	token, err := helpers.GenerateNewClientAccessToken(foundedClient.ClientID, scopes)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "jwt", err.Error())
+	}
+
+	// Set expires minutes count for client key from .env file.
+	minutesCount, err := strconv.Atoi(os.Getenv("JWT_CLIENT_KEY_EXPIRE_MINUTES_COUNT"))
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "minutes count", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventClientTokenIssue, uuid.Nil, uuid.Nil, models.AuditEventDetails{
+		"client_id": foundedClient.ClientID,
+		"scopes":    strings.Join(scopes, " "),
+	})
+
+	// Return status 200 OK and new access token with expiration time.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"jwt": fiber.Map{
+			"expire": time.Now().Add(time.Minute * time.Duration(minutesCount)).Unix(),
+			"token":  token,
+		},
+	})
+}
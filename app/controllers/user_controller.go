@@ -2,10 +2,15 @@ package controllers
 
 import (
 	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
 	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/pkg/mailer"
 	"Komentory/auth/platform/database"
+	"log"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Komentory/utilities"
@@ -14,7 +19,7 @@ import (
 )
 
 // CreateNewUser method to create a new user.
-func CreateNewUser(c *fiber.Ctx) error {
+func (h *Handler) CreateNewUser(c *fiber.Ctx) error {
 	// Create a new user creation struct.
 	newUser := &models.CreateNewUser{}
 
@@ -31,15 +36,20 @@ func CreateNewUser(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "create user")
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
+	// Checking password by the password policy.
+	violations, err := helpers.CheckPassword(
+		newUser.Password, newUser.Email, newUser.UserAttrs.FirstName, newUser.UserAttrs.LastName,
+	)
 	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+	}
+	if len(violations) > 0 {
+		return helpers.ThrowPasswordPolicyError(c, "create user", "password", violations)
 	}
 
 	// Check for user is already sign up by given email.
 	// If status is 404, user is not signed up.
-	foundedUser, status, err := db.GetUserByEmail(newUser.Email)
+	foundedUser, status, err := h.Users.GetUserByEmail(c.Context(), newUser.Email)
 	if err != nil && status != 404 {
 		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
@@ -55,11 +65,17 @@ func CreateNewUser(c *fiber.Ctx) error {
 	// Create a new variable for timestamp, because time fields in User model are pointers.
 	now := time.Now()
 
+	// Create a new hash for the given password.
+	passwordHash, err := helpers.GeneratePasswordHash(newUser.Password)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+	}
+
 	// Set user data:
 	user.ID = uuid.New()
 	user.CreatedAt = &now
 	user.Email = newUser.Email
-	user.PasswordHash = utilities.GeneratePassword(newUser.Password)
+	user.PasswordHash = passwordHash
 	user.UserStatus = 0 // 0 == unconfirmed, 1 == active, 2 == blocked
 	user.UserRole = utilities.RoleNameUser
 	user.UserAttrs.FirstName = newUser.UserAttrs.FirstName
@@ -80,11 +96,6 @@ func CreateNewUser(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "user")
 	}
 
-	// Create a new user with validated data.
-	if err := db.CreateNewUser(user); err != nil {
-		return utilities.CheckForError(c, err, 400, "user", err.Error())
-	}
-
 	// Generate a new activation code with nanoID.
 	randomActivationCode, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 14)
 	if err != nil {
@@ -104,11 +115,26 @@ func CreateNewUser(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "activation code")
 	}
 
-	// Create a new activation code with validated data.
-	if err := db.CreateNewActivationCode(activationCode); err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "activation code", err.Error())
+	// Create a new user with validated data and its activation code in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Create a new user.
+		if err := r.CreateNewUser(c.Context(), user); err != nil {
+			return helpers.NewTxError(err, 400, "user")
+		}
+
+		// Create a new activation code.
+		if err := r.CreateNewActivationCode(c.Context(), activationCode); err != nil {
+			return helpers.NewTxError(err, 500, "activation code")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
 	}
 
+	// Record audit event.
+	audit.Success(c, audit.EventSignUp, user.ID, user.ID)
+
 	// Return status 201 created.
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status":          fiber.StatusCreated,
@@ -117,7 +143,7 @@ func CreateNewUser(c *fiber.Ctx) error {
 }
 
 // ActivateUser method for activate user account by given code.
-func ActivateUser(c *fiber.Ctx) error {
+func (h *Handler) ActivateUser(c *fiber.Ctx) error {
 	// Get now time.
 	now := time.Now().Unix()
 
@@ -129,14 +155,8 @@ func ActivateUser(c *fiber.Ctx) error {
 		return utilities.CheckForError(c, err, 400, "activation code", err.Error())
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
-	}
-
 	// Get code by given string.
-	foundedCode, status, err := db.GetActivationCode(applyActivationCode.Code)
+	foundedCode, status, err := h.ActivationCodes.GetActivationCode(c.Context(), applyActivationCode.Code)
 	if err != nil {
 		return utilities.CheckForError(c, err, status, "activation code", err.Error())
 	}
@@ -144,21 +164,48 @@ func ActivateUser(c *fiber.Ctx) error {
 	// Checking, if now time greather than activation code expiration time.
 	if now < foundedCode.ExpireAt.Unix() {
 		// Get user by given ID.
-		foundedUser, status, err := db.GetUserByID(foundedCode.UserID)
+		foundedUser, status, err := h.Users.GetUserByID(c.Context(), foundedCode.UserID)
 		if err != nil {
 			return utilities.CheckForError(c, err, status, "user", err.Error())
 		}
 
-		// Update user status to 1 (active).
-		if err := db.UpdateUserStatus(foundedUser.ID); err != nil {
-			return utilities.CheckForError(c, err, 400, "user", err.Error())
+		// Activate user and delete activation code in one transaction.
+		isActivated := false
+		if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+			// Get code again (it's locked until the end of transaction, so it's applied only once).
+			if _, status, err := r.GetActivationCode(c.Context(), applyActivationCode.Code); err != nil {
+				return helpers.NewTxError(err, status, "activation code")
+			}
+
+			// Update user status to 1 (active), only if user is not confirmed yet
+			// (blocked user can't unblock itself by the leftover code, the code is deleted anyway).
+			activated, err := r.ActivateUser(c.Context(), foundedUser.ID)
+			if err != nil {
+				return helpers.NewTxError(err, 400, "user")
+			}
+			isActivated = activated
+
+			// Delete activation code.
+			if err := r.DeleteActivationCode(c.Context(), applyActivationCode.Code); err != nil {
+				return helpers.NewTxError(err, 400, "activation code")
+			}
+
+			return nil
+		}); err != nil {
+			return helpers.CheckForTxError(c, err)
 		}
 
-		// Delete activation code.
-		if err := db.DeleteActivationCode(applyActivationCode.Code); err != nil {
-			return utilities.CheckForError(c, err, 400, "activation code", err.Error())
+		// Checking, if user was activated (not blocked or already active).
+		if !isActivated {
+			// Record audit event.
+			audit.Failure(c, audit.EventActivation, uuid.Nil, foundedUser.ID, "user was already activated or blocked")
+
+			return utilities.ThrowJSONError(c, 403, "user", "was already activated or blocked")
 		}
 
+		// Record audit event.
+		audit.Success(c, audit.EventActivation, foundedUser.ID, foundedUser.ID)
+
 		// Return status 200 OK.
 		// User info returns for sending welcome email by Postmark.
 		return c.JSON(fiber.Map{
@@ -169,13 +216,16 @@ func ActivateUser(c *fiber.Ctx) error {
 			},
 		})
 	} else {
+		// Record audit event.
+		audit.Failure(c, audit.EventActivation, uuid.Nil, foundedCode.UserID, "activation code was expired")
+
 		// Return status 403 and forbidden error message.
 		return utilities.ThrowJSONError(c, 403, "activation code", "was expired")
 	}
 }
 
 // UserLogin method to user login, return user model and JWT + refresh token.
-func UserLogin(c *fiber.Ctx) error {
+func (h *Handler) UserLogin(c *fiber.Ctx) error {
 	// Create a new user auth struct.
 	userLogin := &models.UserLogin{}
 
@@ -184,52 +234,149 @@ func UserLogin(c *fiber.Ctx) error {
 		return utilities.CheckForError(c, err, 400, "user login", err.Error())
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
+	// Get user by given email or username.
+	getUser := h.Users.GetUserByUsername
+	if strings.Contains(userLogin.Email, "@") {
+		getUser = h.Users.GetUserByEmail
 	}
+	foundedUser, status, err := getUser(c.Context(), userLogin.Email)
 
-	// Get user by given email.
-	foundedUser, status, err := db.GetUserByEmail(userLogin.Email)
+	// Set email of the found user as key for the login attempts (the same for email and username).
+	loginKey := userLogin.Email
+	if err == nil {
+		loginKey = foundedUser.Email
+	}
+
+	// Get brute-force protection for the login attempts.
+	loginGuard := helpers.LoginGuard()
+
+	// Register login attempt before checking of the password (parallel attempts can't bypass the limits),
+	// and checking, if login attempts are limited for the given IP address and email.
+	loginAttempt, retryAfter, errGuard := loginGuard.Attempt(c.IP(), loginKey)
+	if errGuard != nil {
+		return utilities.CheckForErrorWithStatusCode(c, errGuard, 500, "limiter", errGuard.Error())
+	}
+	if retryAfter > 0 {
+		return helpers.ThrowTooManyRequestsError(c, "user login", retryAfter)
+	}
+
+	// Checking, if user was found.
 	if err != nil {
+		// Record audit event (user is unknown).
+		audit.Failure(c, audit.EventLogin, uuid.Nil, uuid.Nil, "user not found")
+
+		// Register failed login attempt.
+		if _, err := loginAttempt.Fail(); err != nil {
+			return utilities.CheckForErrorWithStatusCode(c, err, 500, "limiter", err.Error())
+		}
+
 		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
 
-	// Compare given user password with stored in found user.
-	compareUserPassword := utilities.ComparePasswords(foundedUser.PasswordHash, userLogin.Password)
+	// Compare given user password with stored hash of the found user
+	// (on error, login attempt is left counted as failed).
+	compareUserPassword, needsRehash, status, err := h.comparePassword(c.Context(), foundedUser.ID, userLogin.Password)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
 	if !compareUserPassword {
+		// Record audit event.
+		audit.Failure(c, audit.EventLogin, uuid.Nil, foundedUser.ID, "wrong password")
+
+		// Register failed login attempt.
+		isLocked, err := loginAttempt.Fail()
+		if err != nil {
+			return utilities.CheckForErrorWithStatusCode(c, err, 500, "limiter", err.Error())
+		}
+		// Notify owner of the account about the lockout.
+		if isLocked {
+			// Record audit event.
+			audit.Failure(c, audit.EventLoginLockout, uuid.Nil, foundedUser.ID, "too many failed login attempts")
+
+			// Send email to the owner (errors are only logged, response must be the same).
+			if err := mailer.Send(&mailer.Message{
+				To:       foundedUser.Email,
+				Subject:  "Your account was temporarily locked",
+				TextBody: "There were too many failed login attempts to your account, so it was temporarily locked. If it wasn't you, please reset your password.",
+			}); err != nil {
+				log.Printf("Oops... Lockout email is not sent! Reason: %v", err)
+			}
+		}
+
 		return utilities.ThrowJSONError(c, 403, "user login", "email or password")
 	}
 
+	// Forget failed login attempts for the given email (this attempt is not counted).
+	if err := loginAttempt.Succeed(); err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "limiter", err.Error())
+	}
+
+	// Checking, if user was blocked.
+	if foundedUser.UserStatus == 2 {
+		// Record audit event.
+		audit.Failure(c, audit.EventLogin, uuid.Nil, foundedUser.ID, "user was blocked")
+
+		return utilities.ThrowJSONError(c, 403, "user", "was blocked")
+	}
+
+	// Cancel deletion of the user account, if it was requested.
+	if foundedUser.DeletionRequestedAt != nil {
+		if err := h.Users.CancelUserDeletion(c.Context(), foundedUser.ID); err != nil {
+			return utilities.CheckForError(c, err, 400, "user", err.Error())
+		}
+		foundedUser.DeletionRequestedAt = nil
+
+		// Record audit event.
+		audit.Success(c, audit.EventAccountDeletionCancel, foundedUser.ID, foundedUser.ID)
+	}
+
+	// Upgrade hash of the password, if it was made with outdated algorithm or parameters (errors are only logged).
+	if needsRehash {
+		if passwordHash, err := helpers.GeneratePasswordHash(userLogin.Password); err != nil {
+			log.Printf("Oops... Password hash is not upgraded! Reason: %v", err)
+		} else if err := h.Users.UpdateUserPasswordHash(c.Context(), foundedUser.ID, passwordHash); err != nil {
+			log.Printf("Oops... Password hash is not upgraded! Reason: %v", err)
+		}
+	}
+
+	// Checking, if current user password was found in data breaches (errors are only logged).
+	if !foundedUser.PasswordChangeRequired {
+		isBreached, err := helpers.IsBreachedPassword(userLogin.Password)
+		if err != nil {
+			log.Printf("Oops... Password is not checked in data breaches! Reason: %v", err)
+		}
+
+		// Force user to change compromised password.
+		if isBreached {
+			if err := h.Users.UpdateUserPasswordChangeRequired(c.Context(), foundedUser.ID, true); err != nil {
+				return utilities.CheckForError(c, err, 400, "user", err.Error())
+			}
+			foundedUser.PasswordChangeRequired = true
+
+			// Record audit event.
+			audit.Success(c, audit.EventPasswordBreached, uuid.Nil, foundedUser.ID)
+		}
+	}
+
 	// Generate a new pair of access and refresh tokens.
-	tokens, err := helpers.GenerateNewTokens(foundedUser.ID.String(), foundedUser.UserRole)
+	tokens, err := helpers.GenerateNewUserTokens(&foundedUser)
 	if err != nil {
 		return utilities.CheckForError(c, err, 400, "tokens", err.Error())
 	}
 
-	// Define user ID.
-	// userID := foundedUser.ID.String()
-
-	// Create a new Redis connection.
-	// connRedis, errRedisConnection := cache.RedisConnection()
-	// if errRedisConnection != nil {
-	// 	// Return status 500 and Redis connection error.
-	// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-	// 		"error": true,
-	// 		"msg":   errRedisConnection.Error(),
-	// 	})
-	// }
-
-	// Set refresh token to Redis.
-	// errSetToRedis := connRedis.Set(context.Background(), userID, tokens.Refresh, 0).Err()
-	// if errSetToRedis != nil {
-	// 	// Return status 500 and Redis connection error.
-	// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-	// 		"error": true,
-	// 		"msg":   errSetToRedis.Error(),
-	// 	})
-	// }
+	// Create a new session for the refresh token.
+	session, err := helpers.NewSession(c, foundedUser.ID, tokens.Refresh)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "session", err.Error())
+	}
+
+	// Save session with refresh token hash.
+	if err := h.Sessions.CreateNewSession(c.Context(), session); err != nil {
+		return utilities.CheckForError(c, err, 400, "session", err.Error())
+	}
+
+	// Record audit event.
+	audit.Success(c, audit.EventLogin, foundedUser.ID, foundedUser.ID)
 
 	// Set expires minutes count for secret key from .env file.
 	minutesCount, err := strconv.Atoi(os.Getenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT"))
@@ -254,18 +401,7 @@ func UserLogin(c *fiber.Ctx) error {
 	})
 
 	// Remap needed user fields from original User model output.
-	authenticatedUser := &models.AuthenticatedUser{
-		ID:         foundedUser.ID,
-		Email:      foundedUser.Email,
-		FirstName:  foundedUser.UserAttrs.FirstName,
-		LastName:   foundedUser.UserAttrs.LastName,
-		AboutMe:    foundedUser.UserAttrs.AboutMe,
-		Picture:    foundedUser.UserAttrs.Picture,
-		WebsiteURL: foundedUser.UserAttrs.WebsiteURL,
-		Abilities:  foundedUser.UserAttrs.Abilities,
-		Status:     foundedUser.UserStatus,
-		Settings:   foundedUser.UserSettings,
-	}
+	authenticatedUser := helpers.NewAuthenticatedUser(&foundedUser)
 
 	// Return status 200 OK.
 	return c.JSON(fiber.Map{
@@ -279,29 +415,28 @@ func UserLogin(c *fiber.Ctx) error {
 }
 
 // UserLogout method to de-authorize user and clear refresh token.
-func UserLogout(c *fiber.Ctx) error {
-	// Define user ID.
-	// userID := claims.UserID.String()
-
-	// Create a new Redis connection.
-	// connRedis, errRedisConnection := cache.RedisConnection()
-	// if errRedisConnection != nil {
-	// 	// Return status 500 and Redis connection error.
-	// 	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-	// 		"error": true,
-	// 		"msg":   errRedisConnection.Error(),
-	// 	})
-	// }
-
-	// Delete user token from Redis.
-	// errDelFromRedis := connRedis.Del(context.Background(), userID).Err()
-	// if errDelFromRedis != nil {
-	// 	// Return status 400 and bad request error.
-	// 	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-	// 		"error": true,
-	// 		"msg":   errDelFromRedis.Error(),
-	// 	})
-	// }
+func (h *Handler) UserLogout(c *fiber.Ctx) error {
+	// Get refresh token from client.
+	refreshToken := c.Cookies("refresh_token", "")
+
+	// Delete session of the refresh token, if it was given.
+	if refreshToken != "" {
+		// Get session by refresh token hash.
+		foundedSession, status, err := h.Sessions.GetSessionByRefreshTokenHash(c.Context(), helpers.HashToken(refreshToken))
+		if err != nil && status != 404 {
+			return utilities.CheckForError(c, err, status, "session", err.Error())
+		}
+
+		// Delete session (if found).
+		if status != 404 {
+			if err := h.Sessions.DeleteSession(c.Context(), foundedSession.ID); err != nil {
+				return utilities.CheckForError(c, err, 400, "session", err.Error())
+			}
+
+			// Record audit event.
+			audit.Success(c, audit.EventLogout, foundedSession.UserID, foundedSession.UserID)
+		}
+	}
 
 	// Clear refresh token cookie.
 	c.Cookie(&fiber.Cookie{
@@ -318,7 +453,7 @@ func UserLogout(c *fiber.Ctx) error {
 }
 
 // UpdateUserAttrs method for update user attributes.
-func UpdateUserAttrs(c *fiber.Ctx) error {
+func (h *Handler) UpdateUserAttrs(c *fiber.Ctx) error {
 	// Set needed credentials.
 	credentials := []string{
 		utilities.GenerateCredential("user_attrs", "update", true),
@@ -346,30 +481,27 @@ func UpdateUserAttrs(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "update user attrs")
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
-	}
-
 	// Get user by ID from JWT.
-	foundedUser, status, err := db.GetUserByID(claims.UserID)
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
 	if err != nil {
 		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
 
 	// Update user attributes.
-	err = db.UpdateUserAttrs(foundedUser.ID, userAttrs)
+	err = h.Users.UpdateUserAttrs(c.Context(), foundedUser.ID, userAttrs)
 	if err != nil {
 		return utilities.CheckForError(c, err, 400, "user attrs", err.Error())
 	}
 
+	// Record audit event.
+	audit.Success(c, audit.EventAttrsChange, claims.UserID, foundedUser.ID)
+
 	// Return status 204 no content.
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // UpdateUserSettings method for update user settings.
-func UpdateUserSettings(c *fiber.Ctx) error {
+func (h *Handler) UpdateUserSettings(c *fiber.Ctx) error {
 	// Set needed credentials.
 	credentials := []string{
 		utilities.GenerateCredential("user_settings", "update", true),
@@ -389,24 +521,21 @@ func UpdateUserSettings(c *fiber.Ctx) error {
 		return utilities.CheckForError(c, err, 400, "user settings", err.Error())
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
-	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
-	}
-
 	// Update user attributes.
-	err = db.UpdateUserSettings(claims.UserID, userSettings)
+	err = h.Users.UpdateUserSettings(c.Context(), claims.UserID, userSettings)
 	if err != nil {
 		return utilities.CheckForError(c, err, 400, "user settings", err.Error())
 	}
 
+	// Record audit event.
+	audit.Success(c, audit.EventSettingsChange, claims.UserID, claims.UserID)
+
 	// Return status 204 no content.
 	return c.SendStatus(fiber.StatusNoContent)
 }
 
 // UpdateUserPassword method to update user password.
-func UpdateUserPassword(c *fiber.Ctx) error {
+func (h *Handler) UpdateUserPassword(c *fiber.Ctx) error {
 	// Set needed credentials.
 	credentials := []string{
 		utilities.GenerateCredential("user_password", "update", true),
@@ -434,32 +563,200 @@ func UpdateUserPassword(c *fiber.Ctx) error {
 		return utilities.CheckForValidationError(c, err, 400, "task")
 	}
 
-	// Create database connection.
-	db, err := database.OpenDBConnection()
+	// Get user by given email.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
 	if err != nil {
-		return utilities.CheckForErrorWithStatusCode(c, err, 500, "database", err.Error())
+		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
 
-	// Get user by given email.
-	foundedUser, status, err := db.GetUserByID(claims.UserID)
+	// Compare given user password with stored hash of the found user.
+	matchUserPasswords, _, status, err := h.comparePassword(c.Context(), foundedUser.ID, updatePassword.OldPassword)
 	if err != nil {
 		return utilities.CheckForError(c, err, status, "user", err.Error())
 	}
-
-	// Compare given user password with stored in found user.
-	matchUserPasswords := utilities.ComparePasswords(foundedUser.PasswordHash, updatePassword.OldPassword)
 	if !matchUserPasswords {
+		// Record audit event.
+		audit.Failure(c, audit.EventPasswordChange, claims.UserID, foundedUser.ID, "wrong old password")
+
 		return utilities.ThrowJSONError(c, 403, "user", "email or password")
 	}
 
-	// Set initialized default data for user:
-	newPasswordHash := utilities.GeneratePassword(updatePassword.NewPassword)
+	// Checking new password by the password policy.
+	violations, err := helpers.CheckPassword(
+		updatePassword.NewPassword, foundedUser.Email, foundedUser.UserAttrs.FirstName, foundedUser.UserAttrs.LastName,
+	)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+	}
+	if len(violations) > 0 {
+		return helpers.ThrowPasswordPolicyError(c, "user password", "new_password", violations)
+	}
+
+	// Create a new hash for the new password.
+	newPasswordHash, err := helpers.GeneratePasswordHash(updatePassword.NewPassword)
+	if err != nil {
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "password", err.Error())
+	}
 
 	// Create a new user with validated data.
-	if err := db.UpdateUserPassword(foundedUser.ID, newPasswordHash); err != nil {
+	if err := h.Users.UpdateUserPassword(c.Context(), foundedUser.ID, newPasswordHash); err != nil {
 		return utilities.CheckForError(c, err, 400, "user", err.Error())
 	}
 
+	// Record audit event.
+	audit.Success(c, audit.EventPasswordChange, claims.UserID, foundedUser.ID)
+
 	// Return status 204 no content.
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// DeleteUser method to request deletion of the current user account.
+// Account is deleted after grace period, login during this period cancels the deletion.
+func (h *Handler) DeleteUser(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("user_account", "delete", true),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "delete user", err.Error())
+	}
+
+	// Create a new DeleteUser struct.
+	deleteUser := &models.DeleteUser{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(deleteUser); err != nil {
+		return utilities.CheckForError(c, err, 400, "delete user", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(deleteUser); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "delete user")
+	}
+
+	// Get user by ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Compare given user password with stored hash of the found user.
+	matchUserPassword, _, status, err := h.comparePassword(c.Context(), foundedUser.ID, deleteUser.Password)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+	if !matchUserPassword {
+		// Record audit event.
+		audit.Failure(c, audit.EventAccountDeletionRequest, claims.UserID, foundedUser.ID, "wrong password")
+
+		return utilities.ThrowJSONError(c, 403, "user", "email or password")
+	}
+
+	// Mark user account as pending deletion and revoke all its sessions in one transaction.
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Mark user account as pending deletion.
+		if err := r.RequestUserDeletion(c.Context(), foundedUser.ID); err != nil {
+			return helpers.NewTxError(err, 400, "user")
+		}
+
+		// Revoke all sessions of the user.
+		if err := r.DeleteSessionsByUserID(c.Context(), foundedUser.ID); err != nil {
+			return helpers.NewTxError(err, 400, "sessions")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Set time of the account deletion.
+	deleteAt := time.Now().Add(configs.AccountDeletionGracePeriod())
+
+	// Notify owner of the account about the deletion (errors are only logged).
+	if err := mailer.Send(&mailer.Message{
+		To:      foundedUser.Email,
+		Subject: "Your account will be deleted",
+		TextBody: "Your account will be deleted on " + deleteAt.Format("January 2, 2006") +
+			". If you change your mind, just log in before this date.",
+	}); err != nil {
+		log.Printf("Oops... Account deletion email is not sent! Reason: %v", err)
+	}
+
+	// Record audit event.
+	audit.Success(c, audit.EventAccountDeletionRequest, claims.UserID, foundedUser.ID)
+
+	// Clear refresh token cookie.
+	c.Cookie(&fiber.Cookie{
+		Name:     "refresh_token",
+		Value:    "",
+		Expires:  time.Now(),
+		SameSite: os.Getenv("COOKIE_SAME_SITE"),
+		Secure:   true,
+		HTTPOnly: true,
+	})
+
+	// Return status 200 OK and time of the account deletion.
+	return c.JSON(fiber.Map{
+		"status":    fiber.StatusOK,
+		"delete_at": deleteAt.Unix(),
+	})
+}
+
+// GetCurrentUser method to get profile of the current user (owner of the token).
+func (h *Handler) GetCurrentUser(c *fiber.Ctx) error {
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTime(c)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user", err.Error())
+	}
+
+	// Get user by ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Profile contains private data, so it must not be cached.
+	c.Set(fiber.HeaderCacheControl, "no-store")
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"user":   helpers.NewAuthenticatedUser(&foundedUser),
+	})
+}
+
+// GetPublicUser method to get public profile of the user by given ID (for anyone).
+func (h *Handler) GetPublicUser(c *fiber.Ctx) error {
+	// Parse user ID from URL.
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user", err.Error())
+	}
+
+	// Get user by ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), userID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Only active users have public profile.
+	if foundedUser.UserStatus != 1 || foundedUser.DeletionRequestedAt != nil {
+		return utilities.ThrowJSONError(c, 404, "user", "user is not active")
+	}
+
+	// Allow clients (and proxies) to cache public profile.
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(configs.PublicProfileMaxAge()))
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"user":   helpers.NewPublicUser(&foundedUser),
+	})
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/export"
+	"Komentory/auth/pkg/jobs"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CreateNewUserExport method to request export of all personal data of the current user.
+// Archive is built in background, signed download link is sent to the user email.
+func (h *Handler) CreateNewUserExport(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("user_data", "export", true),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "user export", err.Error())
+	}
+
+	// Checking, if user exists.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Start a new export job in background.
+	job := jobs.NewUserExport(h.DB, foundedUser.ID, configs.UserExportLinkTTL())
+	if err := job.Start(c.Context()); err != nil {
+		// Refuse a new export, if the previous one is not built yet.
+		if errors.Is(err, jobs.ErrUserExportPending) {
+			return utilities.ThrowJSONError(c, 403, "user export", err.Error())
+		}
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "user export", err.Error())
+	}
+
+	// Record audit event.
+	audit.SuccessWithDetails(c, audit.EventUserExportRequest, claims.UserID, foundedUser.ID, map[string]string{
+		"export_id": job.ID.String(),
+	})
+
+	// Return status 202 accepted and ID of the export.
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"status":    fiber.StatusAccepted,
+		"export_id": job.ID,
+	})
+}
+
+// GetUserExport method to download archive with personal data by the signed link.
+func (h *Handler) GetUserExport(c *fiber.Ctx) error {
+	// Get export ID from URL.
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user export", err.Error())
+	}
+
+	// Get expiration time of the link.
+	expires, err := strconv.ParseInt(c.Query("expires"), 10, 64)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "user export", "expire time is empty or not valid")
+	}
+
+	// Checking signature and expiration time of the link.
+	if err := export.Verify(configs.UserExportLinkSecretKey(), id.String(), expires, c.Query("signature")); err != nil {
+		return utilities.ThrowJSONError(c, 403, "user export", err.Error())
+	}
+
+	// Checking, if archive exists.
+	userExport, status, err := h.DB.GetUserExportArchive(c.Context(), id)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user export", "archive was removed or not built yet")
+	}
+
+	// Record audit event (user is unknown, link is the only credential).
+	audit.SuccessWithDetails(c, audit.EventUserExportDownload, uuid.Nil, uuid.Nil, map[string]string{
+		"export_id": id.String(),
+	})
+
+	// Send archive as attachment.
+	c.Attachment("personal-data.zip")
+	return c.Send(userExport.Archive)
+}
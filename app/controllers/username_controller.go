@@ -0,0 +1,190 @@
+package controllers
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// CheckUsernameAvailability method to check, if the given username can be taken.
+func (h *Handler) CheckUsernameAvailability(c *fiber.Ctx) error {
+	// Get username from URL.
+	username := c.Params("username")
+
+	// Checking username by the username policy.
+	violations := helpers.CheckUsername(username)
+	if len(violations) > 0 {
+		return c.JSON(fiber.Map{
+			"status":     fiber.StatusOK,
+			"available":  false,
+			"violations": violations,
+		})
+	}
+
+	// Checking, if username is not taken by anyone.
+	isAvailable, err := h.Usernames.IsUsernameAvailable(c.Context(), username, uuid.Nil)
+	if err != nil {
+		return utilities.CheckForError(c, err, 400, "username", err.Error())
+	}
+
+	// Set reason, if username is taken.
+	if !isAvailable {
+		violations = append(violations, "is already taken")
+	}
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status":     fiber.StatusOK,
+		"available":  isAvailable,
+		"violations": violations,
+	})
+}
+
+// GetPublicUserByUsername method to get public profile of the user by given username (for anyone).
+// Old usernames are redirected to the actual username of the same user.
+func (h *Handler) GetPublicUserByUsername(c *fiber.Ctx) error {
+	// Get username from URL.
+	username := c.Params("username")
+
+	// Get user by username.
+	foundedUser, status, err := h.Users.GetUserByUsername(c.Context(), username)
+	if err != nil && status != 404 {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Checking, if it's an old username of the user.
+	if status == 404 {
+		// Get redirect by old username.
+		foundedRedirect, status, err := h.Usernames.GetUsernameRedirect(c.Context(), username)
+		if err != nil {
+			return utilities.CheckForError(c, err, status, "user", err.Error())
+		}
+
+		// Get owner of the old username.
+		foundedUser, status, err = h.Users.GetUserByID(c.Context(), foundedRedirect.UserID)
+		if err != nil {
+			return utilities.CheckForError(c, err, status, "user", err.Error())
+		}
+
+		// Redirect to the actual username (if user is still public).
+		if foundedUser.Username != nil && foundedUser.UserStatus == 1 && foundedUser.DeletionRequestedAt == nil {
+			return c.Redirect("/v1/usernames/"+*foundedUser.Username, fiber.StatusMovedPermanently)
+		}
+	}
+
+	// Only active users have public profile.
+	if foundedUser.UserStatus != 1 || foundedUser.DeletionRequestedAt != nil {
+		return utilities.ThrowJSONError(c, 404, "user", "user is not active")
+	}
+
+	// Allow clients (and proxies) to cache public profile.
+	c.Set(fiber.HeaderCacheControl, "public, max-age="+strconv.Itoa(configs.PublicProfileMaxAge()))
+
+	// Return status 200 OK.
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"user":   helpers.NewPublicUser(&foundedUser),
+	})
+}
+
+// UpdateUsername method to set (or change) username of the current user.
+func (h *Handler) UpdateUsername(c *fiber.Ctx) error {
+	// Set needed credentials.
+	credentials := []string{
+		utilities.GenerateCredential("user_username", "update", true),
+	}
+
+	// Validate JWT token.
+	claims, err := utilities.TokenValidateExpireTimeAndCredentials(c, credentials)
+	if err != nil {
+		return utilities.CheckForError(c, err, 401, "update username", err.Error())
+	}
+
+	// Create a new UpdateUsername struct.
+	updateUsername := &models.UpdateUsername{}
+
+	// Checking received data from JSON body.
+	if err := c.BodyParser(updateUsername); err != nil {
+		return utilities.CheckForError(c, err, 400, "username", err.Error())
+	}
+
+	// Create a new validator for the struct.
+	validate := utilities.NewValidator()
+
+	// Validate fields.
+	if err := validate.Struct(updateUsername); err != nil {
+		return utilities.CheckForValidationError(c, err, 400, "username")
+	}
+
+	// Checking username by the username policy.
+	if violations := helpers.CheckUsername(updateUsername.Username); len(violations) > 0 {
+		return helpers.ThrowUsernamePolicyError(c, "username", violations)
+	}
+
+	// Get user by ID.
+	foundedUser, status, err := h.Users.GetUserByID(c.Context(), claims.UserID)
+	if err != nil {
+		return utilities.CheckForError(c, err, status, "user", err.Error())
+	}
+
+	// Checking, if username was changed recently (the first username can be set at any time),
+	// it's checked again by the update of the username in transaction.
+	if foundedUser.UsernameChangedAt != nil {
+		if nextChangeAt := foundedUser.UsernameChangedAt.Add(configs.UsernameChangeCooldown()); time.Now().Before(nextChangeAt) {
+			return utilities.ThrowJSONError(c, 403, "username", "can be changed again after "+nextChangeAt.Format(time.RFC3339))
+		}
+	}
+
+	// Lock the username, re-check its availability and update it in one transaction
+	// (parallel changes of the same user are serialized by the lock of the user row).
+	if err := h.Tx.WithTx(c.Context(), func(r database.Repository) error {
+		// Get storage of the usernames in transaction.
+		usernames, ok := database.Unwrap(r).(database.UsernameRepository)
+		if !ok {
+			return helpers.NewTxError(fmt.Errorf("usernames are not supported by the storage"), 500, "username")
+		}
+
+		// Lock the username for other transactions (until the end of this one).
+		if err := usernames.LockUsername(c.Context(), updateUsername.Username); err != nil {
+			return helpers.NewTxError(err, 400, "username")
+		}
+
+		// Checking, if the username is already taken by another user.
+		isAvailable, err := usernames.IsUsernameAvailable(c.Context(), updateUsername.Username, foundedUser.ID)
+		if err != nil {
+			return helpers.NewTxError(err, 400, "username")
+		}
+		if !isAvailable {
+			return helpers.NewTxError(fmt.Errorf("username is already taken"), 400, "username")
+		}
+
+		// Update username, if it was not changed during the cooldown (old username is kept as redirect to the user).
+		changedBefore := time.Now().Add(-configs.UsernameChangeCooldown())
+		if status, err := usernames.UpdateUserUsername(c.Context(), foundedUser.ID, updateUsername.Username, changedBefore); err != nil {
+			return helpers.NewTxError(err, status, "username")
+		}
+
+		return nil
+	}); err != nil {
+		return helpers.CheckForTxError(c, err)
+	}
+
+	// Remove changed user from cache (username is updated without storage of the users).
+	h.invalidateUser(foundedUser.ID)
+
+	// Record audit event (usernames are personal data, so they are not stored in append-only log).
+	audit.Success(c, audit.EventUsernameChange, claims.UserID, foundedUser.ID)
+
+	// Return status 204 no content.
+	return c.SendStatus(fiber.StatusNoContent)
+}
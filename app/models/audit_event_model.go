@@ -0,0 +1,67 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing audit event model.
+// ---
+
+// AuditEvent struct to describe security audit event object.
+type AuditEvent struct {
+	ID        int64             `db:"id" json:"id"`
+	CreatedAt time.Time         `db:"created_at" json:"created_at"`
+	Event     string            `db:"event" json:"event" validate:"required,lte=64"`
+	Outcome   string            `db:"outcome" json:"outcome" validate:"required,oneof=success failure"`
+	ActorID   *uuid.UUID        `db:"actor_id" json:"actor_id"`   // nil == anonymous actor
+	TargetID  *uuid.UUID        `db:"target_id" json:"target_id"` // nil == unknown target
+	IPAddress string            `db:"ip_address" json:"ip_address" validate:"lte=45"`
+	UserAgent string            `db:"user_agent" json:"user_agent" validate:"lte=255"`
+	Details   AuditEventDetails `db:"details" json:"details"`
+}
+
+// AuditEventDetails type to describe additional info of the audit event, stored in JSONB field.
+type AuditEventDetails map[string]string
+
+// ---
+// Structures to filtering audit events.
+// ---
+
+// AuditEventsFilter struct to describe filter and pagination for the list of audit events.
+type AuditEventsFilter struct {
+	Page     int    `query:"page" validate:"gte=1"`
+	Limit    int    `query:"limit" validate:"gte=1,lte=100"`
+	ActorID  string `query:"actor_id" validate:"omitempty,uuid_rfc4122"`
+	TargetID string `query:"target_id" validate:"omitempty,uuid_rfc4122"`
+	Event    string `query:"event" validate:"lte=64"`
+	Outcome  string `query:"outcome" validate:"omitempty,oneof=success failure"`
+}
+
+// ---
+// This methods simply returns the JSON-encoded representation of the struct.
+// ---
+
+// Value make the AuditEventDetails type implement the driver.Valuer interface.
+func (d *AuditEventDetails) Value() (driver.Value, error) {
+	return json.Marshal(d)
+}
+
+// ---
+// This methods simply decodes a JSON-encoded value into the struct fields.
+// ---
+
+// Scan make the AuditEventDetails type implement the sql.Scanner interface.
+func (d *AuditEventDetails) Scan(value interface{}) error {
+	j, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(j, &d)
+}
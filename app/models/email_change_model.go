@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing email change model.
+// ---
+
+// EmailChange struct to describe request to change user email object.
+type EmailChange struct {
+	ID              uuid.UUID  `db:"id" json:"id" validate:"required,uuid"`
+	CreatedAt       *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UserID          uuid.UUID  `db:"user_id" json:"user_id" validate:"required,uuid"`
+	OldEmail        string     `db:"old_email" json:"old_email" validate:"required,email,lte=255"`
+	NewEmail        string     `db:"new_email" json:"new_email" validate:"required,email,lte=255"`
+	ConfirmCode     string     `db:"confirm_code" json:"-" validate:"required,lte=14"`
+	ConfirmExpireAt time.Time  `db:"confirm_expire_at" json:"confirm_expire_at" validate:"required"`
+	RevertCode      string     `db:"revert_code" json:"-" validate:"required,lte=32"`
+	RevertExpireAt  time.Time  `db:"revert_expire_at" json:"revert_expire_at" validate:"required"`
+	ConfirmedAt     *time.Time `db:"confirmed_at" json:"confirmed_at"` // nil == not confirmed by the new email owner
+	RevertedAt      *time.Time `db:"reverted_at" json:"reverted_at"`   // nil == not reverted by the old email owner
+}
+
+// ---
+// Structures to creating a new email change.
+// ---
+
+// NewEmailChange struct to describe request to change email of the current user.
+type NewEmailChange struct {
+	Password string `json:"password" validate:"required,lte=255"`
+	NewEmail string `json:"new_email" validate:"required,email,lte=255"`
+}
+
+// ---
+// Structures to applying email change codes.
+// ---
+
+// ApplyEmailChangeCode struct to describe applying of a given confirm (or revert) code.
+type ApplyEmailChangeCode struct {
+	Code string `json:"code" validate:"required,lte=32"`
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing impersonation model.
+// ---
+
+// Impersonation struct to describe impersonation (admin acts as user) object.
+type Impersonation struct {
+	ID        uuid.UUID  `db:"id" json:"id" validate:"required,uuid"`
+	CreatedAt *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	AdminID   uuid.UUID  `db:"admin_id" json:"admin_id" validate:"required,uuid"`
+	UserID    uuid.UUID  `db:"user_id" json:"user_id" validate:"required,uuid"`
+	ExpireAt  time.Time  `db:"expire_at" json:"expire_at" validate:"required"`
+	EndedAt   *time.Time `db:"ended_at" json:"ended_at"` // nil == not ended by admin
+	IPAddress string     `db:"ip_address" json:"ip_address" validate:"lte=45"`
+	UserAgent string     `db:"user_agent" json:"user_agent" validate:"lte=255"`
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing personal access token model.
+// ---
+
+// PersonalAccessToken struct to describe personal access token object.
+type PersonalAccessToken struct {
+	ID          uuid.UUID   `db:"id" json:"id" validate:"required,uuid"`
+	CreatedAt   *time.Time  `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UserID      uuid.UUID   `db:"user_id" json:"-" validate:"required,uuid"`
+	Name        string      `db:"name" json:"name" validate:"required,lte=64"`
+	TokenHash   string      `db:"token_hash" json:"-" validate:"required,len=64"`
+	Credentials Credentials `db:"credentials" json:"credentials" validate:"required,min=1"`
+	ExpireAt    *time.Time  `db:"expire_at" json:"expire_at"`       // nil == never expires
+	LastUsedAt  *time.Time  `db:"last_used_at" json:"last_used_at"` // nil == never used
+}
+
+// Credentials type to describe list of credentials, stored in JSONB field.
+type Credentials []string
+
+// ---
+// Structures to creating a new personal access token.
+// ---
+
+// CreateNewPersonalAccessToken struct to describe creation of a new personal access token.
+type CreateNewPersonalAccessToken struct {
+	Name        string     `json:"name" validate:"required,lte=64"`
+	Credentials []string   `json:"credentials" validate:"required,min=1"`
+	ExpireAt    *time.Time `json:"expire_at"` // optional
+}
+
+// ---
+// This methods simply returns the JSON-encoded representation of the struct.
+// ---
+
+// Value make the Credentials type implement the driver.Valuer interface.
+func (c *Credentials) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+// ---
+// This methods simply decodes a JSON-encoded value into the struct fields.
+// ---
+
+// Scan make the Credentials type implement the sql.Scanner interface.
+func (c *Credentials) Scan(value interface{}) error {
+	j, ok := value.([]byte)
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(j, &c)
+}
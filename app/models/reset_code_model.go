@@ -28,5 +28,6 @@ type NewResetCode struct {
 
 // ApplyResetCode struct to describe applying of a given reset code.
 type ApplyResetCode struct {
-	Code string `json:"code" validate:"required,lte=14"`
+	Code        string `json:"code" validate:"required,lte=14"`
+	NewPassword string `json:"new_password" validate:"omitempty,lte=255"` // random password is generated, if empty
 }
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ---
+// Structures to describing role and permission models.
+// ---
+
+// Role struct to describe user role object.
+type Role struct {
+	ID          int        `db:"id" json:"id" validate:"required,int"`
+	CreatedAt   *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UpdatedAt   *time.Time `db:"updated_at" json:"updated_at,omitempty"` // pointer to time.Time for omitempty
+	Name        string     `db:"name" json:"name" validate:"required,lte=64"`
+	Credentials []string   `db:"-" json:"credentials"`
+}
+
+// Permission struct to describe permission (single credential) object.
+type Permission struct {
+	ID         int        `db:"id" json:"id"`
+	CreatedAt  *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	Credential string     `db:"credential" json:"credential" validate:"required,lte=255"`
+}
+
+// RoleCredential struct to describe credential of the role (joined role permissions).
+type RoleCredential struct {
+	RoleID     int    `db:"role_id"`
+	Credential string `db:"credential"`
+}
+
+// ---
+// Structures to creating a new role and permission.
+// ---
+
+// CreateNewRole struct to describe creation of a new role.
+type CreateNewRole struct {
+	ID   int    `json:"id" validate:"required,gt=0"`
+	Name string `json:"name" validate:"required,lte=64"`
+}
+
+// CreateNewPermission struct to describe creation of a new permission.
+type CreateNewPermission struct {
+	Credential string `json:"credential" validate:"required,lte=255"`
+}
+
+// ---
+// Structures to updating role permissions.
+// ---
+
+// UpdateRolePermissions struct to describe updating list of the role credentials.
+type UpdateRolePermissions struct {
+	Credentials []string `json:"credentials" validate:"required"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ---
+// Structures to describing service client model.
+// ---
+
+// ServiceClient struct to describe internal service (moderation bot, notification worker, etc) object.
+type ServiceClient struct {
+	ClientID     string      `db:"client_id" json:"client_id" validate:"required,lte=64"`
+	CreatedAt    *time.Time  `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UpdatedAt    *time.Time  `db:"updated_at" json:"updated_at,omitempty"` // pointer to time.Time for omitempty
+	Name         string      `db:"name" json:"name" validate:"required,lte=255"`
+	SecretHash   string      `db:"secret_hash" json:"-" validate:"required,lte=255"`
+	Scopes       Credentials `db:"scopes" json:"scopes" validate:"required"`
+	ClientStatus int         `db:"client_status" json:"client_status" validate:"int"` // 1 == active, 2 == blocked
+}
+
+// ---
+// Structures to issuing tokens for service client.
+// ---
+
+// ClientCredentials struct to describe client credentials grant.
+type ClientCredentials struct {
+	ClientID     string   `json:"client_id" validate:"required,lte=64"`
+	ClientSecret string   `json:"client_secret" validate:"required,lte=255"`
+	Scopes       []string `json:"scopes"` // optional, all allowed scopes by default
+}
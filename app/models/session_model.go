@@ -0,0 +1,22 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing session model.
+// ---
+
+// Session struct to describe user session (issued refresh token) object.
+type Session struct {
+	ID               uuid.UUID  `db:"id" json:"id" validate:"required,uuid"`
+	CreatedAt        *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UserID           uuid.UUID  `db:"user_id" json:"-" validate:"required,uuid"`
+	RefreshTokenHash string     `db:"refresh_token_hash" json:"-" validate:"required,len=64"`
+	ExpireAt         time.Time  `db:"expire_at" json:"expire_at" validate:"required"`
+	IPAddress        string     `db:"ip_address" json:"ip_address" validate:"lte=45"`
+	UserAgent        string     `db:"user_agent" json:"user_agent" validate:"lte=255"`
+}
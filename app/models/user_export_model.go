@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing user data export.
+// ---
+
+// UserExport struct to describe all personal data of the user (each field is a file in the archive).
+// Password hash and token hashes are never exported.
+type UserExport struct {
+	User                 User                  `json:"user"`
+	Sessions             []Session             `json:"sessions"`
+	SecurityLog          []AuditEvent          `json:"security_log"`
+	PersonalAccessTokens []PersonalAccessToken `json:"personal_access_tokens"`
+	EmailChanges         []EmailChange         `json:"email_changes"`
+}
+
+// MarshalJSON make the UserExport type implement the json.Marshaler interface
+// (password hash of the user is cleared, other hashes and codes are not encoded by their models).
+func (e UserExport) MarshalJSON() ([]byte, error) {
+	type userExport UserExport // without MarshalJSON method
+	data := userExport(e)
+	data.User.PasswordHash = ""
+
+	return json.Marshal(data)
+}
+
+// UserExportArchive struct to describe built archive with personal data of the user
+// (archive is nil, while the export is pending).
+type UserExportArchive struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	CreatedAt *time.Time `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
+	UserID    uuid.UUID  `db:"user_id" json:"user_id"`
+	ExpireAt  time.Time  `db:"expire_at" json:"expire_at"`
+	Archive   []byte     `db:"archive" json:"-"`
+}
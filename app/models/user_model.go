@@ -19,11 +19,16 @@ type User struct {
 	CreatedAt    *time.Time   `db:"created_at" json:"created_at,omitempty"` // pointer to time.Time for omitempty
 	UpdatedAt    *time.Time   `db:"updated_at" json:"updated_at,omitempty"` // pointer to time.Time for omitempty
 	Email        string       `db:"email" json:"email" validate:"required,email,lte=128"`
-	PasswordHash string       `db:"password_hash" json:"password_hash,omitempty" validate:"required,lte=64"`
+	PasswordHash string       `db:"password_hash" json:"password_hash,omitempty" validate:"required,lte=255"`
 	UserStatus   int          `db:"user_status" json:"user_status" validate:"int"`
 	UserRole     int          `db:"user_role" json:"user_role,omitempty" validate:"required,int"`
 	UserAttrs    UserAttrs    `db:"user_attrs" json:"user_attrs" validate:"required,dive"`
 	UserSettings UserSettings `db:"user_settings" json:"user_settings" validate:"required,dive"`
+
+	PasswordChangeRequired bool       `db:"password_change_required" json:"password_change_required"`     // true, if password was found in data breaches
+	DeletionRequestedAt    *time.Time `db:"deletion_requested_at" json:"deletion_requested_at,omitempty"` // nil == account is not pending deletion
+	Username               *string    `db:"username" json:"username,omitempty"`                           // nil == username is not set yet
+	UsernameChangedAt      *time.Time `db:"username_changed_at" json:"username_changed_at,omitempty"`
 }
 
 // UserAttrs struct to describe user attributes.
@@ -63,19 +68,33 @@ type CreateNewUser struct {
 // Structures to updating user attributes, settings and password.
 // ---
 
+// UpdateUsername struct to describe updating username.
+type UpdateUsername struct {
+	Username string `json:"username" validate:"required,lte=64"`
+}
+
 // UpdateUserPassword struct to describe updating user password.
 type UpdateUserPassword struct {
 	OldPassword string `json:"old_password" validate:"required"`
 	NewPassword string `json:"new_password" validate:"required"`
 }
 
+// ---
+// Structures to deleting user account.
+// ---
+
+// DeleteUser struct to describe request to delete account of the current user.
+type DeleteUser struct {
+	Password string `json:"password" validate:"required,lte=255"`
+}
+
 // ---
 // Structures to authenticating user.
 // ---
 
-// UserLogin struct to describe user login.
+// UserLogin struct to describe user login (by email or username).
 type UserLogin struct {
-	Email    string `json:"email" validate:"required,email,lte=255"`
+	Email    string `json:"email" validate:"required,lte=255"` // email or username
 	Password string `json:"password" validate:"required,lte=255"`
 }
 
@@ -83,6 +102,7 @@ type UserLogin struct {
 type AuthenticatedUser struct {
 	ID         uuid.UUID    `json:"id"`
 	Email      string       `json:"email"`
+	Username   string       `json:"username,omitempty"`
 	FirstName  string       `json:"first_name"`
 	LastName   string       `json:"last_name"`
 	AboutMe    string       `json:"about_me"`
@@ -91,6 +111,43 @@ type AuthenticatedUser struct {
 	Abilities  []string     `json:"abilities"`
 	Status     int          `json:"status"`
 	Settings   UserSettings `json:"settings"`
+
+	PasswordChangeRequired bool `json:"password_change_required"`
+}
+
+// PublicUser struct to describe public user profile object (for anyone).
+type PublicUser struct {
+	ID         uuid.UUID `json:"id"`
+	Username   string    `json:"username,omitempty"`
+	FirstName  string    `json:"first_name"`
+	LastName   string    `json:"last_name"`
+	AboutMe    string    `json:"about_me"`
+	Picture    string    `json:"picture"`
+	WebsiteURL string    `json:"website_url"`
+	Abilities  []string  `json:"abilities"`
+}
+
+// ---
+// Structures to managing users (by admin).
+// ---
+
+// UsersFilter struct to describe filter and pagination for the list of users.
+type UsersFilter struct {
+	Page   int    `query:"page" validate:"gte=1"`
+	Limit  int    `query:"limit" validate:"gte=1,lte=100"`
+	Status *int   `query:"status" validate:"omitempty,oneof=0 1 2"`
+	Role   *int   `query:"role" validate:"omitempty,gt=0"`
+	Search string `query:"search" validate:"lte=255"` // part of the email
+}
+
+// UpdateUserStatus struct to describe updating user status.
+type UpdateUserStatus struct {
+	UserStatus int `json:"user_status" validate:"oneof=0 1 2"` // 0 == unconfirmed, 1 == active, 2 == blocked
+}
+
+// UpdateUserRole struct to describe updating user role.
+type UpdateUserRole struct {
+	UserRole int `json:"user_role" validate:"required,gt=0"`
 }
 
 // ---
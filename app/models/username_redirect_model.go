@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ---
+// Structures to describing username redirect model.
+// ---
+
+// UsernameRedirect struct to describe redirect from the old username to its owner.
+type UsernameRedirect struct {
+	Username  string     `db:"username" json:"username" validate:"required,lte=64"` // in lower case
+	CreatedAt *time.Time `db:"created_at" json:"created_at,omitempty"`              // pointer to time.Time for omitempty
+	UserID    uuid.UUID  `db:"user_id" json:"user_id" validate:"required,uuid"`
+}
@@ -2,34 +2,40 @@ package queries
 
 import (
 	"Komentory/auth/app/models"
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jmoiron/sqlx"
 )
 
-// ResetCodeQueries struct for queries from User model.
+// activationCodeColumns is a list of the activation_codes table columns, scanned into models.ActivationCode.
+const activationCodeColumns = `code, expire_at, user_id`
+
+// ActivationCodeQueries struct for queries from ActivationCode model.
 type ActivationCodeQueries struct {
-	*sqlx.DB
+	Executor
 }
 
 // GetActivationCode query for getting activation code by given string.
-func (q *ActivationCodeQueries) GetActivationCode(code string) (models.ActivationCode, int, error) {
+// In transaction, the code is locked until its end (so it can be consumed only once).
+// Outside of transaction (and on read replica) the code is read without lock.
+func (q *ActivationCodeQueries) GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error) {
 	// Define activationCode variable.
 	activationCode := models.ActivationCode{}
 
 	// Define query string.
 	query := `
-	SELECT *
+	SELECT ` + activationCodeColumns + `
 	FROM
 		activation_codes
 	WHERE
 		code = $1::varchar
 	LIMIT 1
-	`
+	` + lockInTx(q.Executor)
 
 	// Send query to database.
-	err := q.Get(&activationCode, query, code)
+	err := q.GetContext(ctx, &activationCode, query, code)
 
 	// Get query result.
 	switch err {
@@ -46,17 +52,17 @@ func (q *ActivationCodeQueries) GetActivationCode(code string) (models.Activatio
 }
 
 // CreateNewActivationCode query for creating a new activation code for a new user.
-func (q *ActivationCodeQueries) CreateNewActivationCode(ac *models.ActivationCode) error {
+func (q *ActivationCodeQueries) CreateNewActivationCode(ctx context.Context, ac *models.ActivationCode) error {
 	// Define query string.
 	query := `
-	INSERT INTO activation_codes 
+	INSERT INTO activation_codes (` + activationCodeColumns + `)
 	VALUES (
 		$1::varchar, $2::timestamp, $3::uuid
 	)
 	`
 
 	// Send query to database.
-	_, err := q.Exec(
+	_, err := q.ExecContext(ctx,
 		query,
 		ac.Code, ac.ExpireAt, ac.UserID,
 	)
@@ -70,7 +76,7 @@ func (q *ActivationCodeQueries) CreateNewActivationCode(ac *models.ActivationCod
 }
 
 // DeleteActivationCode query for deleting activation code.
-func (q *ActivationCodeQueries) DeleteActivationCode(code string) error {
+func (q *ActivationCodeQueries) DeleteActivationCode(ctx context.Context, code string) error {
 	// Define query string.
 	query := `
 	DELETE FROM activation_codes 
@@ -78,7 +84,7 @@ func (q *ActivationCodeQueries) DeleteActivationCode(code string) error {
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, code)
+	_, err := q.ExecContext(ctx, query, code)
 	if err != nil {
 		// Return only error.
 		return err
@@ -87,3 +93,26 @@ func (q *ActivationCodeQueries) DeleteActivationCode(code string) error {
 	// This query returns nothing.
 	return nil
 }
+
+// DeleteExpiredActivationCodes query for deleting up to the given count of activation codes, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (q *ActivationCodeQueries) DeleteExpiredActivationCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	// Define query string.
+	query := `
+	DELETE FROM activation_codes
+	WHERE code IN (
+		SELECT code FROM activation_codes
+		WHERE expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, expiredBefore, limit)
+	if err != nil {
+		// Return only error.
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
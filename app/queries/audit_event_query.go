@@ -0,0 +1,127 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// auditEventColumns is a list of the audit_events table columns, scanned into models.AuditEvent.
+const auditEventColumns = `
+		id, created_at, event,
+		outcome, actor_id, target_id,
+		ip_address, user_agent, details`
+
+// auditEventInsertColumns is a list of the audit_events table columns, set on insert (ID is generated).
+const auditEventInsertColumns = `
+		created_at, event, outcome,
+		actor_id, target_id, ip_address,
+		user_agent, details`
+
+// AuditEventQueries struct for queries from AuditEvent model.
+type AuditEventQueries struct {
+	Executor
+}
+
+// GetAuditEvents query for getting list of audit events by given filter.
+func (q *AuditEventQueries) GetAuditEvents(ctx context.Context, f *models.AuditEventsFilter) ([]models.AuditEvent, int, error) {
+	// Define AuditEvent variable.
+	events := []models.AuditEvent{}
+
+	// Define query string.
+	query := `
+	SELECT` + auditEventColumns + `
+	FROM
+		audit_events
+	WHERE
+		($1::varchar = '' OR actor_id = NULLIF($1::varchar, '')::uuid)
+		AND ($2::varchar = '' OR target_id = NULLIF($2::varchar, '')::uuid)
+		AND ($3::varchar = '' OR event = $3::varchar)
+		AND ($4::varchar = '' OR outcome = $4::varchar)
+	ORDER BY
+		created_at DESC, id DESC
+	LIMIT $5::int
+	OFFSET $6::int
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx,
+		&events, query,
+		f.ActorID, f.TargetID, f.Event, f.Outcome,
+		f.Limit, (f.Page-1)*f.Limit,
+	)
+	if err != nil {
+		// Return empty object and 400 error.
+		return events, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return events, fiber.StatusOK, nil
+}
+
+// GetAuditEventsByUserID query for getting list of audit events, where the given user
+// was an actor or a target (actor and target filters are ignored).
+func (q *AuditEventQueries) GetAuditEventsByUserID(ctx context.Context, userID uuid.UUID, f *models.AuditEventsFilter) ([]models.AuditEvent, int, error) {
+	// Define AuditEvent variable.
+	events := []models.AuditEvent{}
+
+	// Define query string.
+	query := `
+	SELECT` + auditEventColumns + `
+	FROM
+		audit_events
+	WHERE
+		(actor_id = $1::uuid OR target_id = $1::uuid)
+		AND ($2::varchar = '' OR event = $2::varchar)
+		AND ($3::varchar = '' OR outcome = $3::varchar)
+	ORDER BY
+		created_at DESC, id DESC
+	LIMIT $4::int
+	OFFSET $5::int
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx,
+		&events, query,
+		userID, f.Event, f.Outcome,
+		f.Limit, (f.Page-1)*f.Limit,
+	)
+	if err != nil {
+		// Return empty object and 400 error.
+		return events, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return events, fiber.StatusOK, nil
+}
+
+// CreateNewAuditEvent query for creating a new audit event (events can't be updated or deleted).
+func (q *AuditEventQueries) CreateNewAuditEvent(ctx context.Context, e *models.AuditEvent) error {
+	// Define query string.
+	query := `
+	INSERT INTO audit_events (` + auditEventInsertColumns + `)
+	VALUES (
+		$1::timestamp, $2::varchar, $3::varchar,
+		$4::uuid, $5::uuid, $6::varchar,
+		$7::varchar, $8::jsonb
+	)
+	RETURNING id
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx,
+		&e.ID, query,
+		e.CreatedAt, e.Event, e.Outcome,
+		e.ActorID, e.TargetID, e.IPAddress,
+		e.UserAgent, &e.Details,
+	)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query sets ID of the created audit event only.
+	return nil
+}
@@ -0,0 +1,179 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// emailChangeColumns is a list of the email_changes table columns, scanned into models.EmailChange.
+const emailChangeColumns = `
+		id, created_at, user_id,
+		old_email, new_email,
+		confirm_code, confirm_expire_at,
+		revert_code, revert_expire_at,
+		confirmed_at, reverted_at`
+
+// emailChangeInsertColumns is a list of the email_changes table columns, set on insert
+// (email change is neither confirmed, nor reverted yet).
+const emailChangeInsertColumns = `
+		id, created_at, user_id,
+		old_email, new_email,
+		confirm_code, confirm_expire_at,
+		revert_code, revert_expire_at`
+
+// EmailChangeQueries struct for queries from EmailChange model.
+type EmailChangeQueries struct {
+	Executor
+}
+
+// GetEmailChangeByConfirmCode query for getting email change by given confirm code.
+// In transaction, the email change is locked until its end (so it can be applied only once).
+func (q *EmailChangeQueries) GetEmailChangeByConfirmCode(ctx context.Context, code string) (models.EmailChange, int, error) {
+	return q.getEmailChange(ctx, `SELECT`+emailChangeColumns+` FROM email_changes WHERE confirm_code = $1::varchar LIMIT 1 `+lockInTx(q.Executor), code)
+}
+
+// GetEmailChangeByRevertCode query for getting email change by given revert code.
+// In transaction, the email change is locked until its end (so it can be applied only once).
+func (q *EmailChangeQueries) GetEmailChangeByRevertCode(ctx context.Context, code string) (models.EmailChange, int, error) {
+	return q.getEmailChange(ctx, `SELECT`+emailChangeColumns+` FROM email_changes WHERE revert_code = $1::varchar LIMIT 1 `+lockInTx(q.Executor), code)
+}
+
+// GetEmailChangesByUserID query for getting all email changes by given user ID.
+func (q *EmailChangeQueries) GetEmailChangesByUserID(ctx context.Context, userID uuid.UUID) ([]models.EmailChange, int, error) {
+	// Define EmailChange variable.
+	emailChanges := []models.EmailChange{}
+
+	// Define query string.
+	query := `
+	SELECT` + emailChangeColumns + `
+	FROM
+		email_changes
+	WHERE
+		user_id = $1::uuid
+	ORDER BY
+		created_at DESC
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &emailChanges, query, userID)
+	if err != nil {
+		// Return empty object and 400 error.
+		return emailChanges, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return emailChanges, fiber.StatusOK, nil
+}
+
+// CreateNewEmailChange query for creating a new email change.
+// All not confirmed email changes of the user are deleted by the same statement.
+func (q *EmailChangeQueries) CreateNewEmailChange(ctx context.Context, ec *models.EmailChange) error {
+	// Define query string.
+	query := `
+	WITH deleted_email_changes AS (
+		DELETE FROM email_changes
+		WHERE user_id = $3::uuid AND confirmed_at IS NULL AND reverted_at IS NULL
+	)
+	INSERT INTO email_changes (` + emailChangeInsertColumns + `)
+	VALUES (
+		$1::uuid, $2::timestamp, $3::uuid,
+		$4::varchar, $5::varchar,
+		$6::varchar, $7::timestamp,
+		$8::varchar, $9::timestamp
+	)
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx,
+		query,
+		ec.ID, ec.CreatedAt, ec.UserID,
+		ec.OldEmail, ec.NewEmail,
+		ec.ConfirmCode, ec.ConfirmExpireAt,
+		ec.RevertCode, ec.RevertExpireAt,
+	)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// MarkEmailChangeConfirmed query for marking email change as confirmed.
+func (q *EmailChangeQueries) MarkEmailChangeConfirmed(ctx context.Context, id uuid.UUID) error {
+	return q.markEmailChange(ctx, `UPDATE email_changes SET confirmed_at = NOW () WHERE id = $1::uuid`, id)
+}
+
+// MarkEmailChangeReverted query for marking email change as reverted.
+func (q *EmailChangeQueries) MarkEmailChangeReverted(ctx context.Context, id uuid.UUID) error {
+	return q.markEmailChange(ctx, `UPDATE email_changes SET reverted_at = NOW () WHERE id = $1::uuid`, id)
+}
+
+// LockEmail query for locking the email for other transactions until the end of the current one
+// (so uniqueness of the email can be checked before its change). It must be run in transaction.
+func (q *EmailChangeQueries) LockEmail(ctx context.Context, email string) error {
+	// Send query to database.
+	_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext($1::varchar))`, email)
+
+	return err
+}
+
+// UpdateUserEmail query for replacing email of the user by the given one (only, if it's not changed again).
+// Returns false, if email of the user is not the given old one.
+func (q *EmailChangeQueries) UpdateUserEmail(ctx context.Context, id uuid.UUID, oldEmail, newEmail string) (bool, error) {
+	// Send query to database.
+	result, err := q.ExecContext(ctx,
+		`UPDATE users SET updated_at = NOW (), email = $3::varchar WHERE id = $1::uuid AND email = $2::varchar`,
+		id, oldEmail, newEmail,
+	)
+	if err != nil {
+		// Return only error.
+		return false, err
+	}
+
+	// Checking, if email was replaced.
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (q *EmailChangeQueries) getEmailChange(ctx context.Context, query string, args ...interface{}) (models.EmailChange, int, error) {
+	// Define EmailChange variable.
+	emailChange := models.EmailChange{}
+
+	// Send query to database.
+	err := q.GetContext(ctx, &emailChange, query, args...)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return emailChange, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return emailChange, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return emailChange, fiber.StatusBadRequest, err
+	}
+}
+
+func (q *EmailChangeQueries) markEmailChange(ctx context.Context, query string, id uuid.UUID) error {
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
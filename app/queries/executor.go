@@ -0,0 +1,95 @@
+package queries
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Executor interface to describe connection pool (*sqlx.DB) or transaction (*sqlx.Tx) for the queries.
+type Executor interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// WithTimeout func for limiting duration of each query of the given executor
+// (zero timeout means no limit, only the given context is used).
+func WithTimeout(e Executor, timeout time.Duration) Executor {
+	if timeout <= 0 {
+		return e
+	}
+
+	return &timeoutExecutor{Executor: e, timeout: timeout}
+}
+
+// timeoutExecutor struct to describe executor, which cancels each query after the timeout.
+type timeoutExecutor struct {
+	Executor
+	timeout time.Duration
+}
+
+// GetContext method to get one row with the query timeout.
+func (e *timeoutExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	return e.Executor.GetContext(ctx, dest, query, args...)
+}
+
+// SelectContext method to get many rows with the query timeout.
+func (e *timeoutExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	return e.Executor.SelectContext(ctx, dest, query, args...)
+}
+
+// ExecContext method to run the query without rows with the query timeout.
+func (e *timeoutExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	return e.Executor.ExecContext(ctx, query, args...)
+}
+
+// lockInTx func for getting row lock clause of the executor: rows are locked until the end
+// of transaction, outside of it there is nothing to lock (read replica rejects locking reads).
+func lockInTx(e Executor) string {
+	switch executor := e.(type) {
+	case *timeoutExecutor:
+		return lockInTx(executor.Executor)
+	case *sqlx.Tx:
+		return `FOR UPDATE`
+	default:
+		return ``
+	}
+}
+
+// Unsupported func for creating executor, which fails each query with the given error
+// (for queries, which are not implemented by the selected database driver).
+func Unsupported(err error) Executor {
+	return &unsupportedExecutor{err: err}
+}
+
+// unsupportedExecutor struct to describe executor without database.
+type unsupportedExecutor struct {
+	err error
+}
+
+// GetContext method to fail getting one row.
+func (e *unsupportedExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return e.err
+}
+
+// SelectContext method to fail getting many rows.
+func (e *unsupportedExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return e.err
+}
+
+// ExecContext method to fail running the query.
+func (e *unsupportedExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, e.err
+}
@@ -0,0 +1,134 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// impersonationColumns is a list of the impersonations table columns, scanned into models.Impersonation.
+const impersonationColumns = `
+		id, created_at, admin_id,
+		user_id, expire_at, ended_at,
+		ip_address, user_agent`
+
+// impersonationInsertColumns is a list of the impersonations table columns, set on insert
+// (impersonation is not ended yet).
+const impersonationInsertColumns = `
+		id, created_at, admin_id,
+		user_id, expire_at,
+		ip_address, user_agent`
+
+// ImpersonationQueries struct for queries from Impersonation model.
+type ImpersonationQueries struct {
+	Executor
+}
+
+// GetImpersonationByID query for getting impersonation by given ID.
+func (q *ImpersonationQueries) GetImpersonationByID(ctx context.Context, id uuid.UUID) (models.Impersonation, int, error) {
+	// Define Impersonation variable.
+	impersonation := models.Impersonation{}
+
+	// Define query string.
+	query := `
+	SELECT` + impersonationColumns + `
+	FROM
+		impersonations
+	WHERE
+		id = $1::uuid
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &impersonation, query, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return impersonation, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return impersonation, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return impersonation, fiber.StatusBadRequest, err
+	}
+}
+
+// CreateNewImpersonation query for creating a new impersonation.
+func (q *ImpersonationQueries) CreateNewImpersonation(ctx context.Context, i *models.Impersonation) error {
+	// Define query string.
+	query := `
+	INSERT INTO impersonations (` + impersonationInsertColumns + `)
+	VALUES (
+		$1::uuid, $2::timestamp, $3::uuid,
+		$4::uuid, $5::timestamp,
+		$6::varchar, $7::varchar
+	)
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx,
+		query,
+		i.ID, i.CreatedAt, i.AdminID,
+		i.UserID, i.ExpireAt,
+		i.IPAddress, i.UserAgent,
+	)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// EndImpersonation query for ending impersonation by given ID.
+func (q *ImpersonationQueries) EndImpersonation(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	UPDATE impersonations
+	SET ended_at = NOW ()
+	WHERE id = $1::uuid AND ended_at IS NULL
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// EndExpiredImpersonations query for ending a batch of impersonations, which expired before the given time
+// and were not ended by admin (end time is set to the expiration time). It returns the ended impersonations.
+func (q *ImpersonationQueries) EndExpiredImpersonations(ctx context.Context, expiredBefore time.Time, limit int) ([]models.Impersonation, error) {
+	// Define impersonations variable.
+	impersonations := []models.Impersonation{}
+
+	// Define query string.
+	query := `
+	UPDATE impersonations
+	SET ended_at = expire_at
+	WHERE id IN (
+		SELECT id FROM impersonations
+		WHERE ended_at IS NULL AND expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	RETURNING` + impersonationColumns
+
+	// Send query to database.
+	if err := q.SelectContext(ctx, &impersonations, query, expiredBefore, limit); err != nil {
+		return impersonations, err
+	}
+
+	return impersonations, nil
+}
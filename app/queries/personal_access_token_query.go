@@ -0,0 +1,206 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// personalAccessTokenColumns is a list of the personal_access_tokens table columns, scanned into models.PersonalAccessToken.
+const personalAccessTokenColumns = `
+		id, created_at, user_id,
+		name, token_hash, credentials,
+		expire_at, last_used_at`
+
+// PersonalAccessTokenQueries struct for queries from PersonalAccessToken model.
+type PersonalAccessTokenQueries struct {
+	Executor
+}
+
+// GetPersonalAccessTokenByID query for getting personal access token by given ID.
+func (q *PersonalAccessTokenQueries) GetPersonalAccessTokenByID(ctx context.Context, id uuid.UUID) (models.PersonalAccessToken, int, error) {
+	// Define PersonalAccessToken variable.
+	personalAccessToken := models.PersonalAccessToken{}
+
+	// Define query string.
+	query := `
+	SELECT` + personalAccessTokenColumns + `
+	FROM
+		personal_access_tokens
+	WHERE
+		id = $1::uuid
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &personalAccessToken, query, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return personalAccessToken, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return personalAccessToken, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return personalAccessToken, fiber.StatusBadRequest, err
+	}
+}
+
+// GetPersonalAccessTokenByHash query for getting personal access token by given token hash.
+func (q *PersonalAccessTokenQueries) GetPersonalAccessTokenByHash(ctx context.Context, tokenHash string) (models.PersonalAccessToken, int, error) {
+	// Define PersonalAccessToken variable.
+	personalAccessToken := models.PersonalAccessToken{}
+
+	// Define query string.
+	query := `
+	SELECT` + personalAccessTokenColumns + `
+	FROM
+		personal_access_tokens
+	WHERE
+		token_hash = $1::varchar
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &personalAccessToken, query, tokenHash)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return personalAccessToken, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return personalAccessToken, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return personalAccessToken, fiber.StatusBadRequest, err
+	}
+}
+
+// GetPersonalAccessTokensByUserID query for getting all personal access tokens by given user ID.
+func (q *PersonalAccessTokenQueries) GetPersonalAccessTokensByUserID(ctx context.Context, userID uuid.UUID) ([]models.PersonalAccessToken, int, error) {
+	// Define PersonalAccessToken variable.
+	personalAccessTokens := []models.PersonalAccessToken{}
+
+	// Define query string.
+	query := `
+	SELECT` + personalAccessTokenColumns + `
+	FROM
+		personal_access_tokens
+	WHERE
+		user_id = $1::uuid
+	ORDER BY
+		created_at DESC
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &personalAccessTokens, query, userID)
+	if err != nil {
+		// Return empty object and 400 error.
+		return personalAccessTokens, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return personalAccessTokens, fiber.StatusOK, nil
+}
+
+// CreateNewPersonalAccessToken query for creating a new personal access token.
+func (q *PersonalAccessTokenQueries) CreateNewPersonalAccessToken(ctx context.Context, t *models.PersonalAccessToken) error {
+	// Define query string.
+	query := `
+	INSERT INTO personal_access_tokens (` + personalAccessTokenColumns + `)
+	VALUES (
+		$1::uuid, $2::timestamp, $3::uuid,
+		$4::varchar, $5::varchar, $6::jsonb,
+		$7::timestamp, $8::timestamp
+	)
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx,
+		query,
+		t.ID, t.CreatedAt, t.UserID,
+		t.Name, t.TokenHash, &t.Credentials,
+		t.ExpireAt, t.LastUsedAt,
+	)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// UpdatePersonalAccessTokenLastUsedAt query for updating last usage time of the personal access token.
+func (q *PersonalAccessTokenQueries) UpdatePersonalAccessTokenLastUsedAt(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	UPDATE
+		personal_access_tokens
+	SET
+		last_used_at = $2::timestamp
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeletePersonalAccessToken query for deleting (revoking) personal access token by given ID.
+func (q *PersonalAccessTokenQueries) DeletePersonalAccessToken(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	DELETE FROM personal_access_tokens
+	WHERE id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteExpiredPersonalAccessTokens query for deleting up to the given count of personal access tokens (tokens without expiration are kept), which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (q *PersonalAccessTokenQueries) DeleteExpiredPersonalAccessTokens(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	// Define query string.
+	query := `
+	DELETE FROM personal_access_tokens
+	WHERE id IN (
+		SELECT id FROM personal_access_tokens
+		WHERE expire_at IS NOT NULL AND expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, expiredBefore, limit)
+	if err != nil {
+		// Return only error.
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
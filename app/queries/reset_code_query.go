@@ -2,34 +2,40 @@ package queries
 
 import (
 	"Komentory/auth/app/models"
+	"context"
 	"database/sql"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/jmoiron/sqlx"
 )
 
-// ResetCodeQueries struct for queries from User model.
+// resetCodeColumns is a list of the reset_codes table columns, scanned into models.ResetCode.
+const resetCodeColumns = `code, expire_at, email`
+
+// ResetCodeQueries struct for queries from ResetCode model.
 type ResetCodeQueries struct {
-	*sqlx.DB
+	Executor
 }
 
 // GetResetCode query for getting reset code by given string.
-func (q *ResetCodeQueries) GetResetCode(code string) (models.ResetCode, int, error) {
+// In transaction, the code is locked until its end (so it can be consumed only once).
+// Outside of transaction (and on read replica) the code is read without lock.
+func (q *ResetCodeQueries) GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error) {
 	// Define ResetCode variable.
 	resetCode := models.ResetCode{}
 
 	// Define query string.
 	query := `
-	SELECT *
+	SELECT ` + resetCodeColumns + `
 	FROM
 		reset_codes
 	WHERE
 		code = $1::varchar
 	LIMIT 1
-	`
+	` + lockInTx(q.Executor)
 
 	// Send query to database.
-	err := q.Get(&resetCode, query, code)
+	err := q.GetContext(ctx, &resetCode, query, code)
 
 	// Get query result.
 	switch err {
@@ -46,17 +52,17 @@ func (q *ResetCodeQueries) GetResetCode(code string) (models.ResetCode, int, err
 }
 
 // CreateNewResetCode query for creating a new reset code.
-func (q *ResetCodeQueries) CreateNewResetCode(rc *models.ResetCode) error {
+func (q *ResetCodeQueries) CreateNewResetCode(ctx context.Context, rc *models.ResetCode) error {
 	// Define query string.
 	query := `
-	INSERT INTO reset_codes 
+	INSERT INTO reset_codes (` + resetCodeColumns + `)
 	VALUES (
 		$1::varchar, $2::timestamp, $3::varchar
 	)
 	`
 
 	// Send query to database.
-	_, err := q.Exec(
+	_, err := q.ExecContext(ctx,
 		query,
 		rc.Code, rc.ExpireAt, rc.Email,
 	)
@@ -70,7 +76,7 @@ func (q *ResetCodeQueries) CreateNewResetCode(rc *models.ResetCode) error {
 }
 
 // DeleteResetCode query for deleting reset code.
-func (q *ResetCodeQueries) DeleteResetCode(code string) error {
+func (q *ResetCodeQueries) DeleteResetCode(ctx context.Context, code string) error {
 	// Define query string.
 	query := `
 	DELETE FROM reset_codes 
@@ -78,7 +84,7 @@ func (q *ResetCodeQueries) DeleteResetCode(code string) error {
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, code)
+	_, err := q.ExecContext(ctx, query, code)
 	if err != nil {
 		// Return only error.
 		return err
@@ -89,7 +95,7 @@ func (q *ResetCodeQueries) DeleteResetCode(code string) error {
 }
 
 // DeleteResetCodesByEmail query for deleting all reset codes for the given email.
-func (q *ResetCodeQueries) DeleteResetCodesByEmail(email string) error {
+func (q *ResetCodeQueries) DeleteResetCodesByEmail(ctx context.Context, email string) error {
 	// Define query string.
 	query := `
 	DELETE FROM reset_codes 
@@ -97,7 +103,7 @@ func (q *ResetCodeQueries) DeleteResetCodesByEmail(email string) error {
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, email)
+	_, err := q.ExecContext(ctx, query, email)
 	if err != nil {
 		// Return only error.
 		return err
@@ -106,3 +112,26 @@ func (q *ResetCodeQueries) DeleteResetCodesByEmail(email string) error {
 	// This query returns nothing.
 	return nil
 }
+
+// DeleteExpiredResetCodes query for deleting up to the given count of reset codes, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (q *ResetCodeQueries) DeleteExpiredResetCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	// Define query string.
+	query := `
+	DELETE FROM reset_codes
+	WHERE code IN (
+		SELECT code FROM reset_codes
+		WHERE expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, expiredBefore, limit)
+	if err != nil {
+		// Return only error.
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,316 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Lists of the roles, permissions and role_permissions tables columns, scanned into models
+// (role permissions are set by credentials).
+const (
+	roleColumns = `
+		id, created_at, updated_at, name`
+	permissionColumns = `
+		id, created_at, credential`
+	rolePermissionColumns = `role_id, permission_id`
+)
+
+// RoleQueries struct for queries from Role and Permission models.
+type RoleQueries struct {
+	Executor
+}
+
+// GetRoles query for getting all roles.
+func (q *RoleQueries) GetRoles(ctx context.Context) ([]models.Role, int, error) {
+	// Define Role variable.
+	roles := []models.Role{}
+
+	// Define query string.
+	query := `
+	SELECT` + roleColumns + `
+	FROM
+		roles
+	ORDER BY
+		id
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &roles, query)
+	if err != nil {
+		// Return empty object and 400 error.
+		return roles, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return roles, fiber.StatusOK, nil
+}
+
+// GetRoleByID query for getting one role by given ID.
+func (q *RoleQueries) GetRoleByID(ctx context.Context, id int) (models.Role, int, error) {
+	// Define Role variable.
+	role := models.Role{}
+
+	// Define query string.
+	query := `
+	SELECT` + roleColumns + `
+	FROM
+		roles
+	WHERE
+		id = $1::int
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &role, query, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return role, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return role, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return role, fiber.StatusBadRequest, err
+	}
+}
+
+// GetRoleCredentials query for getting credentials of all roles.
+func (q *RoleQueries) GetRoleCredentials(ctx context.Context) ([]models.RoleCredential, int, error) {
+	// Define RoleCredential variable.
+	roleCredentials := []models.RoleCredential{}
+
+	// Define query string.
+	query := `
+	SELECT
+		rp.role_id, p.credential
+	FROM
+		role_permissions AS rp
+		JOIN permissions AS p ON p.id = rp.permission_id
+	ORDER BY
+		rp.role_id, p.id
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &roleCredentials, query)
+	if err != nil {
+		// Return empty object and 400 error.
+		return roleCredentials, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return roleCredentials, fiber.StatusOK, nil
+}
+
+// CreateNewRole query for creating a new role.
+func (q *RoleQueries) CreateNewRole(ctx context.Context, r *models.Role) error {
+	// Define query string.
+	query := `
+	INSERT INTO roles (id, created_at, name)
+	VALUES (
+		$1::int, $2::timestamp, $3::varchar
+	)
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, r.ID, r.CreatedAt, r.Name)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteRole query for deleting role by given ID.
+func (q *RoleQueries) DeleteRole(ctx context.Context, id int) error {
+	// Define query string.
+	query := `
+	DELETE FROM roles
+	WHERE id = $1::int
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteRolePermissions query for deleting all permissions of the role.
+func (q *RoleQueries) DeleteRolePermissions(ctx context.Context, id int) error {
+	// Define query string.
+	query := `
+	DELETE FROM role_permissions
+	WHERE role_id = $1::int
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// AddRolePermission query for adding permission with given credential to the role
+// (replacing of all permissions must be run in transaction with DeleteRolePermissions).
+func (q *RoleQueries) AddRolePermission(ctx context.Context, id int, credential string) error {
+	// Define query string.
+	query := `
+	INSERT INTO role_permissions (` + rolePermissionColumns + `)
+	SELECT $1::int, id FROM permissions WHERE credential = $2::varchar
+	ON CONFLICT DO NOTHING
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, id, credential)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// Checking, if permission with given credential is exists.
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return fmt.Errorf("permission '%s' is not found", credential)
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// TouchRole query for updating time of the last change of the role.
+func (q *RoleQueries) TouchRole(ctx context.Context, id int) error {
+	// Define query string.
+	query := `
+	UPDATE
+		roles
+	SET
+		updated_at = $2::timestamp
+	WHERE
+		id = $1::int
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// GetPermissions query for getting all permissions.
+func (q *RoleQueries) GetPermissions(ctx context.Context) ([]models.Permission, int, error) {
+	// Define Permission variable.
+	permissions := []models.Permission{}
+
+	// Define query string.
+	query := `
+	SELECT` + permissionColumns + `
+	FROM
+		permissions
+	ORDER BY
+		id
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &permissions, query)
+	if err != nil {
+		// Return empty object and 400 error.
+		return permissions, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return permissions, fiber.StatusOK, nil
+}
+
+// GetPermissionByID query for getting one permission by given ID.
+func (q *RoleQueries) GetPermissionByID(ctx context.Context, id int) (models.Permission, int, error) {
+	// Define Permission variable.
+	permission := models.Permission{}
+
+	// Define query string.
+	query := `
+	SELECT` + permissionColumns + `
+	FROM
+		permissions
+	WHERE
+		id = $1::int
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &permission, query, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return permission, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return permission, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return permission, fiber.StatusBadRequest, err
+	}
+}
+
+// CreateNewPermission query for creating a new permission.
+func (q *RoleQueries) CreateNewPermission(ctx context.Context, p *models.Permission) error {
+	// Define query string.
+	query := `
+	INSERT INTO permissions (created_at, credential)
+	VALUES (
+		$1::timestamp, $2::varchar
+	)
+	RETURNING id
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &p.ID, query, p.CreatedAt, p.Credential)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query sets ID of the created permission only.
+	return nil
+}
+
+// DeletePermission query for deleting permission by given ID.
+func (q *RoleQueries) DeletePermission(ctx context.Context, id int) error {
+	// Define query string.
+	query := `
+	DELETE FROM permissions
+	WHERE id = $1::int
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
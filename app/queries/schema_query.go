@@ -0,0 +1,109 @@
+package queries
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// expectedColumns is a list of columns of each table, which are used by the queries with explicit columns.
+var expectedColumns = map[string]string{
+	"users":                  userColumns,
+	"activation_codes":       activationCodeColumns,
+	"reset_codes":            resetCodeColumns,
+	"email_changes":          emailChangeColumns,
+	"sessions":               sessionColumns,
+	"personal_access_tokens": personalAccessTokenColumns,
+	"impersonations":         impersonationColumns,
+	"audit_events":           auditEventColumns,
+	"service_clients":        serviceClientColumns,
+	"username_redirects":     usernameRedirectColumns,
+	"user_exports":           userExportColumns,
+	"roles":                  roleColumns,
+	"permissions":            permissionColumns,
+	"role_permissions":       rolePermissionColumns,
+}
+
+// tableColumn struct to describe column of the table from information_schema.
+type tableColumn struct {
+	TableName  string `db:"table_name"`
+	ColumnName string `db:"column_name"`
+	IsRequired bool   `db:"is_required"` // NOT NULL without default value
+}
+
+// SchemaQueries struct for queries to the database schema.
+type SchemaQueries struct {
+	Executor
+}
+
+// CheckSchema query for comparing expected columns of the tables with the database schema.
+// It returns error with the diff, if any expected column is missing, or unknown column is required
+// for inserts (NOT NULL without default value). Other unknown columns are allowed (for rolling migrations).
+func (q *SchemaQueries) CheckSchema(ctx context.Context) error {
+	// Define columns variable.
+	columns := []tableColumn{}
+
+	// Define query string.
+	query := `
+	SELECT
+		table_name, column_name,
+		is_nullable = 'NO' AND column_default IS NULL AND is_identity = 'NO' AS is_required
+	FROM
+		information_schema.columns
+	WHERE
+		table_schema = current_schema()
+		AND table_name = ANY (string_to_array($1::text, ','))
+	`
+
+	// Send query to database.
+	tables := make([]string, 0, len(expectedColumns))
+	for table := range expectedColumns {
+		tables = append(tables, table)
+	}
+	sort.Strings(tables)
+	if err := q.SelectContext(ctx, &columns, query, strings.Join(tables, ",")); err != nil {
+		return err
+	}
+
+	// Group actual columns by table.
+	actual := map[string]map[string]bool{}
+	for _, column := range columns {
+		if actual[column.TableName] == nil {
+			actual[column.TableName] = map[string]bool{}
+		}
+		actual[column.TableName][column.ColumnName] = column.IsRequired
+	}
+
+	// Compare expected and actual columns of each table.
+	diff := []string{}
+	for _, table := range tables {
+		expected := map[string]bool{}
+		for _, column := range strings.Split(expectedColumns[table], ",") {
+			column = strings.TrimSpace(column)
+			expected[column] = true
+
+			if _, ok := actual[table][column]; !ok {
+				diff = append(diff, fmt.Sprintf("- %s.%s (missing in database)", table, column))
+			}
+		}
+
+		unknown := []string{}
+		for column, isRequired := range actual[table] {
+			if !expected[column] && isRequired {
+				unknown = append(unknown, column)
+			}
+		}
+		sort.Strings(unknown)
+		for _, column := range unknown {
+			diff = append(diff, fmt.Sprintf("+ %s.%s (required in database, unknown to queries)", table, column))
+		}
+	}
+
+	// Return error with diff, if schema is drifted.
+	if len(diff) > 0 {
+		return fmt.Errorf("database schema does not match queries:\n%s", strings.Join(diff, "\n"))
+	}
+
+	return nil
+}
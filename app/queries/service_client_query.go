@@ -0,0 +1,52 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// serviceClientColumns is a list of the service_clients table columns, scanned into models.ServiceClient.
+const serviceClientColumns = `
+		client_id, created_at, updated_at,
+		name, secret_hash, scopes,
+		client_status`
+
+// ServiceClientQueries struct for queries from ServiceClient model.
+type ServiceClientQueries struct {
+	Executor
+}
+
+// GetServiceClientByID query for getting one service client by given client ID.
+func (q *ServiceClientQueries) GetServiceClientByID(ctx context.Context, clientID string) (models.ServiceClient, int, error) {
+	// Define ServiceClient variable.
+	serviceClient := models.ServiceClient{}
+
+	// Define query string.
+	query := `
+	SELECT` + serviceClientColumns + `
+	FROM
+		service_clients
+	WHERE
+		client_id = $1::varchar
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &serviceClient, query, clientID)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return serviceClient, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return serviceClient, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return serviceClient, fiber.StatusBadRequest, err
+	}
+}
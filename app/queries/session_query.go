@@ -0,0 +1,172 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// sessionColumns is a list of the sessions table columns, scanned into models.Session.
+const sessionColumns = `
+		id, created_at, user_id,
+		refresh_token_hash, expire_at, ip_address,
+		user_agent`
+
+// SessionQueries struct for queries from Session model.
+type SessionQueries struct {
+	Executor
+}
+
+// GetSessionByRefreshTokenHash query for getting session by given refresh token hash.
+// In transaction, the session is locked until its end (so refresh token can be rotated only once).
+func (q *SessionQueries) GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (models.Session, int, error) {
+	// Define Session variable.
+	session := models.Session{}
+
+	// Define query string.
+	query := `
+	SELECT` + sessionColumns + `
+	FROM
+		sessions
+	WHERE
+		refresh_token_hash = $1::varchar
+	LIMIT 1
+	FOR UPDATE
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &session, query, refreshTokenHash)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return session, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return session, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return session, fiber.StatusBadRequest, err
+	}
+}
+
+// GetSessionsByUserID query for getting all sessions by given user ID.
+func (q *SessionQueries) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, int, error) {
+	// Define Session variable.
+	sessions := []models.Session{}
+
+	// Define query string.
+	query := `
+	SELECT` + sessionColumns + `
+	FROM
+		sessions
+	WHERE
+		user_id = $1::uuid
+	ORDER BY
+		created_at DESC
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx, &sessions, query, userID)
+	if err != nil {
+		// Return empty object and 400 error.
+		return sessions, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return sessions, fiber.StatusOK, nil
+}
+
+// CreateNewSession query for creating a new session.
+func (q *SessionQueries) CreateNewSession(ctx context.Context, s *models.Session) error {
+	// Define query string.
+	query := `
+	INSERT INTO sessions (` + sessionColumns + `)
+	VALUES (
+		$1::uuid, $2::timestamp, $3::uuid,
+		$4::varchar, $5::timestamp, $6::varchar,
+		$7::varchar
+	)
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx,
+		query,
+		s.ID, s.CreatedAt, s.UserID,
+		s.RefreshTokenHash, s.ExpireAt, s.IPAddress,
+		s.UserAgent,
+	)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteSession query for deleting session by given ID.
+func (q *SessionQueries) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	DELETE FROM sessions
+	WHERE id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteSessionsByUserID query for deleting (revoking) all sessions by given user ID.
+func (q *SessionQueries) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) error {
+	// Define query string.
+	query := `
+	DELETE FROM sessions
+	WHERE user_id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, userID)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteExpiredSessions query for deleting up to the given count of sessions, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (q *SessionQueries) DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	// Define query string.
+	query := `
+	DELETE FROM sessions
+	WHERE id IN (
+		SELECT id FROM sessions
+		WHERE expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, expiredBefore, limit)
+	if err != nil {
+		// Return only error.
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,136 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// userExportColumns is a list of the user_exports table columns, scanned into models.UserExportArchive.
+const userExportColumns = `
+		id, created_at, user_id,
+		expire_at, archive`
+
+// UserExportQueries struct for queries from UserExportArchive model.
+type UserExportQueries struct {
+	Executor
+}
+
+// GetUserExportArchive query for getting built archive of the user export by given ID.
+func (q *UserExportQueries) GetUserExportArchive(ctx context.Context, id uuid.UUID) (models.UserExportArchive, int, error) {
+	// Define UserExportArchive variable.
+	userExport := models.UserExportArchive{}
+
+	// Define query string.
+	query := `
+	SELECT` + userExportColumns + `
+	FROM
+		user_exports
+	WHERE
+		id = $1::uuid AND archive IS NOT NULL
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &userExport, query, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return userExport, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return userExport, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return userExport, fiber.StatusBadRequest, err
+	}
+}
+
+// CreateNewUserExport query for creating a new pending export of the user.
+// Returns false, if the previous export of the user is still pending (only one at the same time).
+func (q *UserExportQueries) CreateNewUserExport(ctx context.Context, e *models.UserExportArchive) (bool, error) {
+	// Define query string.
+	query := `
+	INSERT INTO user_exports (id, user_id, expire_at)
+	VALUES ($1::uuid, $2::uuid, $3::timestamp)
+	ON CONFLICT (user_id) WHERE archive IS NULL DO NOTHING
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, e.ID, e.UserID, e.ExpireAt)
+	if err != nil {
+		// Return only error.
+		return false, err
+	}
+
+	// Checking, if export was created.
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// SetUserExportArchive query for saving built archive of the pending export (export is not pending after it).
+func (q *UserExportQueries) SetUserExportArchive(ctx context.Context, id uuid.UUID, archive []byte, expireAt time.Time) error {
+	// Define query string.
+	query := `
+	UPDATE user_exports
+	SET archive = $2::bytea, expire_at = $3::timestamp
+	WHERE id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, archive, expireAt)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteUserExport query for deleting export by given ID (so the failed export is not pending anymore).
+func (q *UserExportQueries) DeleteUserExport(ctx context.Context, id uuid.UUID) error {
+	// Send query to database.
+	_, err := q.ExecContext(ctx, `DELETE FROM user_exports WHERE id = $1::uuid`, id)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteExpiredUserExports query for deleting up to the given count of user exports (archives and exports
+// of the crashed app instances), which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (q *UserExportQueries) DeleteExpiredUserExports(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	// Define query string.
+	query := `
+	DELETE FROM user_exports
+	WHERE id IN (
+		SELECT id FROM user_exports
+		WHERE expire_at < $1::timestamp
+		LIMIT $2::int
+	)
+	`
+
+	// Send query to database.
+	result, err := q.ExecContext(ctx, query, expiredBefore, limit)
+	if err != nil {
+		// Return only error.
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
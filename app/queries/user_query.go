@@ -2,27 +2,49 @@ package queries
 
 import (
 	"Komentory/auth/app/models"
+	"context"
 	"database/sql"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
-	"github.com/jmoiron/sqlx"
 )
 
+// userColumns is a list of the users table columns, scanned into models.User.
+const userColumns = `
+		id, created_at, updated_at,
+		email, password_hash, user_status,
+		user_role, user_attrs, user_settings,
+		password_change_required, deletion_requested_at,
+		username, username_changed_at`
+
+// usernameAvailabilityQuery is a query string for checking, if username is not taken
+// by another user ($2), including old usernames of other users.
+const usernameAvailabilityQuery = `
+	SELECT
+		NOT EXISTS (
+			SELECT 1 FROM users WHERE lower(username) = lower($1::varchar) AND id <> $2::uuid
+		)
+		AND NOT EXISTS (
+			SELECT 1 FROM username_redirects WHERE username = lower($1::varchar) AND user_id <> $2::uuid
+		)
+	`
+
 // UserQueries struct for queries from User model.
 type UserQueries struct {
-	*sqlx.DB
+	Executor
 }
 
 // GetUserByID query for getting one User by given ID.
-func (q *UserQueries) GetUserByID(id uuid.UUID) (models.User, int, error) {
+func (q *UserQueries) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
 	// Define User variable.
 	user := models.User{}
 
 	// Define query string.
 	query := `
-	SELECT *
+	SELECT` + userColumns + `
 	FROM
 		users
 	WHERE
@@ -31,7 +53,7 @@ func (q *UserQueries) GetUserByID(id uuid.UUID) (models.User, int, error) {
 	`
 
 	// Send query to database.
-	err := q.Get(&user, query, id)
+	err := q.GetContext(ctx, &user, query, id)
 
 	// Get query result.
 	switch err {
@@ -48,13 +70,13 @@ func (q *UserQueries) GetUserByID(id uuid.UUID) (models.User, int, error) {
 }
 
 // GetUserByEmail query for getting one User by given Email.
-func (q *UserQueries) GetUserByEmail(email string) (models.User, int, error) {
+func (q *UserQueries) GetUserByEmail(ctx context.Context, email string) (models.User, int, error) {
 	// Define User variable.
 	user := models.User{}
 
 	// Define query string.
 	query := `
-	SELECT *
+	SELECT` + userColumns + `
 	FROM
 		users
 	WHERE
@@ -63,7 +85,101 @@ func (q *UserQueries) GetUserByEmail(email string) (models.User, int, error) {
 	`
 
 	// Send query to database.
-	err := q.Get(&user, query, email)
+	err := q.GetContext(ctx, &user, query, email)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return user, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return user, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return user, fiber.StatusBadRequest, err
+	}
+}
+
+// GetUsers query for getting users by given filter (with pagination).
+func (q *UserQueries) GetUsers(ctx context.Context, f *models.UsersFilter) ([]models.User, int, error) {
+	// Define User variable.
+	users := []models.User{}
+
+	// Define query string.
+	query := `
+	SELECT` + userColumns + `
+	FROM
+		users
+	WHERE
+		($1::int IS NULL OR user_status = $1::int)
+		AND ($2::int IS NULL OR user_role = $2::int)
+		AND ($3::varchar = '' OR email ILIKE '%' || $3::varchar || '%')
+	ORDER BY
+		created_at DESC
+	LIMIT $4::int
+	OFFSET $5::int
+	`
+
+	// Send query to database.
+	err := q.SelectContext(ctx,
+		&users, query,
+		f.Status, f.Role, escapeLikePattern(f.Search),
+		f.Limit, (f.Page-1)*f.Limit,
+	)
+	if err != nil {
+		// Return empty object and 400 error.
+		return users, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return users, fiber.StatusOK, nil
+}
+
+// GetUsersCount query for getting count of users by given filter (without pagination).
+func (q *UserQueries) GetUsersCount(ctx context.Context, f *models.UsersFilter) (int, int, error) {
+	// Define count variable.
+	count := 0
+
+	// Define query string.
+	query := `
+	SELECT COUNT(*)
+	FROM
+		users
+	WHERE
+		($1::int IS NULL OR user_status = $1::int)
+		AND ($2::int IS NULL OR user_role = $2::int)
+		AND ($3::varchar = '' OR email ILIKE '%' || $3::varchar || '%')
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &count, query, f.Status, f.Role, escapeLikePattern(f.Search))
+	if err != nil {
+		// Return zero count and 400 error.
+		return count, fiber.StatusBadRequest, err
+	}
+
+	// Return count and 200 OK.
+	return count, fiber.StatusOK, nil
+}
+
+// GetUserByUsername query for getting one User by given username (case-insensitive).
+func (q *UserQueries) GetUserByUsername(ctx context.Context, username string) (models.User, int, error) {
+	// Define User variable.
+	user := models.User{}
+
+	// Define query string.
+	query := `
+	SELECT` + userColumns + `
+	FROM
+		users
+	WHERE
+		lower(username) = lower($1::varchar)
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &user, query, username)
 
 	// Get query result.
 	switch err {
@@ -79,11 +195,109 @@ func (q *UserQueries) GetUserByEmail(email string) (models.User, int, error) {
 	}
 }
 
+// GetUserPasswordHash query for getting password hash of the user by given ID
+// (to compare passwords with the actual hash, cached users have no hash).
+func (q *UserQueries) GetUserPasswordHash(ctx context.Context, id uuid.UUID) (string, int, error) {
+	// Define password hash variable.
+	passwordHash := ""
+
+	// Send query to database.
+	err := q.GetContext(ctx, &passwordHash, `SELECT password_hash FROM users WHERE id = $1::uuid LIMIT 1`, id)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return hash and 200 OK.
+		return passwordHash, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty hash and 404 error.
+		return passwordHash, fiber.StatusNotFound, err
+	default:
+		// Return empty hash and 400 error.
+		return passwordHash, fiber.StatusBadRequest, err
+	}
+}
+
+// IsUsernameAvailable query for checking, if username is not taken (case-insensitive)
+// by another user, including old usernames of other users (uuid.Nil means anyone).
+func (q *UserQueries) IsUsernameAvailable(ctx context.Context, username string, userID uuid.UUID) (bool, error) {
+	// Define availability variable.
+	isAvailable := false
+
+	// Send query to database.
+	if err := q.GetContext(ctx, &isAvailable, usernameAvailabilityQuery, username, userID); err != nil {
+		return false, err
+	}
+
+	return isAvailable, nil
+}
+
+// LockUsername query for locking the username for other transactions until the end of the current one
+// (so availability of the username can be checked before its change). It must be run in transaction.
+func (q *UserQueries) LockUsername(ctx context.Context, username string) error {
+	// Send query to database.
+	_, err := q.ExecContext(ctx, `SELECT pg_advisory_xact_lock(hashtext(lower($1::varchar)))`, username)
+
+	return err
+}
+
+// UpdateUserUsername query for changing username of the user (old username is kept as redirect to the user),
+// only if it was not changed after the given time (the first username can be set at any time).
+// It must be run in transaction after LockUsername and IsUsernameAvailable.
+func (q *UserQueries) UpdateUserUsername(ctx context.Context, id uuid.UUID, username string, changedBefore time.Time) (int, error) {
+	// Keep the old username (if it's set) as redirect to the user.
+	if _, err := q.ExecContext(ctx, `
+	INSERT INTO username_redirects (username, user_id)
+	SELECT lower(username), id FROM users
+	WHERE id = $1::uuid AND username IS NOT NULL AND lower(username) <> lower($2::varchar)
+	ON CONFLICT (username) DO NOTHING
+	`, id, username); err != nil {
+		return fiber.StatusBadRequest, err
+	}
+
+	// Delete redirect of the new username, if user takes back own old username.
+	if _, err := q.ExecContext(ctx,
+		`DELETE FROM username_redirects WHERE username = lower($1::varchar) AND user_id = $2::uuid`, username, id,
+	); err != nil {
+		return fiber.StatusBadRequest, err
+	}
+
+	// Update username, if cooldown after the last change is over.
+	result, err := q.ExecContext(ctx, `
+	UPDATE
+		users
+	SET
+		updated_at = NOW (),
+		username = $2::varchar,
+		username_changed_at = NOW ()
+	WHERE
+		id = $1::uuid AND (username_changed_at IS NULL OR username_changed_at <= $3::timestamp)
+	`, id, username, changedBefore)
+	if err != nil {
+		return fiber.StatusBadRequest, err
+	}
+
+	// Checking, if username was updated.
+	count, err := result.RowsAffected()
+	if err != nil {
+		return fiber.StatusBadRequest, err
+	}
+	if count == 0 {
+		return fiber.StatusForbidden, fmt.Errorf("username was changed recently")
+	}
+
+	return fiber.StatusOK, nil
+}
+
 // CreateNewUser query for creating a new user by given email and password hash.
-func (q *UserQueries) CreateNewUser(u *models.User) error {
+func (q *UserQueries) CreateNewUser(ctx context.Context, u *models.User) error {
 	// Define query string.
 	query := `
-	INSERT INTO users
+	INSERT INTO users (
+		id, created_at, updated_at,
+		email, password_hash, user_status,
+		user_role, user_attrs, user_settings
+	)
 	VALUES (
 		$1::uuid, $2::timestamp, $3::timestamp, 
 		$4::varchar, $5::varchar, $6::int, 
@@ -92,7 +306,7 @@ func (q *UserQueries) CreateNewUser(u *models.User) error {
 	`
 
 	// Send query to database.
-	_, err := q.Exec(
+	_, err := q.ExecContext(ctx,
 		query,
 		u.ID, u.CreatedAt, u.UpdatedAt,
 		u.Email, u.PasswordHash, u.UserStatus,
@@ -108,7 +322,7 @@ func (q *UserQueries) CreateNewUser(u *models.User) error {
 }
 
 // UpdateUserAttrs query for updating user attrs by given user ID.
-func (q *UserQueries) UpdateUserAttrs(id uuid.UUID, u *models.UserAttrs) error {
+func (q *UserQueries) UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error {
 	// Define query string.
 	query := `
 	UPDATE
@@ -121,7 +335,7 @@ func (q *UserQueries) UpdateUserAttrs(id uuid.UUID, u *models.UserAttrs) error {
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, id, time.Now(), u)
+	_, err := q.ExecContext(ctx, query, id, time.Now(), u)
 	if err != nil {
 		// Return only error.
 		return err
@@ -132,7 +346,7 @@ func (q *UserQueries) UpdateUserAttrs(id uuid.UUID, u *models.UserAttrs) error {
 }
 
 // UpdateUserSettings query for updating user settings by given user ID.
-func (q *UserQueries) UpdateUserSettings(id uuid.UUID, u *models.UserSettings) error {
+func (q *UserQueries) UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error {
 	// Define query string.
 	query := `
 	UPDATE
@@ -145,7 +359,7 @@ func (q *UserQueries) UpdateUserSettings(id uuid.UUID, u *models.UserSettings) e
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, id, time.Now(), u)
+	_, err := q.ExecContext(ctx, query, id, time.Now(), u)
 	if err != nil {
 		// Return only error.
 		return err
@@ -156,20 +370,149 @@ func (q *UserQueries) UpdateUserSettings(id uuid.UUID, u *models.UserSettings) e
 }
 
 // UpdateUserPassword query for updating user password by given user ID.
-func (q *UserQueries) UpdateUserPassword(id uuid.UUID, password_hash string) error {
+func (q *UserQueries) UpdateUserPassword(ctx context.Context, id uuid.UUID, password_hash string) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		updated_at = $2::timestamp,
+		password_hash = $3::varchar,
+		password_change_required = FALSE
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now(), password_hash)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// RequestUserDeletion query for marking user account as pending deletion.
+func (q *UserQueries) RequestUserDeletion(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		updated_at = $2::timestamp,
+		deletion_requested_at = $2::timestamp
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// CancelUserDeletion query for cancelling deletion of the user account.
+func (q *UserQueries) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		updated_at = $2::timestamp,
+		deletion_requested_at = NULL
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// DeleteUsersPendingDeletion query for deleting up to the given count of user accounts, which deletion
+// was requested before the given time. Activation codes, sessions and other user data are deleted by cascade,
+// reset codes are deleted by user email (by the same statement). It returns IDs of the deleted users.
+func (q *UserQueries) DeleteUsersPendingDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	// Define IDs of the deleted users.
+	ids := []uuid.UUID{}
+
+	// Define query string.
+	query := `
+	WITH deleted_users AS (
+		DELETE FROM users
+		WHERE id IN (
+			SELECT id FROM users
+			WHERE deletion_requested_at < $1::timestamp
+			LIMIT $2::int
+		)
+		RETURNING id, email
+	), deleted_reset_codes AS (
+		DELETE FROM reset_codes
+		WHERE email IN (SELECT email FROM deleted_users)
+	)
+	SELECT id FROM deleted_users
+	`
+
+	// Send query to database.
+	if err := q.SelectContext(ctx, &ids, query, requestedBefore, limit); err != nil {
+		// Return empty object and error.
+		return []uuid.UUID{}, err
+	}
+
+	return ids, nil
+}
+
+// UpdateUserPasswordHash query for replacing hash of the same user password (after upgrade of the algorithm).
+func (q *UserQueries) UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, password_hash string) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		password_hash = $2::varchar
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, password_hash)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// UpdateUserPasswordChangeRequired query for marking (or unmarking) user password as required to change.
+func (q *UserQueries) UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error {
 	// Define query string.
 	query := `
 	UPDATE
 		users
 	SET
 		updated_at = $2::timestamp,
-		password_hash = $3::varchar
+		password_change_required = $3::boolean
 	WHERE
 		id = $1::uuid
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, id, time.Now(), password_hash)
+	_, err := q.ExecContext(ctx, query, id, time.Now(), required)
 	if err != nil {
 		// Return only error.
 		return err
@@ -180,7 +523,32 @@ func (q *UserQueries) UpdateUserPassword(id uuid.UUID, password_hash string) err
 }
 
 // UpdateUserStatus query for updating user status by given user ID.
-func (q *UserQueries) UpdateUserStatus(id uuid.UUID) error {
+func (q *UserQueries) UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		updated_at = $2::timestamp,
+		user_status = $3::int
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now(), status)
+	if err != nil {
+		// Return only error.
+		return err
+	}
+
+	// This query returns nothing.
+	return nil
+}
+
+// ActivateUser query for activating user by given user ID, only if user is not confirmed yet
+// (status 0). It returns false, if user was already activated or blocked.
+func (q *UserQueries) ActivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
 	// Define query string.
 	query := `
 	UPDATE
@@ -190,10 +558,40 @@ func (q *UserQueries) UpdateUserStatus(id uuid.UUID) error {
 		user_status = 1
 	WHERE
 		id = $1::uuid
+		AND user_status = 0
 	`
 
 	// Send query to database.
-	_, err := q.Exec(query, id, time.Now())
+	result, err := q.ExecContext(ctx, query, id, time.Now())
+	if err != nil {
+		// Return only error.
+		return false, err
+	}
+
+	// Checking, if user was activated.
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateUserRole query for updating user role by given user ID.
+func (q *UserQueries) UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error {
+	// Define query string.
+	query := `
+	UPDATE
+		users
+	SET
+		updated_at = $2::timestamp,
+		user_role = $3::int
+	WHERE
+		id = $1::uuid
+	`
+
+	// Send query to database.
+	_, err := q.ExecContext(ctx, query, id, time.Now(), role)
 	if err != nil {
 		// Return only error.
 		return err
@@ -202,3 +600,8 @@ func (q *UserQueries) UpdateUserStatus(id uuid.UUID) error {
 	// This query returns nothing.
 	return nil
 }
+
+// escapeLikePattern func for escaping special characters of the LIKE pattern.
+func escapeLikePattern(pattern string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(pattern)
+}
@@ -0,0 +1,50 @@
+package queries
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// usernameRedirectColumns is a list of the username_redirects table columns, scanned into models.UsernameRedirect.
+const usernameRedirectColumns = `
+		username, created_at, user_id`
+
+// UsernameRedirectQueries struct for queries from UsernameRedirect model.
+type UsernameRedirectQueries struct {
+	Executor
+}
+
+// GetUsernameRedirect query for getting redirect by given old username (case-insensitive).
+func (q *UsernameRedirectQueries) GetUsernameRedirect(ctx context.Context, username string) (models.UsernameRedirect, int, error) {
+	// Define UsernameRedirect variable.
+	usernameRedirect := models.UsernameRedirect{}
+
+	// Define query string.
+	query := `
+	SELECT` + usernameRedirectColumns + `
+	FROM
+		username_redirects
+	WHERE
+		username = lower($1::varchar)
+	LIMIT 1
+	`
+
+	// Send query to database.
+	err := q.GetContext(ctx, &usernameRedirect, query, username)
+
+	// Get query result.
+	switch err {
+	case nil:
+		// Return object and 200 OK.
+		return usernameRedirect, fiber.StatusOK, nil
+	case sql.ErrNoRows:
+		// Return empty object and 404 error.
+		return usernameRedirect, fiber.StatusNotFound, err
+	default:
+		// Return empty object and 400 error.
+		return usernameRedirect, fiber.StatusBadRequest, err
+	}
+}
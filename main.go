@@ -1,10 +1,22 @@
 package main
 
 import (
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/pkg/audit"
 	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/pkg/jobs"
+	"Komentory/auth/pkg/mailer"
 	"Komentory/auth/pkg/middleware"
 	"Komentory/auth/pkg/routes"
+	"Komentory/auth/platform/database"
+	"Komentory/auth/platform/migrations"
+	"context"
+	"log"
 	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 
 	"github.com/Komentory/utilities"
 	"github.com/gofiber/fiber/v2"
@@ -13,6 +25,77 @@ import (
 )
 
 func main() {
+	// Define context, which is done on SIGINT or SIGTERM (stops server and background jobs).
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Open database of the selected driver (once, shared by all requests).
+	db, storage, err := database.Open(ctx)
+	if err != nil {
+		log.Fatalf("Oops... Database is not connected! Reason: %v", err)
+	}
+	defer storage.Close() // closed after the end of background jobs
+
+	// Run migrate command instead of server, if it's given (auth migrate up|down [N]|status).
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(storage, os.Args[2:]); err != nil {
+			log.Fatalf("Oops... Migrate command is failed! Reason: %v", err)
+		}
+		return
+	}
+
+	// Apply embedded migrations on start, if DB_AUTO_MIGRATE is true.
+	if configs.AutoMigrate() {
+		if err := migrate(storage, []string{"up"}); err != nil {
+			log.Fatalf("Oops... Migrations are not applied! Reason: %v", err)
+		}
+	}
+
+	// Set sender of the emails (refuse to start without email service).
+	if err := mailer.Init(); err != nil {
+		log.Fatalf("Oops... Mailer is not configured! Reason: %v", err)
+	}
+
+	// Open the local file of the breached passwords (refuse to start with wrong file).
+	if err := helpers.InitBreachChecker(); err != nil {
+		log.Fatalf("Oops... Breached passwords file is not opened! Reason: %v", err)
+	}
+
+	// Create brute-force protection for the login attempts.
+	if err := helpers.InitLoginGuard(); err != nil {
+		log.Fatalf("Oops... Login limiter is not configured! Reason: %v", err)
+	}
+
+	// Set database for app-wide helpers.
+	helpers.SetCredentialsRepository(storage) // Load roles credentials from database.
+
+	// PostgreSQL is required for all features, SQLite only for users, codes and sessions.
+	isPostgreSQL := configs.DatabaseDriver() == "postgres"
+	if isPostgreSQL {
+		// Check, if database schema matches columns of the queries (refuse to start otherwise).
+		if err := db.CheckSchema(context.Background()); err != nil {
+			log.Fatalf("Oops... Database schema is drifted! Reason: %v", err)
+		}
+
+		// Check, if download links of the user exports are signed by their own key.
+		if key := configs.UserExportLinkSecretKey(); key == "" || key == os.Getenv("JWT_SECRET_KEY") {
+			log.Fatalf("Oops... User export link key is not set or equals to JWT secret key!")
+		}
+
+		audit.SetRecorder(audit.NewDatabaseRecorder(db)) // Store audit events in database.
+	} else {
+		audit.SetRecorder(&audit.LogRecorder{}) // Write audit events to the app log.
+	}
+
+	// Define storage with cache of the users (by USER_CACHE_STORE).
+	repository, err := database.WithUserCache(storage)
+	if err != nil {
+		log.Fatalf("Oops... Users cache is not configured! Reason: %v", err)
+	}
+
+	// Define handler with shared dependencies for controllers.
+	handler := controllers.NewHandlerWithRepository(db, repository)
+
 	// Define Fiber config.
 	config := configs.FiberConfig()
 
@@ -23,14 +106,62 @@ func main() {
 	middleware.FiberMiddleware(app) // Register Fiber's middleware for app.
 
 	// Routes.
-	routes.PublicRoutes(app)  // Register a public routes for app.
-	routes.PrivateRoutes(app) // Register a private routes for app.
-	routes.NotFoundRoute(app) // Register route for 404 Error.
+	routes.PublicRoutes(app, handler)  // Register a public routes for app.
+	routes.PrivateRoutes(app, handler) // Register a private routes for app.
+	routes.AdminRoutes(app, handler)   // Register an admin routes for app.
+	routes.NotFoundRoute(app)          // Register route for 404 Error.
 
-	// Start server (with or without graceful shutdown).
-	if os.Getenv("STAGE_STATUS") == "dev" {
-		utilities.StartServer(app)
-	} else {
-		utilities.StartServerWithGracefulShutdown(app)
+	// Background jobs (stopped together with server) purge PostgreSQL database or SQLite storage.
+	var janitorStorage database.JanitorRepository = db
+	if sqlite, ok := storage.(*database.SQLiteRepository); ok {
+		janitorStorage = sqlite
+	}
+	var backgroundJobs sync.WaitGroup
+	for _, janitor := range jobs.NewJanitors(janitorStorage, handler.UserCache) {
+		janitor.Start(ctx, &backgroundJobs) // Start jobs for purging expired codes, sessions, tokens, accounts and exports.
+	}
+
+	// Start server with graceful shutdown.
+	startServer(ctx, app)
+
+	// Stop background jobs and wait for the running ones (before closing storage).
+	stop()
+	backgroundJobs.Wait()
+}
+
+// startServer func for starting server, which is gracefully shut down, when the given context is done
+// (active requests are finished before return).
+func startServer(ctx context.Context, app *fiber.App) {
+	// Shut down server, when context is done.
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+
+		if err := app.Shutdown(); err != nil {
+			log.Printf("Oops... Server is not shutting down! Reason: %v", err)
+		}
+	}()
+
+	// Build Fiber connection URL.
+	fiberConnURL, _ := utilities.ConnectionURLBuilder("fiber")
+
+	// Run server (it's stopped by shutdown or error).
+	if err := app.Listen(fiberConnURL); err != nil {
+		log.Printf("Oops... Server is not running! Reason: %v", err)
+		return
 	}
+
+	<-shutdownDone
+}
+
+// migrate func for running migrate command with the embedded migrations of the database driver.
+func migrate(storage database.Storage, args []string) error {
+	// Create migrator with the shared connection pool.
+	migrator, err := storage.Migrator()
+	if err != nil {
+		return err
+	}
+
+	return migrations.RunCommand(context.Background(), migrator, args, os.Stdout)
 }
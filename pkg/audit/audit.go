@@ -0,0 +1,192 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// Outcomes of the audit events.
+const (
+	OutcomeSuccess string = "success"
+	OutcomeFailure string = "failure"
+)
+
+// Names of the audit events.
+const (
+	EventSignUp                 string = "sign_up"
+	EventActivation             string = "activation"
+	EventLogin                  string = "login"
+	EventLoginLockout           string = "login_lockout"
+	EventLogout                 string = "logout"
+	EventTokenRenewal           string = "token_renewal"
+	EventPasswordChange         string = "password_change"
+	EventPasswordBreached       string = "password_breached"
+	EventResetRequest           string = "reset_request"
+	EventResetApply             string = "reset_apply"
+	EventEmailChangeRequest     string = "email_change_request"
+	EventEmailChangeConfirm     string = "email_change_confirm"
+	EventEmailChangeRevert      string = "email_change_revert"
+	EventAccountDeletionRequest string = "account_deletion_request"
+	EventAccountDeletionCancel  string = "account_deletion_cancel"
+	EventAccountDeletion        string = "account_deletion"
+	EventUserExportRequest      string = "user_export_request"
+	EventUserExportReady        string = "user_export_ready"
+	EventUserExportDownload     string = "user_export_download"
+	EventUsernameChange         string = "username_change"
+	EventAttrsChange            string = "attrs_change"
+	EventSettingsChange         string = "settings_change"
+	EventPersonalTokenCreate    string = "personal_token_create"
+	EventPersonalTokenRevoke    string = "personal_token_revoke"
+	EventClientTokenIssue       string = "client_token_issue"
+	EventUserStatusChange       string = "admin_user_status_change"
+	EventUserRoleChange         string = "admin_user_role_change"
+	EventUserPasswordReset      string = "admin_user_password_reset"
+	EventUserSessionsRevoke     string = "admin_user_sessions_revoke"
+	EventImpersonationStart     string = "admin_impersonation_start"
+	EventImpersonationEnd       string = "admin_impersonation_end"
+	EventRoleCreate             string = "admin_role_create"
+	EventRolePermissionsChange  string = "admin_role_permissions_change"
+	EventRoleDelete             string = "admin_role_delete"
+	EventPermissionCreate       string = "admin_permission_create"
+	EventPermissionDelete       string = "admin_permission_delete"
+)
+
+// Recorder interface to describe storage of the audit events.
+type Recorder interface {
+	Record(event *models.AuditEvent) error
+}
+
+// DatabaseRecorder struct to store audit events in database (append-only table).
+type DatabaseRecorder struct {
+	DB *database.Queries
+}
+
+// NewDatabaseRecorder func for create a new database recorder with the given connection pool.
+func NewDatabaseRecorder(db *database.Queries) *DatabaseRecorder {
+	return &DatabaseRecorder{DB: db}
+}
+
+// Record method to store the given audit event in database.
+func (r *DatabaseRecorder) Record(event *models.AuditEvent) error {
+	// Checking, if database is set.
+	if r.DB == nil {
+		return fmt.Errorf("database is not set")
+	}
+
+	// Audit event must be stored, even if request is cancelled (only query timeout is used).
+	return r.DB.CreateNewAuditEvent(context.Background(), event)
+}
+
+// LogRecorder struct to write audit events to the app log (for database without audit_events table, like SQLite).
+type LogRecorder struct{}
+
+// Record method to write the given audit event to the app log in JSON format.
+func (r *LogRecorder) Record(event *models.AuditEvent) error {
+	// Convert audit event to JSON.
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("Audit event: %s", data)
+
+	return nil
+}
+
+// recorder is the current storage of the audit events.
+var recorder Recorder = &DatabaseRecorder{}
+
+// SetRecorder func for replacing the default (database without connection) recorder.
+func SetRecorder(r Recorder) {
+	recorder = r
+}
+
+// Success func for recording a successful audit event.
+func Success(c *fiber.Ctx, event string, actorID, targetID uuid.UUID) {
+	record(helpers.NewAuditEvent(c, event, OutcomeSuccess, actorID, targetID))
+}
+
+// SuccessWithDetails func for recording a successful audit event with additional info.
+func SuccessWithDetails(c *fiber.Ctx, event string, actorID, targetID uuid.UUID, details models.AuditEventDetails) {
+	// Create a new audit event.
+	auditEvent := helpers.NewAuditEvent(c, event, OutcomeSuccess, actorID, targetID)
+
+	// Set additional info.
+	for key, value := range details {
+		auditEvent.Details[key] = value
+	}
+
+	record(auditEvent)
+}
+
+// Failure func for recording a failed audit event with the given reason.
+func Failure(c *fiber.Ctx, event string, actorID, targetID uuid.UUID, reason string) {
+	// Create a new audit event.
+	auditEvent := helpers.NewAuditEvent(c, event, OutcomeFailure, actorID, targetID)
+
+	// Set reason of the failure.
+	auditEvent.Details["reason"] = reason
+
+	record(auditEvent)
+}
+
+// FailureWithDetails func for recording a failed audit event with the given reason and additional info.
+func FailureWithDetails(c *fiber.Ctx, event string, actorID, targetID uuid.UUID, reason string, details models.AuditEventDetails) {
+	// Create a new audit event.
+	auditEvent := helpers.NewAuditEvent(c, event, OutcomeFailure, actorID, targetID)
+
+	// Set reason of the failure and additional info.
+	for key, value := range details {
+		auditEvent.Details[key] = value
+	}
+	auditEvent.Details["reason"] = reason
+
+	record(auditEvent)
+}
+
+// System func for recording a successful audit event, which was made by the app itself (by background job).
+func System(event string, targetID uuid.UUID) {
+	// Create a new audit event without client info.
+	auditEvent := &models.AuditEvent{
+		CreatedAt: time.Now(),
+		Event:     event,
+		Outcome:   OutcomeSuccess,
+		TargetID:  &targetID,
+		Details:   models.AuditEventDetails{},
+	}
+
+	record(auditEvent)
+}
+
+// SystemWithDetails func for recording a successful audit event, which was made by the app itself
+// on behalf of the given actor (for example, end of the expired impersonation).
+func SystemWithDetails(event string, actorID, targetID uuid.UUID, details models.AuditEventDetails) {
+	// Create a new audit event without client info.
+	auditEvent := &models.AuditEvent{
+		CreatedAt: time.Now(),
+		Event:     event,
+		Outcome:   OutcomeSuccess,
+		ActorID:   &actorID,
+		TargetID:  &targetID,
+		Details:   details,
+	}
+
+	record(auditEvent)
+}
+
+func record(auditEvent *models.AuditEvent) {
+	// Audit log must not break the request, so errors are only logged.
+	if err := recorder.Record(auditEvent); err != nil {
+		log.Printf("Oops... Audit event %q is not recorded! Reason: %v", auditEvent.Event, err)
+	}
+}
@@ -0,0 +1,19 @@
+package breach
+
+import (
+	"crypto/sha1" // SHA-1 is the format of the Pwned Passwords dump, not a password hash
+	"encoding/hex"
+	"strings"
+)
+
+// Checker interface to describe service for checking passwords in data breaches.
+type Checker interface {
+	// Check method to get count of the given password appearances in data breaches (0, if never).
+	Check(password string) (int, error)
+}
+
+// hashPassword func for making SHA-1 hash of the password in upper case (as in Pwned Passwords).
+func hashPassword(password string) string {
+	hash := sha1.Sum([]byte(password))
+	return strings.ToUpper(hex.EncodeToString(hash[:]))
+}
@@ -0,0 +1,124 @@
+package breach
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// maxLineLength is enough for "<SHA-1 hash>:<count>\r\n" line.
+const maxLineLength int64 = 128
+
+// FileChecker struct to describe checker over the local Pwned Passwords dump file
+// (SHA-1 hashes in upper case, ordered by hash, one "<hash>:<count>" per line).
+// See: https://haveibeenpwned.com/Passwords
+type FileChecker struct {
+	file *os.File
+	size int64
+}
+
+// NewFileChecker func for create a new checker over the given file.
+func NewFileChecker(path string) (*FileChecker, error) {
+	// Open file for reading.
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get size of the file.
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return &FileChecker{file: file, size: info.Size()}, nil
+}
+
+// Check method to get count of the given password appearances in data breaches (0, if never).
+// File is never loaded to memory, binary search reads only ~log2(lines) short chunks.
+func (c *FileChecker) Check(password string) (int, error) {
+	// Define hash to search.
+	hash := hashPassword(password)
+
+	// Binary search by offsets of the lines in file.
+	low, high := int64(0), c.size
+	for low < high {
+		middle := low + (high-low)/2
+
+		// Get the first line, which starts at or after the middle.
+		lineStart, line, err := c.lineAt(middle)
+		if err != nil {
+			return 0, err
+		}
+
+		// No lines start in [middle, high), so search in the left half.
+		if lineStart >= high || len(line) < len(hash) {
+			high = middle
+			continue
+		}
+
+		// Compare hash from the line with the given hash.
+		switch strings.Compare(line[:len(hash)], hash) {
+		case 0:
+			// Return count of the appearances (at least 1, if count is not valid).
+			count, err := strconv.Atoi(strings.TrimPrefix(line[len(hash):], ":"))
+			if err != nil || count < 1 {
+				return 1, nil
+			}
+			return count, nil
+		case -1:
+			low = lineStart + int64(len(line)) + 1
+		default:
+			high = middle
+		}
+	}
+
+	return 0, nil
+}
+
+// Close method to close the file.
+func (c *FileChecker) Close() error {
+	return c.file.Close()
+}
+
+func (c *FileChecker) lineAt(offset int64) (int64, string, error) {
+	// Find start of the line: the first position after "\n", which is at or after offset-1.
+	lineStart := offset
+	if offset > 0 {
+		chunk, err := c.readChunk(offset - 1)
+		if err != nil {
+			return 0, "", err
+		}
+
+		index := bytes.IndexByte(chunk, '\n')
+		if index < 0 {
+			return c.size, "", nil
+		}
+		lineStart = offset + int64(index)
+	}
+
+	// Read the line.
+	chunk, err := c.readChunk(lineStart)
+	if err != nil {
+		return 0, "", err
+	}
+	if index := bytes.IndexByte(chunk, '\n'); index >= 0 {
+		chunk = chunk[:index]
+	}
+
+	return lineStart, strings.TrimSuffix(string(chunk), "\r"), nil
+}
+
+func (c *FileChecker) readChunk(offset int64) ([]byte, error) {
+	// Read chunk from the offset (may be shorter at the end of file).
+	chunk := make([]byte, maxLineLength)
+	n, err := c.file.ReadAt(chunk, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	return chunk[:n], nil
+}
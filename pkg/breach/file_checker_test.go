@@ -0,0 +1,85 @@
+package breach
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeFixture func for writing a small sorted dump with the given passwords (count is index + 1)
+// and line ending. It returns hashes of the passwords in the file order.
+func writeFixture(t *testing.T, passwords []string, lineEnding string) (string, []string) {
+	// Define lines of the dump.
+	lines := make([]string, 0, len(passwords))
+	for i, password := range passwords {
+		lines = append(lines, fmt.Sprintf("%s:%d", hashPassword(password), i+1))
+	}
+	sort.Strings(lines)
+
+	// Write dump to the temporary file.
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, lineEnding)+lineEnding), 0o600))
+
+	// Define hashes in the file order.
+	hashes := make([]string, 0, len(lines))
+	for _, line := range lines {
+		hashes = append(hashes, line[:40])
+	}
+
+	return path, hashes
+}
+
+func TestFileChecker(t *testing.T) {
+	// Define passwords of the fixture.
+	passwords := make([]string, 0, 20)
+	for i := 0; i < 20; i++ {
+		passwords = append(passwords, fmt.Sprintf("password%d", i))
+	}
+
+	// Find password and its count by hash to check the first and the last lines.
+	byHash, counts := make(map[string]string, len(passwords)), make(map[string]int, len(passwords))
+	for i, password := range passwords {
+		byHash[hashPassword(password)] = password
+		counts[password] = i + 1
+	}
+
+	for _, lineEnding := range []string{"\n", "\r\n"} {
+		path, hashes := writeFixture(t, passwords, lineEnding)
+		checker, err := NewFileChecker(path)
+		require.NoError(t, err)
+
+		// Define a structure for specifying input and output data of a single test case.
+		tests := []struct {
+			description   string
+			password      string
+			expectedCount int
+		}{
+			{"find password in the middle", "password7", 8},
+			{"find password in the first line", byHash[hashes[0]], counts[byHash[hashes[0]]]},
+			{"find password in the last line", byHash[hashes[len(hashes)-1]], counts[byHash[hashes[len(hashes)-1]]]},
+			{"not find unknown password", "correct horse battery staple", 0},
+			{"not find empty password", "", 0},
+		}
+
+		for _, test := range tests {
+			count, err := checker.Check(test.password)
+			require.NoError(t, err, test.description)
+			assert.Equal(t, test.expectedCount, count, "need to %s (line ending %q)", test.description, lineEnding)
+		}
+
+		// Check each password of the fixture.
+		for i, password := range passwords {
+			count, err := checker.Check(password)
+			require.NoError(t, err)
+			assert.Equal(t, i+1, count, "need to find %q (line ending %q)", password, lineEnding)
+		}
+
+		require.NoError(t, checker.Close())
+	}
+}
@@ -0,0 +1,36 @@
+package configs
+
+import (
+	"os"
+	"time"
+)
+
+// PublicProfileMaxAge func for getting time (in seconds), while public user profile can be cached by clients.
+func PublicProfileMaxAge() int {
+	return getIntEnv("PUBLIC_PROFILE_MAX_AGE_SECONDS", 300)
+}
+
+// UserCacheStore func for getting store of the users cache by ID ("memory" by default,
+// "redis" for many instances of the app, or "none" to disable cache).
+func UserCacheStore() string {
+	if store := os.Getenv("USER_CACHE_STORE"); store != "" {
+		return store
+	}
+
+	return "memory"
+}
+
+// UserCacheTTL func for getting time to live of the cached user.
+func UserCacheTTL() time.Duration {
+	return time.Duration(getIntEnv("USER_CACHE_TTL_SECONDS", 60)) * time.Second
+}
+
+// UserCacheNotFoundTTL func for getting time to live of the cached not found user.
+func UserCacheNotFoundTTL() time.Duration {
+	return time.Duration(getIntEnv("USER_CACHE_NOT_FOUND_TTL_SECONDS", 10)) * time.Second
+}
+
+// UserCacheSize func for getting max count of the users in the memory cache.
+func UserCacheSize() int {
+	return getIntEnv("USER_CACHE_SIZE", 10000)
+}
@@ -0,0 +1,71 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DatabaseDriver func for getting name of the database driver: "postgres" (by default) or "sqlite".
+func DatabaseDriver() string {
+	if driver := os.Getenv("DB_DRIVER"); driver != "" {
+		return driver
+	}
+
+	return "postgres"
+}
+
+// QueryTimeout func for getting max duration of one database query (0 means no limit).
+func QueryTimeout() time.Duration {
+	return time.Duration(getIntEnv("DB_QUERY_TIMEOUT_SECONDS", 5)) * time.Second
+}
+
+// AutoMigrate func for checking, if embedded migrations must be applied on the app start.
+func AutoMigrate() bool {
+	// Return false, if env is not set or not a boolean.
+	autoMigrate, err := strconv.ParseBool(os.Getenv("DB_AUTO_MIGRATE"))
+	if err != nil {
+		return false
+	}
+
+	return autoMigrate
+}
+
+// ReplicaURLs func for getting connection URLs of the read replicas from DB_REPLICA_URLS (comma-separated).
+func ReplicaURLs() []string {
+	urls := []string{}
+	for _, url := range strings.Split(os.Getenv("DB_REPLICA_URLS"), ",") {
+		if url = strings.TrimSpace(url); url != "" {
+			urls = append(urls, url)
+		}
+	}
+
+	return urls
+}
+
+// ReplicaHealthCheckInterval func for getting interval between health checks of the read replicas.
+func ReplicaHealthCheckInterval() time.Duration {
+	seconds := getIntEnv("DB_REPLICA_HEALTH_CHECK_SECONDS", 5)
+	if seconds < 1 {
+		seconds = 1
+	}
+
+	return time.Duration(seconds) * time.Second
+}
+
+// ReplicaStickyWindow func for getting time, while user is read from the primary database after own write
+// (to read your writes, before replicas catch up; 0 means no stickiness).
+func ReplicaStickyWindow() time.Duration {
+	return time.Duration(getIntEnv("DB_REPLICA_STICKY_SECONDS", 5)) * time.Second
+}
+
+// ReplicaStickyStore func for getting store of the users, which are read from the primary database
+// ("memory" by default, or "redis" for many instances of the app).
+func ReplicaStickyStore() string {
+	if store := os.Getenv("DB_REPLICA_STICKY_STORE"); store != "" {
+		return store
+	}
+
+	return "memory"
+}
@@ -0,0 +1,38 @@
+package configs
+
+import (
+	"log"
+	"os"
+
+	"Komentory/auth/pkg/hasher"
+)
+
+// PasswordHasherConfig func for configuration hashing of the user passwords.
+// New hashes are made by the algorithm from PASSWORD_HASHER ("argon2id" by default),
+// but hashes of all supported algorithms can be verified.
+func PasswordHasherConfig() *hasher.Set {
+	// Define bcrypt hasher.
+	bcryptHasher := &hasher.BcryptHasher{
+		Cost: getIntEnv("PASSWORD_BCRYPT_COST", 10),
+	}
+
+	// Define Argon2id hasher.
+	argon2idHasher := &hasher.Argon2idHasher{
+		Memory:      uint32(getIntEnv("PASSWORD_ARGON2ID_MEMORY", 64*1024)),
+		Iterations:  uint32(getIntEnv("PASSWORD_ARGON2ID_ITERATIONS", 3)),
+		Parallelism: uint8(getIntEnv("PASSWORD_ARGON2ID_PARALLELISM", 2)),
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+
+	// Return set of hashers with the current one first.
+	switch algorithm := os.Getenv("PASSWORD_HASHER"); algorithm {
+	case "bcrypt":
+		return hasher.NewSet(bcryptHasher, argon2idHasher)
+	case "", "argon2id":
+		return hasher.NewSet(argon2idHasher, bcryptHasher)
+	default:
+		log.Printf("Unknown password hasher %q, argon2id is used", algorithm)
+		return hasher.NewSet(argon2idHasher, bcryptHasher)
+	}
+}
@@ -0,0 +1,50 @@
+package configs
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// AccountDeletionGracePeriod func for getting time, after which user account is deleted on request.
+func AccountDeletionGracePeriod() time.Duration {
+	return time.Duration(getIntEnv("ACCOUNT_DELETION_GRACE_PERIOD_DAYS", 30)) * 24 * time.Hour
+}
+
+// AccountDeletionJobInterval func for getting interval of the background job for deleting user accounts.
+func AccountDeletionJobInterval() time.Duration {
+	return time.Duration(getIntEnv("ACCOUNT_DELETION_JOB_INTERVAL_MINUTES", 60)) * time.Minute
+}
+
+// UserExportLinkSecretKey func for getting secret key to sign download links of the user exports
+// (it's not the JWT secret key, so leaked export link key can't be used to sign tokens and vice versa).
+func UserExportLinkSecretKey() string {
+	return os.Getenv("USER_EXPORT_LINK_SECRET_KEY")
+}
+
+// UserExportLinkTTL func for getting lifetime of the download link (and archive itself).
+func UserExportLinkTTL() time.Duration {
+	return time.Duration(getIntEnv("USER_EXPORT_LINK_EXPIRE_HOURS", 24)) * time.Hour
+}
+
+// JanitorJobInterval func for getting interval of the background job for purging expired rows of the table
+// (JANITOR_<TABLE>_INTERVAL_MINUTES or JANITOR_INTERVAL_MINUTES for all tables).
+func JanitorJobInterval(table string) time.Duration {
+	minutes := getIntEnv("JANITOR_"+strings.ToUpper(table)+"_INTERVAL_MINUTES", getIntEnv("JANITOR_INTERVAL_MINUTES", 15))
+	if minutes < 1 {
+		minutes = 1
+	}
+
+	return time.Duration(minutes) * time.Minute
+}
+
+// JanitorBatchSize func for getting max count of the expired rows of the table, deleted by one query
+// (JANITOR_<TABLE>_BATCH_SIZE or JANITOR_BATCH_SIZE for all tables).
+func JanitorBatchSize(table string) int {
+	size := getIntEnv("JANITOR_"+strings.ToUpper(table)+"_BATCH_SIZE", getIntEnv("JANITOR_BATCH_SIZE", 1000))
+	if size < 1 {
+		size = 1
+	}
+
+	return size
+}
@@ -0,0 +1,37 @@
+package configs
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"Komentory/auth/pkg/limiter"
+)
+
+// LoginLimiterConfig func for configuration limits of the login attempts.
+func LoginLimiterConfig() limiter.LoginConfig {
+	// Define sliding window for all limits.
+	window := time.Duration(getIntEnv("LOGIN_LIMIT_WINDOW_MINUTES", 15)) * time.Minute
+
+	// Return login limiter configuration.
+	return limiter.LoginConfig{
+		ByIP:            limiter.Rule{Limit: getIntEnv("LOGIN_LIMIT_BY_IP", 100), Window: window},
+		ByEmail:         limiter.Rule{Limit: getIntEnv("LOGIN_LIMIT_BY_EMAIL", 20), Window: window},
+		ByIPAndEmail:    limiter.Rule{Limit: getIntEnv("LOGIN_LIMIT_BY_IP_AND_EMAIL", 10), Window: window},
+		DelayAfter:      getIntEnv("LOGIN_DELAY_AFTER_FAILURES", 3),
+		Delay:           time.Duration(getIntEnv("LOGIN_DELAY_SECONDS", 1)) * time.Second,
+		MaxDelay:        time.Duration(getIntEnv("LOGIN_MAX_DELAY_SECONDS", 60)) * time.Second,
+		LockoutAfter:    getIntEnv("LOGIN_LOCKOUT_AFTER_FAILURES", 10),
+		LockoutDuration: time.Duration(getIntEnv("LOGIN_LOCKOUT_MINUTES", 30)) * time.Minute,
+	}
+}
+
+func getIntEnv(key string, defaultValue int) int {
+	// Return default value, if env is not set or not a number.
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+
+	return value
+}
@@ -0,0 +1,13 @@
+package configs
+
+import "Komentory/auth/pkg/password"
+
+// PasswordPolicyConfig func for configuration rules for the user passwords.
+func PasswordPolicyConfig() *password.Policy {
+	// Return password policy configuration.
+	return &password.Policy{
+		MinLength: getIntEnv("PASSWORD_MIN_LENGTH", 10),
+		MaxLength: getIntEnv("PASSWORD_MAX_LENGTH", 255),
+		MinScore:  getIntEnv("PASSWORD_MIN_SCORE", 3),
+	}
+}
@@ -0,0 +1,21 @@
+package configs
+
+import (
+	"time"
+
+	"Komentory/auth/pkg/username"
+)
+
+// UsernamePolicyConfig func for configuration rules for the usernames.
+func UsernamePolicyConfig() *username.Policy {
+	// Return username policy configuration.
+	return &username.Policy{
+		MinLength: getIntEnv("USERNAME_MIN_LENGTH", 3),
+		MaxLength: getIntEnv("USERNAME_MAX_LENGTH", 32),
+	}
+}
+
+// UsernameChangeCooldown func for getting time, after which user can change username again.
+func UsernameChangeCooldown() time.Duration {
+	return time.Duration(getIntEnv("USERNAME_CHANGE_COOLDOWN_DAYS", 30)) * 24 * time.Hour
+}
@@ -0,0 +1,50 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"sort"
+)
+
+// WriteArchive func for writing the given data to ZIP archive,
+// each top-level JSON field of the data is written to its own file (like "user.json").
+func WriteArchive(w io.Writer, data interface{}) error {
+	// Encode data to JSON.
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	// Split data by top-level fields.
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return err
+	}
+
+	// Sort field names for the same order of files.
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Create a new ZIP archive.
+	archive := zip.NewWriter(w)
+
+	// Write each field to its own file.
+	for _, name := range names {
+		file, err := archive.Create(name + ".json")
+		if err != nil {
+			return err
+		}
+
+		encoder := json.NewEncoder(file)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(fields[name]); err != nil {
+			return err
+		}
+	}
+
+	return archive.Close()
+}
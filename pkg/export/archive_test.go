@@ -0,0 +1,88 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"Komentory/auth/app/models"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readArchive func for reading all files of the ZIP archive (names in the archive order).
+func readArchive(t *testing.T, archive []byte) ([]string, map[string]string) {
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	require.NoError(t, err)
+
+	names, files := []string{}, map[string]string{}
+	for _, file := range reader.File {
+		content, err := file.Open()
+		require.NoError(t, err)
+		raw, err := io.ReadAll(content)
+		require.NoError(t, err)
+		require.NoError(t, content.Close())
+
+		names = append(names, file.Name)
+		files[file.Name] = string(raw)
+	}
+
+	return names, files
+}
+
+func TestWriteArchive(t *testing.T) {
+	// Define personal data of the user with all secrets, which are stored in database.
+	userID, expireAt := uuid.New(), time.Now().Add(time.Hour)
+	data := &models.UserExport{
+		User: models.User{ID: userID, Email: "user@example.com", PasswordHash: "password-hash-secret"},
+		Sessions: []models.Session{
+			{ID: uuid.New(), UserID: userID, RefreshTokenHash: "refresh-token-hash-secret", ExpireAt: expireAt, IPAddress: "127.0.0.1"},
+		},
+		SecurityLog: []models.AuditEvent{
+			{ID: 1, Event: "user_login", Outcome: "success", TargetID: &userID},
+		},
+		PersonalAccessTokens: []models.PersonalAccessToken{
+			{ID: uuid.New(), UserID: userID, Name: "deploy", TokenHash: "token-hash-secret", Credentials: models.Credentials{"user:own:read"}},
+		},
+		EmailChanges: []models.EmailChange{
+			{ID: uuid.New(), UserID: userID, OldEmail: "old@example.com", NewEmail: "user@example.com", ConfirmCode: "confirm-code-secret", RevertCode: "revert-code-secret"},
+		},
+	}
+
+	// Write archive to the buffer.
+	archive := &bytes.Buffer{}
+	require.NoError(t, WriteArchive(archive, data))
+	names, files := readArchive(t, archive.Bytes())
+
+	// Each top-level field is written to its own file (in sorted order).
+	assert.Equal(t, []string{
+		"email_changes.json", "personal_access_tokens.json", "security_log.json", "sessions.json", "user.json",
+	}, names, "need to write each field to its own file")
+
+	// Files contain personal data of the user.
+	user := models.User{}
+	require.NoError(t, json.Unmarshal([]byte(files["user.json"]), &user))
+	assert.Equal(t, userID, user.ID, "need to export user")
+	assert.Equal(t, "user@example.com", user.Email, "need to export user email")
+	sessions := []models.Session{}
+	require.NoError(t, json.Unmarshal([]byte(files["sessions.json"]), &sessions))
+	if assert.Len(t, sessions, 1, "need to export sessions") {
+		assert.Equal(t, "127.0.0.1", sessions[0].IPAddress, "need to export IP address of the session")
+	}
+	assert.Contains(t, files["security_log.json"], `"event": "user_login"`, "need to export security log")
+	assert.Contains(t, files["personal_access_tokens.json"], `"name": "deploy"`, "need to export personal access tokens")
+	assert.Contains(t, files["email_changes.json"], `"old_email": "old@example.com"`, "need to export email changes")
+
+	// Password hash, token hashes and codes are never exported.
+	for name, content := range files {
+		for _, secret := range []string{"secret", "password_hash", "token_hash", "confirm_code", "revert_code"} {
+			assert.False(t, strings.Contains(content, secret), "need to not export %s in %s", secret, name)
+		}
+	}
+}
@@ -0,0 +1,37 @@
+package export
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// Sign func for making signature of the download link for the given export ID and expiration time.
+func Sign(secret, id string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(fmt.Sprintf("export:%s:%d", id, expires)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify func for checking signature and expiration time of the download link.
+// Links are never valid without secret key.
+func Verify(secret, id string, expires int64, signature string) error {
+	// Checking, if secret key is set.
+	if secret == "" {
+		return fmt.Errorf("link key is not set")
+	}
+
+	// Checking signature (in constant time).
+	if !hmac.Equal([]byte(Sign(secret, id, expires)), []byte(signature)) {
+		return fmt.Errorf("signature is not valid")
+	}
+
+	// Checking expiration time.
+	if time.Now().Unix() > expires {
+		return fmt.Errorf("link was expired")
+	}
+
+	return nil
+}
@@ -0,0 +1,45 @@
+package export
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyLink(t *testing.T) {
+	// Define signed link, which is valid for an hour.
+	id, expires := "2c5a8f7e-3b1d-4e9a-9f0c-7d6b5a4c3e21", time.Now().Add(time.Hour).Unix()
+	signature := Sign("export-secret", id, expires)
+	expired := time.Now().Add(-time.Second).Unix()
+	tampered := []byte(signature)
+	tampered[0] ^= 1
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description   string
+		secret        string
+		id            string
+		expires       int64
+		signature     string
+		expectedError string
+	}{
+		{"allow signed link", "export-secret", id, expires, signature, ""},
+		{"refuse link signed by other key", "jwt-secret", id, expires, signature, "signature is not valid"},
+		{"refuse link without key", "", id, expires, Sign("", id, expires), "link key is not set"},
+		{"refuse link with other export ID", "export-secret", "8e1f0b2a-6c4d-4f3e-a5b7-9d8c7b6a5f40", expires, signature, "signature is not valid"},
+		{"refuse link with extended expiration time", "export-secret", id, expires + 3600, signature, "signature is not valid"},
+		{"refuse link with changed signature", "export-secret", id, expires, string(tampered), "signature is not valid"},
+		{"refuse link with empty signature", "export-secret", id, expires, "", "signature is not valid"},
+		{"refuse expired link", "export-secret", id, expired, Sign("export-secret", id, expired), "link was expired"},
+	}
+
+	for _, test := range tests {
+		err := Verify(test.secret, test.id, test.expires, test.signature)
+		if test.expectedError == "" {
+			assert.NoError(t, err, "need to %s", test.description)
+		} else {
+			assert.EqualError(t, err, test.expectedError, "need to %s", test.description)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const argon2idID = "argon2id"
+
+// Argon2idHasher struct to describe Argon2id password hasher.
+type Argon2idHasher struct {
+	Memory      uint32 // in KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// argon2idParams struct to describe parameters, parsed from the encoded hash.
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+	salt        []byte
+	key         []byte
+}
+
+// ID method to get Argon2id identifier.
+func (h *Argon2idHasher) ID() string {
+	return argon2idID
+}
+
+// Hash method to make a new Argon2id hash of the given password
+// in PHC string format ($argon2id$v=19$m=65536,t=3,p=2$<salt>$<key>).
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	// Generate a new random salt.
+	salt := make([]byte, h.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+
+	// Make key from the password.
+	key := argon2.IDKey([]byte(password), salt, h.Iterations, h.Memory, h.Parallelism, h.KeyLength)
+
+	return fmt.Sprintf(
+		"$%s$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2idID, argon2.Version, h.Memory, h.Iterations, h.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify method to compare Argon2id hash with the given password.
+func (h *Argon2idHasher) Verify(encoded, password string) (bool, error) {
+	// Parse parameters from the encoded hash.
+	p, err := parseArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	// Make key from the password with the same parameters.
+	key := argon2.IDKey([]byte(password), p.salt, p.iterations, p.memory, p.parallelism, uint32(len(p.key)))
+
+	return subtle.ConstantTimeCompare(key, p.key) == 1, nil
+}
+
+// NeedsRehash method to check, if Argon2id hash was made with another parameters.
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	p, err := parseArgon2id(encoded)
+	return err != nil ||
+		p.memory != h.Memory || p.iterations != h.Iterations || p.parallelism != h.Parallelism ||
+		uint32(len(p.salt)) != h.SaltLength || uint32(len(p.key)) != h.KeyLength
+}
+
+// parseArgon2id func for parsing parameters, salt and key from the encoded hash.
+func parseArgon2id(encoded string) (*argon2idParams, error) {
+	// Split encoded hash to the parts ("", id, version, params, salt, key).
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[1] != argon2idID {
+		return nil, fmt.Errorf("argon2id hash has wrong format")
+	}
+
+	// Checking version of the algorithm.
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return nil, fmt.Errorf("argon2id hash has unsupported version")
+	}
+
+	// Parse parameters.
+	p := &argon2idParams{}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return nil, fmt.Errorf("argon2id hash has wrong parameters")
+	}
+
+	// Decode salt and key.
+	var err error
+	if p.salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return nil, fmt.Errorf("argon2id hash has wrong salt")
+	}
+	if p.key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil || len(p.key) == 0 {
+		return nil, fmt.Errorf("argon2id hash has wrong key")
+	}
+
+	return p, nil
+}
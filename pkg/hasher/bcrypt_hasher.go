@@ -0,0 +1,48 @@
+package hasher
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const bcryptID = "2a"
+
+// BcryptHasher struct to describe bcrypt password hasher.
+type BcryptHasher struct {
+	Cost int
+}
+
+// ID method to get bcrypt identifier.
+func (h *BcryptHasher) ID() string {
+	return bcryptID
+}
+
+// Hash method to make a new bcrypt hash of the given password.
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), h.Cost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
+
+// Verify method to compare bcrypt hash with the given password.
+func (h *BcryptHasher) Verify(encoded, password string) (bool, error) {
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// NeedsRehash method to check, if bcrypt hash was made with another cost.
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.Cost
+}
@@ -0,0 +1,86 @@
+package hasher
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Hasher interface to describe password hashing algorithm.
+type Hasher interface {
+	// ID method to get algorithm identifier in PHC string format ("argon2id", "2a", ...).
+	ID() string
+	// Hash method to make a new encoded hash of the given password.
+	Hash(password string) (string, error)
+	// Verify method to compare encoded hash with the given password.
+	Verify(encoded, password string) (bool, error)
+	// NeedsRehash method to check, if encoded hash was made with outdated parameters.
+	NeedsRehash(encoded string) bool
+}
+
+// Set struct to describe a default hasher for the new hashes
+// and hashers for verifying hashes of all supported algorithms.
+type Set struct {
+	current Hasher
+	hashers map[string]Hasher
+}
+
+// NewSet func for create a new set of hashers (first one is used for the new hashes).
+func NewSet(current Hasher, others ...Hasher) *Set {
+	// Create a new set with the current hasher.
+	s := &Set{
+		current: current,
+		hashers: map[string]Hasher{current.ID(): current},
+	}
+
+	// Add other hashers (current one is not replaced).
+	for _, h := range others {
+		if _, ok := s.hashers[h.ID()]; !ok {
+			s.hashers[h.ID()] = h
+		}
+	}
+
+	return s
+}
+
+// Hash method to make a new encoded hash of the given password by the current hasher.
+func (s *Set) Hash(password string) (string, error) {
+	return s.current.Hash(password)
+}
+
+// Verify method to compare encoded hash with the given password by the hasher of its algorithm.
+// Returns true as the second value, if hash must be replaced by the current hasher.
+func (s *Set) Verify(encoded, password string) (bool, bool, error) {
+	// Get algorithm ID from the encoded hash.
+	id := identify(encoded)
+
+	// Get hasher by the algorithm ID.
+	h, ok := s.hashers[id]
+	if !ok {
+		return false, false, fmt.Errorf("unknown password hash algorithm: %q", id)
+	}
+
+	// Compare hash with the given password.
+	match, err := h.Verify(encoded, password)
+	if err != nil || !match {
+		return false, false, err
+	}
+
+	return true, h != s.current || h.NeedsRehash(encoded), nil
+}
+
+// identify func for getting algorithm ID from the encoded hash ("$<id>$...").
+func identify(encoded string) string {
+	// Split encoded hash to the parts ("", id, ...).
+	parts := strings.SplitN(encoded, "$", 3)
+	if len(parts) < 3 || parts[0] != "" {
+		return ""
+	}
+
+	// All bcrypt versions are verified by the same hasher.
+	switch parts[1] {
+	case "2a", "2b", "2y":
+		return bcryptID
+	}
+
+	return parts[1]
+}
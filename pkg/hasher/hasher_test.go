@@ -0,0 +1,178 @@
+package hasher
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// newTestArgon2idHasher func for creating Argon2id hasher with small parameters (fast enough for tests).
+func newTestArgon2idHasher() *Argon2idHasher {
+	return &Argon2idHasher{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}
+}
+
+func TestArgon2idHasher(t *testing.T) {
+	h := newTestArgon2idHasher()
+
+	// Make a new hash of the password.
+	encoded, err := h.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$"), "need to encode hash in PHC format")
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description   string
+		password      string
+		expectedMatch bool
+	}{
+		{"match the same password", "correct horse battery staple", true},
+		{"not match another password", "Correct horse battery staple", false},
+		{"not match empty password", "", false},
+	}
+
+	for _, test := range tests {
+		match, err := h.Verify(encoded, test.password)
+		require.NoError(t, err, test.description)
+		assert.Equal(t, test.expectedMatch, match, "need to %s", test.description)
+	}
+
+	// Salt is random, so hashes of the same password are different.
+	other, err := h.Hash("correct horse battery staple")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other, "need to make hashes with random salt")
+}
+
+func TestParseArgon2id(t *testing.T) {
+	// Define a valid hash for making the malformed ones.
+	encoded, err := newTestArgon2idHasher().Hash("password")
+	require.NoError(t, err)
+	parts := strings.Split(encoded, "$")
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description string
+		encoded     string
+		expectedErr string
+	}{
+		{"parse valid hash", encoded, ""},
+		{"refuse empty string", "", "wrong format"},
+		{"refuse hash of another algorithm", strings.Replace(encoded, "$argon2id$", "$argon2i$", 1), "wrong format"},
+		{"refuse hash without key", strings.Join(parts[:5], "$"), "wrong format"},
+		{"refuse hash with extra part", encoded + "$extra", "wrong format"},
+		{"refuse hash of another version", strings.Replace(encoded, "$v=19$", "$v=16$", 1), "unsupported version"},
+		{"refuse hash without version", strings.Replace(encoded, "$v=19$", "$19$", 1), "unsupported version"},
+		{"refuse hash with wrong parameters", strings.Replace(encoded, "m=64,t=1,p=1", "m=64;t=1;p=1", 1), "wrong parameters"},
+		{"refuse hash with wrong salt", strings.Replace(encoded, parts[4], "!"+parts[4], 1), "wrong salt"},
+		{"refuse hash with wrong key", strings.Replace(encoded, parts[5], "!"+parts[5], 1), "wrong key"},
+		{"refuse hash with empty key", strings.Join(parts[:5], "$") + "$", "wrong key"},
+	}
+
+	for _, test := range tests {
+		_, err := parseArgon2id(test.encoded)
+		if test.expectedErr == "" {
+			assert.NoError(t, err, "need to %s", test.description)
+			continue
+		}
+		if assert.Error(t, err, "need to %s", test.description) {
+			assert.Contains(t, err.Error(), test.expectedErr, "need to %s", test.description)
+		}
+
+		// Malformed hash is never matched and must be replaced.
+		_, err = newTestArgon2idHasher().Verify(test.encoded, "password")
+		assert.Error(t, err, "need to %s on verify", test.description)
+		assert.True(t, newTestArgon2idHasher().NeedsRehash(test.encoded), "need to %s on rehash check", test.description)
+	}
+}
+
+func TestArgon2idNeedsRehash(t *testing.T) {
+	// Make a new hash with the current parameters.
+	encoded, err := newTestArgon2idHasher().Hash("password")
+	require.NoError(t, err)
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description    string
+		change         func(h *Argon2idHasher)
+		expectedRehash bool
+	}{
+		{"keep hash with the same parameters", func(h *Argon2idHasher) {}, false},
+		{"rehash after memory change", func(h *Argon2idHasher) { h.Memory = 128 }, true},
+		{"rehash after iterations change", func(h *Argon2idHasher) { h.Iterations = 2 }, true},
+		{"rehash after parallelism change", func(h *Argon2idHasher) { h.Parallelism = 2 }, true},
+		{"rehash after salt length change", func(h *Argon2idHasher) { h.SaltLength = 32 }, true},
+		{"rehash after key length change", func(h *Argon2idHasher) { h.KeyLength = 64 }, true},
+	}
+
+	for _, test := range tests {
+		h := newTestArgon2idHasher()
+		test.change(h)
+		assert.Equal(t, test.expectedRehash, h.NeedsRehash(encoded), "need to %s", test.description)
+	}
+}
+
+func TestIdentify(t *testing.T) {
+	// Make a new bcrypt hash (with "2a" prefix).
+	hash, err := bcrypt.GenerateFromPassword([]byte("password"), bcrypt.MinCost)
+	require.NoError(t, err)
+	encoded := string(hash)
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description string
+		encoded     string
+		expectedID  string
+	}{
+		{"detect bcrypt 2a", encoded, bcryptID},
+		{"detect bcrypt 2b", strings.Replace(encoded, "$2a$", "$2b$", 1), bcryptID},
+		{"detect bcrypt 2y", strings.Replace(encoded, "$2a$", "$2y$", 1), bcryptID},
+		{"detect argon2id", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5", argon2idID},
+		{"not detect plain string", "password", ""},
+		{"not detect string without leading separator", "2a$10$abc", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedID, identify(test.encoded), "need to %s", test.description)
+	}
+}
+
+func TestSetVerify(t *testing.T) {
+	// Create a new set with Argon2id as the current hasher.
+	bcryptHasher := &BcryptHasher{Cost: bcrypt.MinCost}
+	set := NewSet(newTestArgon2idHasher(), bcryptHasher)
+
+	// Make hashes by both hashers.
+	argon2idHash, err := set.Hash("password")
+	require.NoError(t, err)
+	bcryptHash, err := bcryptHasher.Hash("password")
+	require.NoError(t, err)
+	outdatedHash, err := (&Argon2idHasher{Memory: 32, Iterations: 1, Parallelism: 1, SaltLength: 16, KeyLength: 32}).Hash("password")
+	require.NoError(t, err)
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description    string
+		encoded        string
+		password       string
+		expectedMatch  bool
+		expectedRehash bool
+		expectedError  bool
+	}{
+		{"keep current argon2id hash", argon2idHash, "password", true, false, false},
+		{"rehash argon2id hash with outdated parameters", outdatedHash, "password", true, true, false},
+		{"rehash bcrypt 2a hash", bcryptHash, "password", true, true, false},
+		{"rehash bcrypt 2y hash", strings.Replace(bcryptHash, "$2a$", "$2y$", 1), "password", true, true, false},
+		{"not rehash bcrypt hash of wrong password", bcryptHash, "wrong password", false, false, false},
+		{"not match argon2id hash of wrong password", argon2idHash, "wrong password", false, false, false},
+		{"refuse unknown algorithm", "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5", "password", false, false, true},
+	}
+
+	for _, test := range tests {
+		match, rehash, err := set.Verify(test.encoded, test.password)
+		assert.Equal(t, test.expectedError, err != nil, "need to %s (error: %v)", test.description, err)
+		assert.Equal(t, test.expectedMatch, match, "need to %s (match)", test.description)
+		assert.Equal(t, test.expectedRehash, rehash, "need to %s (rehash)", test.description)
+	}
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"Komentory/auth/app/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// NewAuditEvent func for making a new audit event object with client info from the given context
+// (uuid.Nil as actor or target ID means, that it's unknown).
+func NewAuditEvent(c *fiber.Ctx, event, outcome string, actorID, targetID uuid.UUID) *models.AuditEvent {
+	// Create a new audit event with client info (IP address and user agent).
+	auditEvent := &models.AuditEvent{
+		CreatedAt: time.Now(),
+		Event:     event,
+		Outcome:   outcome,
+		IPAddress: c.IP(),
+		UserAgent: truncateString(c.Get(fiber.HeaderUserAgent), 255),
+		Details:   models.AuditEventDetails{},
+	}
+
+	// Set actor and target, if they are known.
+	if actorID != uuid.Nil {
+		auditEvent.ActorID = &actorID
+	}
+	if targetID != uuid.Nil {
+		auditEvent.TargetID = &targetID
+	}
+
+	// Set admin ID, if the action was made by impersonation token.
+	if token, ok := c.Locals("jwt").(*jwt.Token); ok {
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if adminID, ok := claims["act"].(string); ok {
+				auditEvent.Details["impersonated_by"] = adminID
+			}
+		}
+	}
+
+	return auditEvent
+}
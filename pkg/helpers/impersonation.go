@@ -0,0 +1,52 @@
+package helpers
+
+import (
+	"Komentory/auth/app/models"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
+)
+
+// NewImpersonation func for making a new impersonation object (admin acts as user).
+func NewImpersonation(c *fiber.Ctx, adminID, userID uuid.UUID, expireAt time.Time) *models.Impersonation {
+	// Create a new variable for timestamp, because time fields in Impersonation model are pointers.
+	now := time.Now()
+
+	// Return a new impersonation with client info (IP address and user agent).
+	return &models.Impersonation{
+		ID:        uuid.New(),
+		CreatedAt: &now,
+		AdminID:   adminID,
+		UserID:    userID,
+		ExpireAt:  expireAt,
+		IPAddress: c.IP(),
+		UserAgent: truncateString(c.Get(fiber.HeaderUserAgent), 255),
+	}
+}
+
+// ExtractImpersonationID func for getting impersonation ID from the parsed JWT
+// (returns error, if the current token is not an impersonation token).
+func ExtractImpersonationID(c *fiber.Ctx) (uuid.UUID, error) {
+	// Get parsed JWT from JWTProtected middleware.
+	token, ok := c.Locals("jwt").(*jwt.Token)
+	if !ok {
+		return uuid.UUID{}, fmt.Errorf("token is missing")
+	}
+
+	// Get claims from the token.
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return uuid.UUID{}, fmt.Errorf("token is not valid")
+	}
+
+	// Get impersonation ID from the "imp" claim.
+	impersonationID, ok := claims["imp"].(string)
+	if !ok {
+		return uuid.UUID{}, fmt.Errorf("token is not an impersonation token")
+	}
+
+	return uuid.Parse(impersonationID)
+}
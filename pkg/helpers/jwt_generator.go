@@ -35,21 +35,54 @@ func GenerateNewTokens(id string, role int) (*models.Tokens, error) {
 	}, nil
 }
 
-// ParseRefreshToken func for parse second argument from refresh token.
+// GenerateNewUserTokens func for generate a new Access & Refresh tokens for the given user.
+// If user password is required to change, Access token allows to update password only.
+func GenerateNewUserTokens(user *models.User) (*models.Tokens, error) {
+	// Checking, if user password was compromised.
+	if !user.PasswordChangeRequired {
+		return GenerateNewTokens(user.ID.String(), user.UserRole)
+	}
+
+	// Generate JWT Access token with password update credential only.
+	accessToken, err := GenerateNewAccessToken(user.ID.String(), []string{
+		utilities.GenerateCredential("user_password", "update", true),
+	})
+	if err != nil {
+		// Return token generation error.
+		return nil, err
+	}
+
+	// Generate JWT Refresh token.
+	refreshToken, err := generateNewRefreshToken(user.ID.String())
+	if err != nil {
+		// Return token generation error.
+		return nil, err
+	}
+
+	return &models.Tokens{
+		Access:  accessToken,
+		Refresh: refreshToken,
+	}, nil
+}
+
+// ParseRefreshToken func for parse user ID and expire time from refresh token.
 func ParseRefreshToken(refreshToken string) (uuid.UUID, int64, error) {
-	// Send error message, when refresh token is empty.
-	if refreshToken == "" {
+	// Split refresh token to parts (user ID + expire time + random string).
+	parts := strings.Split(refreshToken, ".")
+
+	// Send error message, when refresh token is empty or has wrong format.
+	if refreshToken == "" || len(parts) != 3 {
 		return uuid.UUID{}, 0, fmt.Errorf("refresh token is empty or not valid")
 	}
 
 	// Parse user ID (UUID).
-	userID, err := uuid.Parse(strings.Split(refreshToken, ".")[0])
+	userID, err := uuid.Parse(parts[0])
 	if err != nil {
 		return uuid.UUID{}, 0, fmt.Errorf("user ID is empty or not valid")
 	}
 
 	// Parse timestamp (int64).
-	token, err := strconv.ParseInt(strings.Split(refreshToken, ".")[1], 0, 64)
+	token, err := strconv.ParseInt(parts[1], 0, 64)
 	if err != nil {
 		return uuid.UUID{}, 0, fmt.Errorf("expire time is empty or not valid")
 	}
@@ -58,35 +91,88 @@ func ParseRefreshToken(refreshToken string) (uuid.UUID, int64, error) {
 	return userID, token, nil
 }
 
-func generateNewAccessToken(id string, role int) (string, error) {
-	// Set secret key from .env file.
-	secret := os.Getenv("JWT_SECRET_KEY")
+// GenerateNewPersonalAccessJWT func for generate a new short-lived Access token
+// by the given personal access token (used instead of the user's login).
+func GenerateNewPersonalAccessJWT(id, tokenID string, credentials []string) (string, error) {
+	// Create a new claims.
+	claims := jwt.MapClaims{}
 
-	// Set expires minutes count for secret key from .env file.
-	minutesCount, err := strconv.Atoi(os.Getenv("JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT"))
-	if err != nil {
-		m := utilities.GenerateErrorMessage(400, "token", "invalid expiration minutes count")
-		return "", fmt.Errorf(m)
-	}
+	// Set public claims:
+	claims["id"] = id
+	claims["pat"] = tokenID
+	claims["credentials"] = credentials
+
+	return generateNewAccessTokenWithClaims(claims, "JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT")
+}
 
+// GenerateNewImpersonationAccessToken func for generate a new short-lived Access token
+// for the user, which is impersonated by the admin (with "act" claim, no Refresh token).
+func GenerateNewImpersonationAccessToken(id, actorID, impersonationID string, credentials []string) (string, error) {
 	// Create a new claims.
 	claims := jwt.MapClaims{}
 
+	// Set public claims:
+	claims["id"] = id
+	claims["act"] = actorID
+	claims["imp"] = impersonationID
+	claims["credentials"] = credentials
+
+	return generateNewAccessTokenWithClaims(claims, "JWT_IMPERSONATION_KEY_EXPIRE_MINUTES_COUNT")
+}
+
+// GenerateNewClientAccessToken func for generate a new short-lived Access token
+// for the service client (with "client_id" instead of user "id").
+func GenerateNewClientAccessToken(clientID string, scopes []string) (string, error) {
+	// Create a new claims.
+	claims := jwt.MapClaims{}
+
+	// Set public claims:
+	claims["client_id"] = clientID
+	claims["credentials"] = scopes
+
+	return generateNewAccessTokenWithClaims(claims, "JWT_CLIENT_KEY_EXPIRE_MINUTES_COUNT")
+}
+
+// GenerateNewAccessToken func for generate a new Access token with the given credentials.
+func GenerateNewAccessToken(id string, credentials []string) (string, error) {
+	// Create a new claims.
+	claims := jwt.MapClaims{}
+
+	// Set public claims:
+	claims["id"] = id
+	claims["credentials"] = credentials
+
+	return generateNewAccessTokenWithClaims(claims, "JWT_SECRET_KEY_EXPIRE_MINUTES_COUNT")
+}
+
+func generateNewAccessToken(id string, role int) (string, error) {
 	// Set default role.
 	if role == 0 {
 		role = utilities.RoleNameUser
 	}
 
 	// Get credentials from role.
-	credentials, err := utilities.GenerateCredentialsByRole(role)
+	credentials, err := GetCredentialsByRole(role)
 	if err != nil {
 		return "", err
 	}
 
-	// Set public claims:
-	claims["id"] = id
+	return GenerateNewAccessToken(id, credentials)
+}
+
+func generateNewAccessTokenWithClaims(claims jwt.MapClaims, expireMinutesCountKey string) (string, error) {
+	// Set secret key from .env file.
+	secret := os.Getenv("JWT_SECRET_KEY")
+
+	// Set expires minutes count for the token from .env file.
+	minutesCount, err := strconv.Atoi(os.Getenv(expireMinutesCountKey))
+	if err != nil {
+		m := utilities.GenerateErrorMessage(400, "token", "invalid expiration minutes count")
+		return "", fmt.Errorf(m)
+	}
+
+	// Set expiration time.
 	claims["expire"] = time.Now().Add(time.Minute * time.Duration(minutesCount)).Unix()
-	claims["credentials"] = credentials
 
 	// Create a new JWT access token with claims.
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -112,6 +198,13 @@ func generateNewRefreshToken(userID string) (string, error) {
 	// Set expiration time.
 	expireTime := time.Now().Add(time.Hour * time.Duration(hoursCount)).Unix()
 
-	// Return a new refresh token (nanoID random string + user ID + expire time).
-	return fmt.Sprintf("%s.%d", userID, expireTime), nil
+	// Generate a new random string with nanoID.
+	randomString, err := utilities.GenerateNewNanoID(utilities.LowerCaseWithoutDashesChars, 32)
+	if err != nil {
+		return "", err
+	}
+
+	// Return a new refresh token (user ID + expire time + nanoID random string).
+	// Random string makes token unguessable, only its hash is stored in sessions table.
+	return fmt.Sprintf("%s.%d.%s", userID, expireTime, randomString), nil
 }
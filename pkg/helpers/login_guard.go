@@ -0,0 +1,76 @@
+package helpers
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/limiter"
+	"Komentory/auth/platform/cache"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	loginGuard     *limiter.LoginGuard // nil == in-memory store is set on first use
+	loginGuardOnce sync.Once
+)
+
+// InitLoginGuard func for creating brute-force protection for the login attempts with the store
+// from LIMITER_STORE ("memory" by default, or "redis" for many instances of the app).
+// It must be called on the app start, so unknown or unavailable store refuses the start
+// (limits of the memory store are not shared between instances).
+func InitLoginGuard() error {
+	// Define store by the given kind.
+	var store limiter.Store
+	switch kind := os.Getenv("LIMITER_STORE"); kind {
+	case "", "memory":
+		store = limiter.NewMemoryStore()
+	case "redis":
+		// Get shared Redis client.
+		client, err := cache.RedisConnection()
+		if err != nil {
+			return err
+		}
+		store = cache.NewRedisStore(client, "limiter:")
+	default:
+		return fmt.Errorf("unknown limiter store %q (want \"memory\" or \"redis\")", kind)
+	}
+
+	loginGuard = limiter.NewLoginGuard(limiter.New(store), configs.LoginLimiterConfig())
+
+	return nil
+}
+
+// LoginGuard func for getting brute-force protection for the login attempts
+// (with in-memory store, if InitLoginGuard was not called, for example, in tests).
+func LoginGuard() *limiter.LoginGuard {
+	// Create login guard with in-memory store on first use.
+	loginGuardOnce.Do(func() {
+		if loginGuard == nil {
+			loginGuard = limiter.NewLoginGuard(limiter.New(limiter.NewMemoryStore()), configs.LoginLimiterConfig())
+		}
+	})
+
+	return loginGuard
+}
+
+// ThrowTooManyRequestsError func for throwing 429 error with Retry-After header in JSON format.
+func ThrowTooManyRequestsError(c *fiber.Ctx, object string, retryAfter time.Duration) error {
+	// Round up seconds for the Retry-After header.
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+
+	// Set Retry-After header.
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(seconds))
+
+	// Return status 429 and too many requests error.
+	return c.Status(fiber.StatusTooManyRequests).JSON(fiber.Map{
+		"status":      fiber.StatusTooManyRequests,
+		"msg":         "too many requests to the " + object + " (try again in " + strconv.Itoa(seconds) + " seconds)",
+		"retry_after": seconds,
+	})
+}
@@ -0,0 +1,40 @@
+package helpers
+
+import (
+	"sync"
+
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/hasher"
+)
+
+var (
+	passwordHasher     *hasher.Set
+	passwordHasherOnce sync.Once
+)
+
+// PasswordHasher func for getting password hasher from .env file.
+func PasswordHasher() *hasher.Set {
+	// Create password hasher from .env file on first use.
+	passwordHasherOnce.Do(func() {
+		passwordHasher = configs.PasswordHasherConfig()
+	})
+
+	return passwordHasher
+}
+
+// GeneratePasswordHash func for making a new hash of the given password by the current algorithm.
+func GeneratePasswordHash(p string) (string, error) {
+	return PasswordHasher().Hash(p)
+}
+
+// ComparePasswordHash func for comparing hash with the given password.
+// Returns true as the second value, if hash was made with outdated algorithm or parameters.
+func ComparePasswordHash(hash, p string) (bool, bool) {
+	// Compare hash with the password (unknown or broken hash never matches).
+	match, needsRehash, err := PasswordHasher().Verify(hash, p)
+	if err != nil {
+		return false, false
+	}
+
+	return match, needsRehash
+}
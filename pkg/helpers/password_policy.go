@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"os"
+	"strings"
+	"sync"
+
+	"Komentory/auth/pkg/breach"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/password"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	passwordPolicy     *password.Policy
+	passwordPolicyOnce sync.Once
+	breachChecker      breach.Checker // nil == passwords are not checked in data breaches
+)
+
+// InitBreachChecker func for opening the local Pwned Passwords file from BREACHED_PASSWORDS_FILE
+// (no check, if it's not set). It must be called on the app start, so wrong file refuses the start.
+func InitBreachChecker() error {
+	path := os.Getenv("BREACHED_PASSWORDS_FILE")
+	if path == "" {
+		return nil
+	}
+
+	checker, err := breach.NewFileChecker(path)
+	if err != nil {
+		return err
+	}
+
+	SetBreachChecker(checker)
+
+	return nil
+}
+
+// SetBreachChecker func for setting checker of the passwords in data breaches (nil for no check).
+func SetBreachChecker(c breach.Checker) {
+	breachChecker = c
+}
+
+// CheckPassword func for checking the given password by the password policy from .env file
+// and in data breaches. Returns list of the broken rules (empty, if password is good).
+func CheckPassword(p string, userInputs ...string) ([]string, error) {
+	// Create password policy from .env file on first use.
+	passwordPolicyOnce.Do(func() {
+		passwordPolicy = configs.PasswordPolicyConfig()
+	})
+
+	// Checking password by the password policy.
+	violations := passwordPolicy.Check(p, userInputs...)
+
+	// Checking, if password was found in data breaches.
+	isBreached, err := IsBreachedPassword(p)
+	if err != nil {
+		return nil, err
+	}
+	if isBreached {
+		violations = append(violations, "was found in data breaches")
+	}
+
+	return violations, nil
+}
+
+// IsBreachedPassword func for checking the given password in data breaches
+// by the checker, opened on the app start (no check, if it's not set).
+func IsBreachedPassword(p string) (bool, error) {
+	// Checking, if breach checker is not configured.
+	if breachChecker == nil {
+		return false, nil
+	}
+
+	// Get count of the password appearances in data breaches.
+	count, err := breachChecker.Check(p)
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// ThrowPasswordPolicyError func for throwing field-level validation error for the password field
+// (in the same format as validation errors of the struct fields).
+func ThrowPasswordPolicyError(c *fiber.Ctx, object, field string, violations []string) error {
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusBadRequest,
+		"msg":    "validation errors for the " + object + " fields",
+		"fields": fiber.Map{
+			field: "password " + strings.Join(violations, ", "),
+		},
+	})
+}
@@ -0,0 +1,30 @@
+package helpers
+
+import (
+	"strings"
+
+	"github.com/Komentory/utilities"
+)
+
+// PersonalAccessTokenPrefix const for the prefix of each personal access token.
+const PersonalAccessTokenPrefix string = "pat_"
+
+// GenerateNewPersonalAccessToken func for generate a new personal access token
+// and its hash (only hash is stored in database).
+func GenerateNewPersonalAccessToken() (string, string, error) {
+	// Generate a new random string with nanoID.
+	randomString, err := utilities.GenerateNewNanoID("", 40)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Set prefix for the token.
+	token := PersonalAccessTokenPrefix + randomString
+
+	return token, HashToken(token), nil
+}
+
+// IsPersonalAccessToken func for checking, if the given token is personal access token.
+func IsPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, PersonalAccessTokenPrefix)
+}
@@ -0,0 +1,138 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+)
+
+// credentialsCache struct to describe in-memory cache of the credentials by role,
+// loaded from roles, permissions and role_permissions tables.
+type credentialsCache struct {
+	sync.RWMutex
+	items    map[int][]string
+	expireAt time.Time
+}
+
+// roleCredentials is a cache for all roles credentials.
+var roleCredentials = &credentialsCache{}
+
+// credentialsRepository is a storage to load roles credentials from.
+var credentialsRepository database.CredentialsRepository
+
+// SetCredentialsRepository func for setting storage to load roles credentials from
+// (must be called on the app start).
+func SetCredentialsRepository(r database.CredentialsRepository) {
+	credentialsRepository = r
+	ResetCredentialsCache()
+}
+
+// GetCredentialsByRole func for getting slice of the credentials by given role number.
+// Credentials are cached for CREDENTIALS_CACHE_TTL_SECONDS (300 seconds by default).
+func GetCredentialsByRole(role int) ([]string, error) {
+	// Get credentials from cache, if it's not expired.
+	roleCredentials.RLock()
+	credentials, ok := roleCredentials.items[role]
+	isExpired := time.Now().After(roleCredentials.expireAt)
+	roleCredentials.RUnlock()
+
+	// Load credentials from database, if cache is empty or expired.
+	if isExpired {
+		items, err := loadRoleCredentials()
+		if err != nil {
+			return nil, err
+		}
+
+		// Define cache TTL.
+		ttlSecondsCount, err := strconv.Atoi(os.Getenv("CREDENTIALS_CACHE_TTL_SECONDS"))
+		if err != nil {
+			ttlSecondsCount = 300
+		}
+
+		// Update cache.
+		roleCredentials.Lock()
+		roleCredentials.items = items
+		roleCredentials.expireAt = time.Now().Add(time.Second * time.Duration(ttlSecondsCount))
+		roleCredentials.Unlock()
+
+		credentials, ok = items[role]
+	}
+
+	// Checking, if role is exists.
+	if !ok {
+		return nil, fmt.Errorf(utilities.GenerateErrorMessage(400, "role", fmt.Sprint(role)))
+	}
+
+	return credentials, nil
+}
+
+// adminCredentials is a list of the credentials, which give access to the admin routes.
+var adminCredentials = []string{
+	utilities.GenerateCredential("users", "manage", false),
+	utilities.GenerateCredential("users", "impersonate", false),
+	utilities.GenerateCredential("roles", "manage", false),
+	utilities.GenerateCredential("audit_events", "read", false),
+}
+
+// HasAdminCredentials func for checking, if the given credentials give access to any admin route.
+func HasAdminCredentials(credentials []string) bool {
+	for _, credential := range credentials {
+		for _, adminCredential := range adminCredentials {
+			if credential == adminCredential {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ResetCredentialsCache func for resetting cache of the credentials
+// (must be called after each change of roles or permissions).
+func ResetCredentialsCache() {
+	roleCredentials.Lock()
+	roleCredentials.items = nil
+	roleCredentials.expireAt = time.Time{}
+	roleCredentials.Unlock()
+}
+
+func loadRoleCredentials() (map[int][]string, error) {
+	// Checking, if storage is set.
+	db := credentialsRepository
+	if db == nil {
+		return nil, fmt.Errorf("storage for roles credentials is not set")
+	}
+
+	// Cache is shared by all requests, so it's loaded without request context (only with query timeout).
+	ctx := context.Background()
+
+	// Get all roles, include roles without any permission.
+	roles, _, err := db.GetRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get credentials of all roles.
+	credentials, _, err := db.GetRoleCredentials(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// Group credentials by role.
+	items := make(map[int][]string, len(roles))
+	for _, role := range roles {
+		items[role.ID] = []string{}
+	}
+	for _, credential := range credentials {
+		items[credential.RoleID] = append(items[credential.RoleID], credential.Credential)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,42 @@
+package helpers
+
+import (
+	"Komentory/auth/app/models"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// NewSession func for making a new session object for the given refresh token.
+func NewSession(c *fiber.Ctx, userID uuid.UUID, refreshToken string) (*models.Session, error) {
+	// Get expiration time of the refresh token.
+	_, expires, err := ParseRefreshToken(refreshToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Create a new variable for timestamp, because time fields in Session model are pointers.
+	now := time.Now()
+
+	// Return a new session with client info (IP address and user agent).
+	return &models.Session{
+		ID:               uuid.New(),
+		CreatedAt:        &now,
+		UserID:           userID,
+		RefreshTokenHash: HashToken(refreshToken),
+		ExpireAt:         time.Unix(expires, 0),
+		IPAddress:        c.IP(),
+		UserAgent:        truncateString(c.Get(fiber.HeaderUserAgent), 255),
+	}, nil
+}
+
+func truncateString(s string, length int) string {
+	// Convert string to runes for the correct unicode truncation.
+	runes := []rune(s)
+	if len(runes) <= length {
+		return s
+	}
+
+	return string(runes[:length])
+}
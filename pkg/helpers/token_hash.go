@@ -0,0 +1,13 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// HashToken func for making SHA-256 hash of the given token
+// (personal access token, refresh token, etc), only hash is stored in database.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
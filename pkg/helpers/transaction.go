@@ -0,0 +1,47 @@
+package helpers
+
+import (
+	"errors"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TxError struct to describe failed step of the transaction (with status and object for JSON error).
+type TxError struct {
+	Err    error
+	Status int
+	Object string
+}
+
+// Error method to get message of the original error.
+func (e *TxError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap method to get the original error.
+func (e *TxError) Unwrap() error {
+	return e.Err
+}
+
+// NewTxError func for stopping transaction with error for the given object.
+func NewTxError(err error, status int, object string) error {
+	return &TxError{Err: err, Status: status, Object: object}
+}
+
+// CheckForTxError func for returning error of the failed (and rolled back) transaction in JSON format.
+func CheckForTxError(c *fiber.Ctx, err error) error {
+	// Checking, if error is from the transaction step.
+	txError := &TxError{}
+	if !errors.As(err, &txError) {
+		// Transaction is not started or not committed.
+		return utilities.CheckForErrorWithStatusCode(c, err, 500, "transaction", err.Error())
+	}
+
+	// Set response status code only for server errors, like in other controllers.
+	if txError.Status >= fiber.StatusInternalServerError {
+		return utilities.CheckForErrorWithStatusCode(c, txError.Err, txError.Status, txError.Object, txError.Err.Error())
+	}
+
+	return utilities.CheckForError(c, txError.Err, txError.Status, txError.Object, txError.Err.Error())
+}
@@ -0,0 +1,46 @@
+package helpers
+
+import "Komentory/auth/app/models"
+
+// NewAuthenticatedUser func for remapping needed user fields from original User model
+// for output to the owner of the account.
+func NewAuthenticatedUser(user *models.User) *models.AuthenticatedUser {
+	return &models.AuthenticatedUser{
+		ID:         user.ID,
+		Email:      user.Email,
+		Username:   usernameOf(user),
+		FirstName:  user.UserAttrs.FirstName,
+		LastName:   user.UserAttrs.LastName,
+		AboutMe:    user.UserAttrs.AboutMe,
+		Picture:    user.UserAttrs.Picture,
+		WebsiteURL: user.UserAttrs.WebsiteURL,
+		Abilities:  user.UserAttrs.Abilities,
+		Status:     user.UserStatus,
+		Settings:   user.UserSettings,
+
+		PasswordChangeRequired: user.PasswordChangeRequired,
+	}
+}
+
+// NewPublicUser func for remapping only public user fields from original User model
+// for output to anyone (like commenter cards).
+func NewPublicUser(user *models.User) *models.PublicUser {
+	return &models.PublicUser{
+		ID:         user.ID,
+		Username:   usernameOf(user),
+		FirstName:  user.UserAttrs.FirstName,
+		LastName:   user.UserAttrs.LastName,
+		AboutMe:    user.UserAttrs.AboutMe,
+		Picture:    user.UserAttrs.Picture,
+		WebsiteURL: user.UserAttrs.WebsiteURL,
+		Abilities:  user.UserAttrs.Abilities,
+	}
+}
+
+// usernameOf func for getting username of the user (empty, if it's not set yet).
+func usernameOf(user *models.User) string {
+	if user.Username == nil {
+		return ""
+	}
+	return *user.Username
+}
@@ -0,0 +1,39 @@
+package helpers
+
+import (
+	"strings"
+	"sync"
+
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/username"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	usernamePolicy     *username.Policy
+	usernamePolicyOnce sync.Once
+)
+
+// CheckUsername func for checking the given username by the username policy from .env file.
+// Returns list of the broken rules (empty, if username is good).
+func CheckUsername(u string) []string {
+	// Create username policy from .env file on first use.
+	usernamePolicyOnce.Do(func() {
+		usernamePolicy = configs.UsernamePolicyConfig()
+	})
+
+	return usernamePolicy.Check(u)
+}
+
+// ThrowUsernamePolicyError func for throwing field-level validation error for the username field
+// (in the same format as validation errors of the struct fields).
+func ThrowUsernamePolicyError(c *fiber.Ctx, object string, violations []string) error {
+	return c.JSON(fiber.Map{
+		"status": fiber.StatusBadRequest,
+		"msg":    "validation errors for the " + object + " fields",
+		"fields": fiber.Map{
+			"username": "username " + strings.Join(violations, ", "),
+		},
+	})
+}
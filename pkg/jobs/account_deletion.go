@@ -0,0 +1,32 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/platform/database"
+)
+
+// deleteAccounts func for deleting a batch of user accounts, which grace period is over before the given time,
+// with removing them from cache and the audit event of the deletion.
+func deleteAccounts(storage database.JanitorRepository, cache database.UserCache, gracePeriod time.Duration) func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+		// Delete users, which requested deletion before the grace period.
+		ids, err := storage.DeleteUsersPendingDeletion(ctx, expiredBefore.Add(-gracePeriod), limit)
+		if err != nil {
+			return 0, err
+		}
+
+		// Remove deleted users from cache and record audit events for them.
+		for _, id := range ids {
+			if err := cache.InvalidateUser(ctx, id); err != nil {
+				log.Printf("Oops... Deleted user is not removed from cache! Reason: %v", err)
+			}
+			audit.System(audit.EventAccountDeletion, id)
+		}
+
+		return int64(len(ids)), nil
+	}
+}
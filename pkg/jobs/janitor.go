@@ -0,0 +1,171 @@
+package jobs
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/platform/database"
+)
+
+// Locker interface to describe database, which runs the given function only in one app instance at the same time.
+type Locker interface {
+	WithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error)
+}
+
+// Janitor struct to describe background job for purging expired rows of one table in batches.
+// Only one app instance runs the job at the same time (by advisory lock with the job name),
+// except local jobs, which purge SQLite database of each app instance.
+type Janitor struct {
+	Locker    Locker
+	Name      string
+	Interval  time.Duration
+	BatchSize int
+	Local     bool
+	Purge     func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error)
+}
+
+// janitorPurge struct to describe purge of the janitor job.
+type janitorPurge struct {
+	name     string
+	local    bool
+	interval time.Duration // zero == interval of the janitor from the config
+	purge    func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error)
+}
+
+// NewJanitors func for create janitor jobs for expired activation codes, reset codes, sessions and accounts
+// pending deletion (with intervals and batch sizes from the config). For PostgreSQL database, expired
+// personal access tokens, impersonations and user exports are purged too. SQLite storage has no advisory
+// locks (it's used by one app instance), so its jobs are local.
+func NewJanitors(storage database.JanitorRepository, cache database.UserCache) []*Janitor {
+	db, isPostgreSQL := storage.(*database.Queries)
+
+	// Define purges of the storage.
+	purges := []janitorPurge{
+		{"janitor_activation_codes", !isPostgreSQL, 0, storage.DeleteExpiredActivationCodes},
+		{"janitor_reset_codes", !isPostgreSQL, 0, storage.DeleteExpiredResetCodes},
+		{"janitor_sessions", !isPostgreSQL, 0, storage.DeleteExpiredSessions},
+		{"account_deletion", !isPostgreSQL, configs.AccountDeletionJobInterval(), deleteAccounts(storage, cache, configs.AccountDeletionGracePeriod())},
+	}
+	if isPostgreSQL {
+		purges = append(purges, []janitorPurge{
+			{"janitor_personal_access_tokens", false, 0, db.DeleteExpiredPersonalAccessTokens},
+			{"janitor_impersonations", false, 0, endExpiredImpersonations(db)},
+			{"janitor_user_exports", false, 0, db.DeleteExpiredUserExports},
+		}...)
+	}
+
+	janitors := make([]*Janitor, 0, len(purges))
+	for _, p := range purges {
+		// Define name of the purged table for the config.
+		table := strings.TrimPrefix(p.name, "janitor_")
+		interval := p.interval
+		if interval <= 0 {
+			interval = configs.JanitorJobInterval(table)
+		}
+
+		janitor := &Janitor{
+			Name:      p.name,
+			Interval:  interval,
+			BatchSize: configs.JanitorBatchSize(table),
+			Local:     p.local,
+			Purge:     p.purge,
+		}
+		if isPostgreSQL {
+			janitor.Locker = db
+		}
+		janitors = append(janitors, janitor)
+	}
+
+	return janitors
+}
+
+// Start method to run the job in background with the given interval, until the given context is done
+// (on the app shutdown). Job is added to the given wait group, so the app can wait for the running job.
+func (j *Janitor) Start(ctx context.Context, wg *sync.WaitGroup) {
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		// Create a new ticker with the job interval.
+		ticker := time.NewTicker(j.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			// Errors are only logged, the job is retried on next tick.
+			if err := j.Run(ctx); err != nil {
+				log.Printf("Oops... Job %s is failed! Reason: %v", j.Name, err)
+			}
+		}
+	}()
+}
+
+// Run method to delete all expired rows in batches (if job is not running by another app instance).
+func (j *Janitor) Run(ctx context.Context) error {
+	// Define start time and count of the deleted rows for metrics.
+	startedAt, deleted := time.Now(), int64(0)
+
+	// Define func for deleting expired rows.
+	purge := func(ctx context.Context) error {
+		for {
+			// Delete the next batch of rows, which expired before the job start.
+			count, err := j.Purge(ctx, startedAt, j.BatchSize)
+			deleted += count
+			if err != nil {
+				return err
+			}
+
+			// Stop, if the last batch is not full (nothing is left).
+			if count < int64(j.BatchSize) {
+				return nil
+			}
+		}
+	}
+
+	// Delete expired rows under the lock (local job without lock).
+	var isLocked bool
+	var err error
+	if j.Local {
+		err = purge(ctx)
+	} else {
+		isLocked, err = j.Locker.WithAdvisoryLock(ctx, j.Name, purge)
+	}
+
+	// Update metrics of the job (job is skipped, if the lock is held by another app instance).
+	recordRun(j.Name, startedAt, !j.Local && err == nil && !isLocked, deleted, err)
+
+	return err
+}
+
+// endExpiredImpersonations func for ending expired impersonations, which were not ended by admin,
+// with the audit event of the end (so each start has an end in the audit trail).
+func endExpiredImpersonations(db *database.Queries) func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+		// End a batch of expired impersonations.
+		impersonations, err := db.EndExpiredImpersonations(ctx, expiredBefore, limit)
+		if err != nil {
+			return 0, err
+		}
+
+		// Record audit events for the ended impersonations.
+		for _, impersonation := range impersonations {
+			audit.SystemWithDetails(audit.EventImpersonationEnd, impersonation.AdminID, impersonation.UserID, models.AuditEventDetails{
+				"impersonation_id": impersonation.ID.String(),
+				"reason":           "expired",
+			})
+		}
+
+		return int64(len(impersonations)), nil
+	}
+}
@@ -0,0 +1,163 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testLocker struct to describe database, which acquires (or not) the advisory lock.
+type testLocker struct {
+	isLocked bool
+}
+
+// WithAdvisoryLock method to run the given function, only if lock is acquired.
+func (l *testLocker) WithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	if !l.isLocked {
+		return false, nil
+	}
+
+	return true, fn(ctx)
+}
+
+// testPurge func for purging the given batches (the last one is not full) or failing with the given error.
+func testPurge(batches []int64, err error) func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return func(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+		if err != nil {
+			return 0, err
+		}
+		count := batches[0]
+		batches = batches[1:]
+		return count, nil
+	}
+}
+
+func TestJanitorRun(t *testing.T) {
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description     string
+		janitor         *Janitor
+		expectedErr     bool
+		expectedMetrics JobMetrics
+	}{
+		{
+			"run local job without lock",
+			&Janitor{Name: "test_local", BatchSize: 2, Local: true, Purge: testPurge([]int64{2, 2, 1}, nil)},
+			false, JobMetrics{Runs: 1, Deleted: 5},
+		},
+		{
+			"run job under the lock",
+			&Janitor{Name: "test_locked", Locker: &testLocker{isLocked: true}, BatchSize: 2, Purge: testPurge([]int64{1}, nil)},
+			false, JobMetrics{Runs: 1, Deleted: 1},
+		},
+		{
+			"skip job, which is running by another instance",
+			&Janitor{Name: "test_skipped", Locker: &testLocker{isLocked: false}, BatchSize: 2, Purge: testPurge(nil, nil)},
+			false, JobMetrics{Skips: 1},
+		},
+		{
+			"count failed local job",
+			&Janitor{Name: "test_failed", BatchSize: 2, Local: true, Purge: testPurge(nil, errors.New("purge is failed"))},
+			true, JobMetrics{Runs: 1, Failures: 1, LastError: "purge is failed"},
+		},
+	}
+
+	for _, test := range tests {
+		err := test.janitor.Run(context.Background())
+		assert.Equal(t, test.expectedErr, err != nil, "need to %s (error: %v)", test.description, err)
+
+		// Checking metrics of the job (time of the run is set only for the finished run).
+		metrics := Metrics()[test.janitor.Name]
+		assert.Equal(t, test.expectedMetrics.Runs, metrics.Runs, "need to %s (runs)", test.description)
+		assert.Equal(t, test.expectedMetrics.Skips, metrics.Skips, "need to %s (skips)", test.description)
+		assert.Equal(t, test.expectedMetrics.Failures, metrics.Failures, "need to %s (failures)", test.description)
+		assert.Equal(t, test.expectedMetrics.Deleted, metrics.Deleted, "need to %s (deleted)", test.description)
+		assert.Equal(t, test.expectedMetrics.LastError, metrics.LastError, "need to %s (last error)", test.description)
+		assert.Equal(t, test.expectedMetrics.Runs > 0, metrics.LastRunAt != nil, "need to %s (last run)", test.description)
+		assert.Equal(t, test.expectedMetrics.Runs > 0, metrics.LastDuration != "", "need to %s (last duration)", test.description)
+	}
+}
+
+func TestSQLiteJanitors(t *testing.T) {
+	// Define SQLite storage in a temporary file, accounts are deleted without grace period.
+	ctx := context.Background()
+	t.Setenv("DB_URL", "file:"+filepath.Join(t.TempDir(), "auth.db"))
+	t.Setenv("ACCOUNT_DELETION_GRACE_PERIOD_DAYS", "0")
+	storage, err := database.OpenSQLiteConnection()
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// Create tables and roles by the embedded migrations.
+	migrator, err := storage.Migrator()
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Define active user with expired and actual codes and sessions, and user pending deletion.
+	expiredAt, expireAt := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	active := models.User{ID: uuid.New(), Email: "active@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	leaving := models.User{ID: uuid.New(), Email: "leaving@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	for _, user := range []*models.User{&active, &leaving} {
+		require.NoError(t, storage.CreateNewUser(ctx, user))
+	}
+	require.NoError(t, storage.RequestUserDeletion(ctx, leaving.ID))
+	require.NoError(t, storage.CreateNewActivationCode(ctx, &models.ActivationCode{Code: "expired", ExpireAt: expiredAt, UserID: active.ID}))
+	require.NoError(t, storage.CreateNewActivationCode(ctx, &models.ActivationCode{Code: "actual", ExpireAt: expireAt, UserID: active.ID}))
+	require.NoError(t, storage.CreateNewResetCode(ctx, &models.ResetCode{Code: "expired", ExpireAt: expiredAt, Email: active.Email}))
+	require.NoError(t, storage.CreateNewResetCode(ctx, &models.ResetCode{Code: "actual", ExpireAt: expireAt, Email: active.Email}))
+	require.NoError(t, storage.CreateNewResetCode(ctx, &models.ResetCode{Code: "leaving", ExpireAt: expireAt, Email: leaving.Email}))
+	expiredSession := models.Session{ID: uuid.New(), UserID: active.ID, RefreshTokenHash: "expired", ExpireAt: expiredAt}
+	actualSession := models.Session{ID: uuid.New(), UserID: active.ID, RefreshTokenHash: "actual", ExpireAt: expireAt}
+	for _, session := range []*models.Session{&expiredSession, &actualSession} {
+		require.NoError(t, storage.CreateNewSession(ctx, session))
+	}
+
+	// Set recorder of audit events of the deleted accounts.
+	audit.SetRecorder(&audit.LogRecorder{})
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Run all janitors of SQLite storage (they are local, SQLite has no advisory locks).
+	janitors := NewJanitors(storage, database.NopUserCache{})
+	names := make([]string, 0, len(janitors))
+	for _, janitor := range janitors {
+		names = append(names, janitor.Name)
+		assert.True(t, janitor.Local, "need to run %s without advisory lock", janitor.Name)
+		require.NoError(t, janitor.Run(ctx), janitor.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"janitor_activation_codes", "janitor_reset_codes", "janitor_sessions", "account_deletion",
+	}, names, "need to purge only tables of SQLite storage")
+
+	// Only expired codes and sessions and accounts after the grace period are deleted.
+	_, _, err = storage.GetActivationCode(ctx, "expired")
+	assert.Error(t, err, "need to delete expired activation code")
+	_, _, err = storage.GetActivationCode(ctx, "actual")
+	assert.NoError(t, err, "need to keep actual activation code")
+	_, _, err = storage.GetResetCode(ctx, "expired")
+	assert.Error(t, err, "need to delete expired reset code")
+	_, _, err = storage.GetResetCode(ctx, "actual")
+	assert.NoError(t, err, "need to keep actual reset code")
+	_, _, err = storage.GetResetCode(ctx, "leaving")
+	assert.Error(t, err, "need to delete reset code of the deleted account")
+	sessions, _, err := storage.GetSessionsByUserID(ctx, active.ID)
+	require.NoError(t, err)
+	if assert.Len(t, sessions, 1, "need to delete expired session") {
+		assert.Equal(t, actualSession.ID, sessions[0].ID, "need to keep actual session")
+	}
+	_, _, err = storage.GetUserByID(ctx, leaving.ID)
+	assert.Error(t, err, "need to delete account after the grace period")
+	_, _, err = storage.GetUserByID(ctx, active.ID)
+	assert.NoError(t, err, "need to keep active account")
+	assert.Equal(t, int64(1), Metrics()["account_deletion"].Deleted, "need to count deleted accounts")
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"sync"
+	"time"
+)
+
+// JobMetrics struct to describe metrics of the background job (since the app start).
+type JobMetrics struct {
+	Runs         int64      `json:"runs"`          // count of the finished runs
+	Skips        int64      `json:"skips"`         // count of the runs, skipped because job is running by another instance
+	Failures     int64      `json:"failures"`      // count of the failed runs
+	Deleted      int64      `json:"deleted"`       // count of the deleted rows
+	LastRunAt    *time.Time `json:"last_run_at"`   // nil == job was not run yet
+	LastDuration string     `json:"last_duration"` // duration of the last finished run
+	LastError    string     `json:"last_error"`    // empty == last run is succeeded
+}
+
+// metrics is a registry of the metrics of all background jobs (by job name).
+var metrics = struct {
+	sync.Mutex
+	jobs map[string]*JobMetrics
+}{jobs: map[string]*JobMetrics{}}
+
+// Metrics func for getting copy of the metrics of all background jobs (by job name).
+func Metrics() map[string]JobMetrics {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	jobs := make(map[string]JobMetrics, len(metrics.jobs))
+	for name, job := range metrics.jobs {
+		jobs[name] = *job
+	}
+
+	return jobs
+}
+
+// recordRun func for updating metrics of the job after its run.
+func recordRun(name string, startedAt time.Time, isSkipped bool, deleted int64, err error) {
+	metrics.Lock()
+	defer metrics.Unlock()
+
+	// Get metrics of the job (or create a new one).
+	job, ok := metrics.jobs[name]
+	if !ok {
+		job = &JobMetrics{}
+		metrics.jobs[name] = job
+	}
+
+	// Checking, if job is skipped (nothing else is changed).
+	if isSkipped {
+		job.Skips++
+		return
+	}
+
+	// Update metrics of the finished run.
+	job.Runs++
+	job.Deleted += deleted
+	job.LastRunAt = &startedAt
+	job.LastDuration = time.Since(startedAt).String()
+	job.LastError = ""
+	if err != nil {
+		job.Failures++
+		job.LastError = err.Error()
+	}
+}
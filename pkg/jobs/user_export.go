@@ -0,0 +1,162 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/export"
+	"Komentory/auth/pkg/mailer"
+	"Komentory/auth/platform/database"
+
+	"github.com/google/uuid"
+)
+
+// ErrUserExportPending is returned, if the previous export of the user is not built yet.
+var ErrUserExportPending = errors.New("previous export is still pending")
+
+// UserExport struct to describe background job for building archive with personal data of the user.
+// Archive is stored in database, so it can be downloaded from any app instance.
+type UserExport struct {
+	DB      *database.Queries
+	ID      uuid.UUID
+	UserID  uuid.UUID
+	LinkTTL time.Duration
+}
+
+// NewUserExport func for create a new user data export job.
+func NewUserExport(db *database.Queries, userID uuid.UUID, linkTTL time.Duration) *UserExport {
+	return &UserExport{
+		DB:      db,
+		ID:      uuid.New(),
+		UserID:  userID,
+		LinkTTL: linkTTL,
+	}
+}
+
+// Start method to run the job in background (once). Only one export of the user is built at the same time,
+// pending export is stored in database without archive (ErrUserExportPending is returned, if it exists).
+func (j *UserExport) Start(ctx context.Context) error {
+	// Mark export of the user as pending (export of the crashed app is removed by janitor after link lifetime).
+	isCreated, err := j.DB.CreateNewUserExport(ctx, &models.UserExportArchive{
+		ID:       j.ID,
+		UserID:   j.UserID,
+		ExpireAt: time.Now().Add(j.LinkTTL),
+	})
+	if err != nil {
+		return err
+	}
+	if !isCreated {
+		return ErrUserExportPending
+	}
+
+	go func() {
+		// Errors are only logged, failed export is deleted, so user can request a new one.
+		// Job outlives the request, so it runs without request context (only with query timeout).
+		if err := j.Run(context.Background()); err != nil {
+			log.Printf("Oops... User data export %s is not built! Reason: %v", j.ID, err)
+			if err := j.DB.DeleteUserExport(context.Background(), j.ID); err != nil {
+				log.Printf("Oops... User data export %s is not deleted! Reason: %v", j.ID, err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Run method to build archive with personal data of the user and send download link to the user email.
+func (j *UserExport) Run(ctx context.Context) error {
+	// Collect all personal data of the user.
+	data, err := j.collect(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Build archive in memory.
+	archive := &bytes.Buffer{}
+	if err := export.WriteArchive(archive, data); err != nil {
+		return err
+	}
+
+	// Save archive with the same lifetime as the download link (link is sent only for the saved archive).
+	expireAt := time.Now().Add(j.LinkTTL)
+	if err := j.DB.SetUserExportArchive(ctx, j.ID, archive.Bytes(), expireAt); err != nil {
+		return err
+	}
+
+	// Make signed download link.
+	expires := expireAt.Unix()
+	link := fmt.Sprintf(
+		"%s/v1/user/export/%s?expires=%d&signature=%s",
+		os.Getenv("USER_EXPORT_LINK_BASE_URL"), j.ID, expires, export.Sign(configs.UserExportLinkSecretKey(), j.ID.String(), expires),
+	)
+
+	// Send download link to the user.
+	if err := mailer.Send(&mailer.Message{
+		To:       data.User.Email,
+		Subject:  "Your personal data is ready",
+		TextBody: "Download archive with your personal data (valid for " + j.LinkTTL.String() + "): " + link,
+	}); err != nil {
+		return err
+	}
+
+	// Record audit event.
+	audit.System(audit.EventUserExportReady, j.UserID)
+
+	return nil
+}
+
+// collect method to get all personal data of the user from database.
+func (j *UserExport) collect(ctx context.Context) (*models.UserExport, error) {
+	// Get user by ID.
+	user, _, err := j.DB.GetUserByID(ctx, j.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get sessions of the user.
+	sessions, _, err := j.DB.GetSessionsByUserID(ctx, j.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all audit events of the user (page by page).
+	securityLog := []models.AuditEvent{}
+	for filter := (&models.AuditEventsFilter{Page: 1, Limit: 100}); ; filter.Page++ {
+		events, _, err := j.DB.GetAuditEventsByUserID(ctx, j.UserID, filter)
+		if err != nil {
+			return nil, err
+		}
+		securityLog = append(securityLog, events...)
+		if len(events) < filter.Limit {
+			break
+		}
+	}
+
+	// Get personal access tokens of the user.
+	personalAccessTokens, _, err := j.DB.GetPersonalAccessTokensByUserID(ctx, j.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get email changes of the user.
+	emailChanges, _, err := j.DB.GetEmailChangesByUserID(ctx, j.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.UserExport{
+		User:                 user,
+		Sessions:             sessions,
+		SecurityLog:          securityLog,
+		PersonalAccessTokens: personalAccessTokens,
+		EmailChanges:         emailChanges,
+	}, nil
+}
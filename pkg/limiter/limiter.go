@@ -0,0 +1,99 @@
+package limiter
+
+import (
+	"time"
+)
+
+// timeNow func for getting current time (replaced in tests to control time).
+var timeNow = time.Now
+
+// Rule struct to describe limit of hits per sliding window.
+type Rule struct {
+	Limit  int
+	Window time.Duration
+}
+
+// Limiter struct to describe sliding window limiter over the given store.
+type Limiter struct {
+	store Store
+}
+
+// New func for create a new limiter with the given store.
+func New(store Store) *Limiter {
+	return &Limiter{store: store}
+}
+
+// Hit method to add a new hit for the key and return count of hits inside the window.
+func (l *Limiter) Hit(key string, rule Rule) (int, error) {
+	// Add a new hit.
+	hits, err := l.add(key, rule, timeNow())
+	if err != nil {
+		return 0, err
+	}
+
+	return len(hits), nil
+}
+
+// Remove method to remove the hit of the key, which was added at the given time.
+func (l *Limiter) Remove(key string, at time.Time) error {
+	return l.store.Remove(key, at)
+}
+
+// add method to add a new hit for the key at the given time and return all hits inside the window
+// (including hits, which were added at the same time by the parallel requests).
+func (l *Limiter) add(key string, rule Rule, at time.Time) ([]time.Time, error) {
+	// Add a new hit.
+	if err := l.store.Add(key, at, rule.Window); err != nil {
+		return nil, err
+	}
+
+	// Get all hits inside the window.
+	return l.store.Hits(key, at.Add(-rule.Window))
+}
+
+// RetryAfter method to get duration, after which the next hit for the key will be allowed
+// (0, if limit of the rule is not reached yet).
+func (l *Limiter) RetryAfter(key string, rule Rule) (time.Duration, error) {
+	// Get now time.
+	now := timeNow()
+
+	// Get all hits inside the window.
+	hits, err := l.store.Hits(key, now.Add(-rule.Window))
+	if err != nil {
+		return 0, err
+	}
+
+	// Checking, if limit of the rule is disabled or not reached.
+	if rule.Limit <= 0 || len(hits) < rule.Limit {
+		return 0, nil
+	}
+
+	// Next hit will be allowed, when the oldest hits will leave the window.
+	return hits[len(hits)-rule.Limit].Add(rule.Window).Sub(now), nil
+}
+
+// Last method to get time of the last hit for the key inside the window (zero time, if there are no hits).
+func (l *Limiter) Last(key string, rule Rule) (time.Time, int, error) {
+	// Get all hits inside the window.
+	hits, err := l.store.Hits(key, timeNow().Add(-rule.Window))
+	if err != nil || len(hits) == 0 {
+		return time.Time{}, 0, err
+	}
+
+	return hits[len(hits)-1], len(hits), nil
+}
+
+// Reset method to remove all hits of the key.
+func (l *Limiter) Reset(key string) error {
+	return l.store.Reset(key)
+}
+
+// Lock method to lock the key for the given duration.
+func (l *Limiter) Lock(key string, duration time.Duration) error {
+	return l.store.Lock(key, duration)
+}
+
+// LockedFor method to get remaining duration of the lock (0, if key is not locked).
+func (l *Limiter) LockedFor(key string) (time.Duration, error) {
+	return l.store.LockedFor(key)
+}
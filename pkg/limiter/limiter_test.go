@@ -0,0 +1,210 @@
+package limiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setClock func for replacing current time of the limiter with the controlled one.
+// It returns func to move the time forward.
+func setClock(t *testing.T) func(d time.Duration) {
+	current := time.Date(2021, 11, 1, 12, 0, 0, 0, time.UTC)
+	timeNow = func() time.Time { return current }
+	t.Cleanup(func() { timeNow = time.Now })
+
+	return func(d time.Duration) { current = current.Add(d) }
+}
+
+func TestLimiterSlidingWindow(t *testing.T) {
+	advance := setClock(t)
+	limiter := New(NewMemoryStore())
+	rule := Rule{Limit: 3, Window: time.Minute}
+
+	// Define a structure for specifying steps of the test case.
+	steps := []struct {
+		description        string
+		advance            time.Duration
+		expectedHits       int
+		expectedRetryAfter time.Duration
+	}{
+		{"count the first hit", 0, 1, 0},
+		{"count the second hit", 10 * time.Second, 2, 0},
+		{"wait for the oldest hit after limit is reached", 10 * time.Second, 3, 40 * time.Second},
+		{"forget hits outside of the window", 41 * time.Second, 3, 9 * time.Second},
+	}
+
+	for _, step := range steps {
+		advance(step.advance)
+
+		hits, err := limiter.Hit("key", rule)
+		require.NoError(t, err)
+		assert.Equal(t, step.expectedHits, hits, "need to %s (hits)", step.description)
+
+		retryAfter, err := limiter.RetryAfter("key", rule)
+		require.NoError(t, err)
+		assert.Equal(t, step.expectedRetryAfter, retryAfter, "need to %s (retry after)", step.description)
+	}
+
+	// Limit is disabled, if it's not positive.
+	retryAfter, err := limiter.RetryAfter("key", Rule{Limit: 0, Window: time.Minute})
+	require.NoError(t, err)
+	assert.Zero(t, retryAfter, "need to allow hits without limit")
+
+	// Reset removes all hits of the key.
+	require.NoError(t, limiter.Reset("key"))
+	_, count, err := limiter.Last("key", rule)
+	require.NoError(t, err)
+	assert.Zero(t, count, "need to remove hits by reset")
+}
+
+// failLogin func for registering failed login attempt, which must be allowed.
+// It returns true, if account was locked by this attempt.
+func failLogin(t *testing.T, guard *LoginGuard, ip, email string) bool {
+	attempt, retryAfter, err := guard.Attempt(ip, email)
+	require.NoError(t, err)
+	require.Zero(t, retryAfter, "need to allow login attempt")
+
+	isLocked, err := attempt.Fail()
+	require.NoError(t, err)
+
+	return isLocked
+}
+
+func TestLoginGuardProgressiveDelay(t *testing.T) {
+	advance := setClock(t)
+	guard := NewLoginGuard(New(NewMemoryStore()), LoginConfig{
+		ByIP:         Rule{Limit: 100, Window: time.Hour},
+		ByEmail:      Rule{Limit: 100, Window: time.Hour},
+		ByIPAndEmail: Rule{Limit: 100, Window: time.Hour},
+		DelayAfter:   2,
+		Delay:        time.Second,
+		MaxDelay:     4 * time.Second,
+	})
+
+	// Define a structure for specifying steps of the test case (allowed attempts are failed).
+	steps := []struct {
+		description   string
+		advance       time.Duration
+		expectedDelay time.Duration
+	}{
+		{"allow the first attempt", 0, 0},
+		{"allow the second attempt", 0, 0},
+		{"delay attempt after 2 failures", 0, time.Second},
+		{"allow attempt after the delay", time.Second, 0},
+		{"double delay after 3 failures", 0, 2 * time.Second},
+		{"allow attempt after the doubled delay", 2 * time.Second, 0},
+		{"double delay after 4 failures", 0, 4 * time.Second},
+		{"allow attempt after the max delay", 4 * time.Second, 0},
+		{"cap delay by max delay", 0, 4 * time.Second},
+	}
+
+	for _, step := range steps {
+		advance(step.advance)
+
+		attempt, delay, err := guard.Attempt("127.0.0.1", "user@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, step.expectedDelay, delay, "need to %s", step.description)
+		assert.Equal(t, step.expectedDelay == 0, attempt != nil, "need to %s (attempt)", step.description)
+		if attempt != nil {
+			_, err := attempt.Fail()
+			require.NoError(t, err)
+		}
+	}
+
+	// Other IP address has no delay.
+	attempt, delay, err := guard.Attempt("10.0.0.1", "user@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, delay, "need to not delay login from other IP address")
+	_, err = attempt.Fail()
+	require.NoError(t, err)
+
+	// Login is allowed after the delay (email is normalized).
+	advance(4 * time.Second)
+	_, delay, err = guard.Attempt("127.0.0.1", " User@Example.com ")
+	require.NoError(t, err)
+	assert.Zero(t, delay, "need to allow login after the delay")
+}
+
+func TestLoginGuardLockoutAndReset(t *testing.T) {
+	advance := setClock(t)
+	guard := NewLoginGuard(New(NewMemoryStore()), LoginConfig{
+		ByIP:            Rule{Limit: 4, Window: time.Hour},
+		ByEmail:         Rule{Limit: 100, Window: time.Hour},
+		ByIPAndEmail:    Rule{Limit: 100, Window: time.Hour},
+		DelayAfter:      100,
+		LockoutAfter:    3,
+		LockoutDuration: 10 * time.Minute,
+	})
+
+	// Account is locked by the third failure.
+	for i, expectedLocked := range []bool{false, false, true} {
+		isLocked := failLogin(t, guard, "127.0.0.1", "user@example.com")
+		assert.Equal(t, expectedLocked, isLocked, "need to lock account after %d failures", i+1)
+	}
+	attempt, lockedFor, err := guard.Attempt("10.0.0.1", "user@example.com")
+	require.NoError(t, err)
+	assert.Nil(t, attempt, "need to refuse login to locked account")
+	assert.Equal(t, 10*time.Minute, lockedFor, "need to refuse login to locked account from any IP address")
+
+	// Account is unlocked after lockout duration.
+	advance(10*time.Minute + time.Second)
+	attempt, lockedFor, err = guard.Attempt("127.0.0.1", "user@example.com")
+	require.NoError(t, err)
+	assert.Zero(t, lockedFor, "need to unlock account after lockout duration")
+
+	// Successful login forgets failures of the email, but not of the IP address
+	// (successful attempt itself is not counted).
+	require.NoError(t, attempt.Succeed())
+	failLogin(t, guard, "127.0.0.1", "other@example.com")
+	_, retryAfter, err := guard.Attempt("127.0.0.1", "third@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Minute-time.Second, retryAfter, "need to limit failures by IP address after success")
+
+	// Refused attempt is not counted.
+	_, retryAfter, err = guard.Attempt("127.0.0.1", "third@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, 50*time.Minute-time.Second, retryAfter, "need to not count refused attempts")
+}
+
+func TestLoginGuardParallelAttempts(t *testing.T) {
+	setClock(t)
+	guard := NewLoginGuard(New(NewMemoryStore()), LoginConfig{
+		ByIP:            Rule{Limit: 100, Window: time.Hour},
+		ByEmail:         Rule{Limit: 100, Window: time.Hour},
+		ByIPAndEmail:    Rule{Limit: 3, Window: time.Hour},
+		DelayAfter:      100,
+		LockoutAfter:    100,
+		LockoutDuration: 10 * time.Minute,
+	})
+
+	// Run parallel attempts, which are not finished yet (password is checking).
+	var wg sync.WaitGroup
+	allowed := make(chan *LoginAttempt, 20)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			attempt, _, err := guard.Attempt("127.0.0.1", "user@example.com")
+			assert.NoError(t, err)
+			if attempt != nil {
+				allowed <- attempt
+			}
+		}()
+	}
+	wg.Wait()
+	close(allowed)
+
+	// Only attempts inside the limit are allowed.
+	assert.Len(t, allowed, 3, "need to limit parallel attempts")
+	for attempt := range allowed {
+		_, err := attempt.Fail()
+		require.NoError(t, err)
+	}
+	_, retryAfter, err := guard.Attempt("127.0.0.1", "user@example.com")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, retryAfter, "need to refuse attempt after parallel failures")
+}
@@ -0,0 +1,196 @@
+package limiter
+
+import (
+	"strings"
+	"time"
+)
+
+// LoginConfig struct to describe limits for the login attempts (only failed and not finished attempts are counted).
+type LoginConfig struct {
+	ByIP            Rule          // failed attempts from one IP address (for any email)
+	ByEmail         Rule          // failed attempts for one email (from any IP address)
+	ByIPAndEmail    Rule          // failed attempts for one email from one IP address
+	DelayAfter      int           // count of failed attempts, after which delay is required
+	Delay           time.Duration // first delay, each next failed attempt doubles it
+	MaxDelay        time.Duration // max delay between attempts
+	LockoutAfter    int           // count of failed attempts for one email, after which account is locked
+	LockoutDuration time.Duration // duration of the account lockout
+}
+
+// LoginGuard struct to describe brute-force protection for the login attempts.
+type LoginGuard struct {
+	limiter *Limiter
+	config  LoginConfig
+}
+
+// NewLoginGuard func for create a new login guard with the given limiter and config.
+func NewLoginGuard(limiter *Limiter, config LoginConfig) *LoginGuard {
+	return &LoginGuard{
+		limiter: limiter,
+		config:  config,
+	}
+}
+
+// LoginAttempt struct to describe login attempt, which is registered before checking of the password
+// (it's counted as failed, until Succeed is called).
+type LoginAttempt struct {
+	guard         *LoginGuard
+	ipKey         string
+	emailKey      string
+	ipAndEmailKey string
+	emailFailures int
+	at            time.Time
+}
+
+// Attempt method to register login attempt for the given IP address and email before checking of the password,
+// so parallel attempts are counted by each other and can't bypass the limits. If attempt is refused,
+// it's not counted and duration is returned, after which the next attempt will be allowed.
+func (g *LoginGuard) Attempt(ip, email string) (*LoginAttempt, time.Duration, error) {
+	// Define keys for the given IP address and email.
+	ipKey, emailKey, ipAndEmailKey := loginKeys(ip, email)
+	attempt := &LoginAttempt{guard: g, ipKey: ipKey, emailKey: emailKey, ipAndEmailKey: ipAndEmailKey, at: timeNow()}
+
+	// Checking, if account is locked.
+	lockedFor, err := g.limiter.LockedFor(emailKey)
+	if err != nil || lockedFor > 0 {
+		return nil, lockedFor, err
+	}
+
+	// Register attempt for each key and check limits (with this attempt).
+	retryAfter := time.Duration(0)
+	previousFailures := []time.Time{}
+	for _, limit := range []struct {
+		key  string
+		rule Rule
+	}{
+		{ipKey, g.config.ByIP},
+		{emailKey, g.config.ByEmail},
+		{ipAndEmailKey, g.config.ByIPAndEmail},
+	} {
+		hits, err := g.limiter.add(limit.key, limit.rule, attempt.at)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		// Next attempt will be allowed, when the oldest attempts will leave the window.
+		if limit.rule.Limit > 0 && len(hits) > limit.rule.Limit {
+			if duration := hits[len(hits)-1-limit.rule.Limit].Add(limit.rule.Window).Sub(attempt.at); duration > retryAfter {
+				retryAfter = duration
+			}
+		}
+
+		// Save failed attempts for the email and for the IP address with email (without this attempt).
+		switch limit.key {
+		case emailKey:
+			attempt.emailFailures = len(hits)
+		case ipAndEmailKey:
+			previousFailures = withoutHit(hits, attempt.at)
+		}
+	}
+
+	// Checking, if progressive delay after the last failed attempt is not over.
+	if duration := g.delay(previousFailures, attempt.at); duration > retryAfter {
+		retryAfter = duration
+	}
+
+	// Refused attempt is not counted.
+	if retryAfter > 0 {
+		if err := attempt.remove(ipKey, emailKey, ipAndEmailKey); err != nil {
+			return nil, 0, err
+		}
+		return nil, retryAfter, nil
+	}
+
+	return attempt, 0, nil
+}
+
+// Fail method to leave login attempt counted as failed.
+// Returns true, if account was locked by this attempt.
+func (a *LoginAttempt) Fail() (bool, error) {
+	// Checking, if count of failed attempts for the email reached lockout.
+	config := a.guard.config
+	if config.LockoutAfter <= 0 || a.emailFailures < config.LockoutAfter {
+		return false, nil
+	}
+
+	// Lock account and start counting of the failed attempts from the beginning.
+	if err := a.guard.limiter.Lock(a.emailKey, config.LockoutDuration); err != nil {
+		return false, err
+	}
+	if err := a.guard.limiter.Reset(a.emailKey); err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Succeed method to register successful login attempt (failed attempts for the email are forgotten,
+// but not for the IP address, this attempt is removed from them).
+func (a *LoginAttempt) Succeed() error {
+	// Remove this attempt from the IP address.
+	if err := a.remove(a.ipKey); err != nil {
+		return err
+	}
+
+	// Reset failed attempts.
+	if err := a.guard.limiter.Reset(a.emailKey); err != nil {
+		return err
+	}
+
+	return a.guard.limiter.Reset(a.ipAndEmailKey)
+}
+
+// remove method to remove this attempt from the given keys.
+func (a *LoginAttempt) remove(keys ...string) error {
+	for _, key := range keys {
+		if err := a.guard.limiter.Remove(key, a.at); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// delay method to get remaining progressive delay after the last of the given failed attempts
+// (0, if delay is not required or is over).
+func (g *LoginGuard) delay(failures []time.Time, now time.Time) time.Duration {
+	// Checking, if delay is required.
+	failuresCount := len(failures)
+	if failuresCount == 0 || failuresCount < g.config.DelayAfter {
+		return 0
+	}
+
+	// Calculate progressive delay (doubled for each next failed attempt).
+	delay := g.config.Delay
+	for i := g.config.DelayAfter; i < failuresCount && delay < g.config.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > g.config.MaxDelay {
+		delay = g.config.MaxDelay
+	}
+
+	// Checking, if delay after the last failed attempt is not over.
+	if remaining := failures[failuresCount-1].Add(delay).Sub(now); remaining > 0 {
+		return remaining
+	}
+
+	return 0
+}
+
+// withoutHit func for getting hits without the one, which was added at the given time.
+func withoutHit(hits []time.Time, at time.Time) []time.Time {
+	for i, hit := range hits {
+		if hit.Equal(at) {
+			return append(append([]time.Time{}, hits[:i]...), hits[i+1:]...)
+		}
+	}
+
+	return hits
+}
+
+func loginKeys(ip, email string) (string, string, string) {
+	// Normalize email to prevent bypassing of the limits.
+	email = strings.ToLower(strings.TrimSpace(email))
+
+	return "login:ip:" + ip, "login:email:" + email, "login:ip_email:" + ip + ":" + email
+}
@@ -0,0 +1,154 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+// memoryEntry struct to describe hits of the one key in memory.
+type memoryEntry struct {
+	hits     []time.Time
+	expireAt time.Time
+}
+
+// MemoryStore struct to describe in-memory store for a single instance of the app.
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	locks     map[string]time.Time
+	cleanupAt time.Time
+}
+
+// NewMemoryStore func for create a new in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		entries: map[string]*memoryEntry{},
+		locks:   map[string]time.Time{},
+	}
+}
+
+// Add method to add a new hit for the key and remove hits, which are older than window.
+func (s *MemoryStore) Add(key string, at time.Time, window time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Remove expired entries from time to time.
+	s.cleanup(at)
+
+	// Get or create entry for the key.
+	entry, ok := s.entries[key]
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	// Leave only hits inside the window.
+	hits := entry.hits[:0]
+	for _, hit := range entry.hits {
+		if hit.After(at.Add(-window)) {
+			hits = append(hits, hit)
+		}
+	}
+
+	// Add a new hit.
+	entry.hits = append(hits, at)
+	entry.expireAt = at.Add(window)
+
+	return nil
+}
+
+// Hits method to get all hits of the key after the given time (sorted by time).
+func (s *MemoryStore) Hits(key string, since time.Time) ([]time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Collect hits after the given time.
+	hits := []time.Time{}
+	if entry, ok := s.entries[key]; ok {
+		for _, hit := range entry.hits {
+			if hit.After(since) {
+				hits = append(hits, hit)
+			}
+		}
+	}
+
+	return hits, nil
+}
+
+// Remove method to remove one hit of the key, which was added at the given time.
+func (s *MemoryStore) Remove(key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Remove the first hit with the given time.
+	if entry, ok := s.entries[key]; ok {
+		for i, hit := range entry.hits {
+			if hit.Equal(at) {
+				entry.hits = append(entry.hits[:i], entry.hits[i+1:]...)
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// Reset method to remove all hits of the key.
+func (s *MemoryStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.entries, key)
+
+	return nil
+}
+
+// Lock method to lock the key for the given duration.
+func (s *MemoryStore) Lock(key string, duration time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[key] = timeNow().Add(duration)
+
+	return nil
+}
+
+// LockedFor method to get remaining duration of the lock (0, if key is not locked).
+func (s *MemoryStore) LockedFor(key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Checking, if lock is exists and not expired.
+	lockedUntil, ok := s.locks[key]
+	if !ok {
+		return 0, nil
+	}
+	if remaining := lockedUntil.Sub(timeNow()); remaining > 0 {
+		return remaining, nil
+	}
+
+	// Remove expired lock.
+	delete(s.locks, key)
+
+	return 0, nil
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	// Run cleanup not often than once per minute.
+	if now.Before(s.cleanupAt) {
+		return
+	}
+	s.cleanupAt = now.Add(time.Minute)
+
+	// Remove expired entries and locks.
+	for key, entry := range s.entries {
+		if now.After(entry.expireAt) {
+			delete(s.entries, key)
+		}
+	}
+	for key, lockedUntil := range s.locks {
+		if now.After(lockedUntil) {
+			delete(s.locks, key)
+		}
+	}
+}
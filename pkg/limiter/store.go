@@ -0,0 +1,24 @@
+package limiter
+
+import "time"
+
+// Store interface to describe storage of the hits for sliding windows and locks.
+type Store interface {
+	// Add method to add a new hit for the key and remove hits, which are older than window.
+	Add(key string, at time.Time, window time.Duration) error
+
+	// Hits method to get all hits of the key after the given time (sorted by time).
+	Hits(key string, since time.Time) ([]time.Time, error)
+
+	// Remove method to remove one hit of the key, which was added at the given time.
+	Remove(key string, at time.Time) error
+
+	// Reset method to remove all hits of the key.
+	Reset(key string) error
+
+	// Lock method to lock the key for the given duration.
+	Lock(key string, duration time.Duration) error
+
+	// LockedFor method to get remaining duration of the lock (0, if key is not locked).
+	LockedFor(key string) (time.Duration, error)
+}
@@ -0,0 +1,110 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+)
+
+// Message struct to describe email message.
+type Message struct {
+	To       string
+	Subject  string
+	TextBody string
+}
+
+// Sender interface to describe service for sending emails.
+type Sender interface {
+	Send(message *Message) error
+}
+
+// LogSender struct to describe sender, which only writes emails to the log with codes and links
+// (used only, if it's selected by MAILER=log or for development with STAGE_STATUS=dev).
+type LogSender struct{}
+
+// Send method to write the given email to the log.
+func (s *LogSender) Send(message *Message) error {
+	log.Printf("Email to %s: %s\n%s", message.To, message.Subject, message.TextBody)
+	return nil
+}
+
+var (
+	sender     Sender
+	senderOnce sync.Once
+)
+
+// SetSender func for replacing the default sender.
+func SetSender(s Sender) {
+	senderOnce.Do(func() {})
+	sender = s
+}
+
+// NewSender func for creating sender from .env file, selected by MAILER ("postmark" or "log").
+// By default, Postmark is used, if POSTMARK_SERVER_TOKEN is set, and log only for development.
+// Otherwise error is returned, because emails with codes and links must not be written to the log.
+func NewSender() (Sender, error) {
+	// Define kind of the sender.
+	kind := os.Getenv("MAILER")
+	if kind == "" {
+		switch {
+		case os.Getenv("POSTMARK_SERVER_TOKEN") != "":
+			kind = "postmark"
+		case os.Getenv("STAGE_STATUS") == "dev":
+			kind = "log"
+		}
+	}
+
+	switch kind {
+	case "postmark":
+		// Checking, if Postmark is configured.
+		token := os.Getenv("POSTMARK_SERVER_TOKEN")
+		if token == "" {
+			return nil, fmt.Errorf("POSTMARK_SERVER_TOKEN is not set for postmark mailer")
+		}
+		return NewPostmarkSender(token, os.Getenv("POSTMARK_FROM_EMAIL")), nil
+	case "log":
+		return &LogSender{}, nil
+	case "":
+		return nil, fmt.Errorf("email service is not configured (set POSTMARK_SERVER_TOKEN, or MAILER=log to write emails to the log)")
+	default:
+		return nil, fmt.Errorf("unknown mailer: %s", kind)
+	}
+}
+
+// Init func for setting the default sender from .env file (must be called on the app start,
+// so the app fails to start without email service).
+func Init() error {
+	s, err := NewSender()
+	if err != nil {
+		return err
+	}
+
+	SetSender(s)
+
+	return nil
+}
+
+// Send func for sending the given email with the default sender (from .env file, if it's not set).
+func Send(message *Message) error {
+	// Define default sender from .env file on first use.
+	senderOnce.Do(func() {
+		s, err := NewSender()
+		if err != nil {
+			s = &failingSender{err: err}
+		}
+		sender = s
+	})
+
+	return sender.Send(message)
+}
+
+// failingSender struct to describe sender, which fails each email with the given error.
+type failingSender struct {
+	err error
+}
+
+// Send method to fail sending the given email.
+func (s *failingSender) Send(message *Message) error {
+	return s.err
+}
@@ -0,0 +1,64 @@
+package mailer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// PostmarkSender struct to describe sender via Postmark API.
+// See: https://postmarkapp.com/developer/api/email-api
+type PostmarkSender struct {
+	serverToken string
+	from        string
+	client      *http.Client
+}
+
+// NewPostmarkSender func for create a new Postmark sender with the given server token and sender email.
+func NewPostmarkSender(serverToken, from string) *PostmarkSender {
+	return &PostmarkSender{
+		serverToken: serverToken,
+		from:        from,
+		client:      &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send method to send the given email via Postmark API.
+func (s *PostmarkSender) Send(message *Message) error {
+	// Create JSON body of the request.
+	body, err := json.Marshal(map[string]string{
+		"From":          s.from,
+		"To":            message.To,
+		"Subject":       message.Subject,
+		"TextBody":      message.TextBody,
+		"MessageStream": "outbound",
+	})
+	if err != nil {
+		return err
+	}
+
+	// Create a new request to Postmark API.
+	req, err := http.NewRequest(http.MethodPost, "https://api.postmarkapp.com/email", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Postmark-Server-Token", s.serverToken)
+
+	// Send request.
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// Checking, if email was accepted by Postmark.
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("postmark returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
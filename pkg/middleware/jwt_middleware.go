@@ -1,23 +1,117 @@
 package middleware
 
 import (
+	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v4"
 
 	jwtMiddleware "github.com/gofiber/jwt/v2"
 )
 
 // JWTProtected func for specify routes group with JWT authentication.
+// Personal access tokens are accepted too (exchanged to a short-lived JWT),
+// but service client tokens are not (all of these routes are user-only).
 // See: https://github.com/gofiber/jwt
-func JWTProtected() func(*fiber.Ctx) error {
+func JWTProtected(db *database.Queries) func(*fiber.Ctx) error {
 	// Create config for JWT authentication middleware.
 	config := jwtMiddleware.Config{
-		SigningKey:   []byte(os.Getenv("JWT_SECRET_KEY")),
-		ContextKey:   "jwt", // used in private routes
+		SigningKey: []byte(os.Getenv("JWT_SECRET_KEY")),
+		ContextKey: "jwt", // used in private routes
+		SuccessHandler: func(c *fiber.Ctx) error {
+			return jwtUserOnly(c, db)
+		},
 		ErrorHandler: jwtError,
 	}
-	return jwtMiddleware.New(config)
+
+	// Create JWT authentication middleware.
+	jwtHandler := jwtMiddleware.New(config)
+
+	return func(c *fiber.Ctx) error {
+		// Exchange personal access token, if it was given.
+		if err := exchangePersonalAccessToken(c, db); err != nil {
+			return jwtError(c, err)
+		}
+
+		return jwtHandler(c)
+	}
+}
+
+// SessionOnly func for specify routes, which can't be accessed with personal access tokens
+// or impersonation tokens (for example, routes to change password or manage tokens).
+func SessionOnly() func(*fiber.Ctx) error {
+	return func(c *fiber.Ctx) error {
+		// Get parsed JWT from JWTProtected middleware.
+		token, ok := c.Locals("jwt").(*jwt.Token)
+		if !ok {
+			return utilities.ThrowJSONError(c, 401, "token", "token is missing")
+		}
+
+		// Checking, if token was exchanged from personal access token.
+		if claims, ok := token.Claims.(jwt.MapClaims); ok {
+			if _, isPersonalAccessToken := claims["pat"]; isPersonalAccessToken {
+				return utilities.ThrowJSONError(c, 403, "route", "personal access token is not allowed")
+			}
+			if _, isImpersonationToken := claims["act"]; isImpersonationToken {
+				return utilities.ThrowJSONError(c, 403, "route", "impersonation token is not allowed")
+			}
+		}
+
+		return c.Next()
+	}
+}
+
+func jwtUserOnly(c *fiber.Ctx, db *database.Queries) error {
+	// Get parsed JWT.
+	token, ok := c.Locals("jwt").(*jwt.Token)
+	if !ok {
+		return utilities.ThrowJSONError(c, 401, "token", "token is missing")
+	}
+
+	// Checking, if token was issued for the user (not for the service client).
+	if claims, ok := token.Claims.(jwt.MapClaims); ok {
+		if _, isClientToken := claims["client_id"]; isClientToken {
+			return utilities.ThrowJSONError(c, 403, "route", "service client token is not allowed")
+		}
+		if _, isUserToken := claims["id"].(string); !isUserToken {
+			return utilities.ThrowJSONError(c, 401, "token", "user ID is missing")
+		}
+		if _, isImpersonationToken := claims["imp"]; isImpersonationToken {
+			if err := checkImpersonation(c, db); err != nil {
+				return jwtError(c, err)
+			}
+		}
+	}
+
+	return c.Next()
+}
+
+func checkImpersonation(c *fiber.Ctx, db *database.Queries) error {
+	// Get impersonation ID from the current token.
+	impersonationID, err := helpers.ExtractImpersonationID(c)
+	if err != nil {
+		return err
+	}
+
+	// Get impersonation by ID.
+	foundedImpersonation, _, err := db.GetImpersonationByID(c.Context(), impersonationID)
+	if err != nil {
+		return fmt.Errorf("impersonation token is not valid")
+	}
+
+	// Checking, if impersonation was ended by admin.
+	if foundedImpersonation.EndedAt != nil {
+		return fmt.Errorf("impersonation was ended")
+	}
+
+	return nil
 }
 
 func jwtError(c *fiber.Ctx, err error) error {
@@ -35,3 +129,72 @@ func jwtError(c *fiber.Ctx, err error) error {
 		"msg":    err.Error(),
 	})
 }
+
+func exchangePersonalAccessToken(c *fiber.Ctx, db *database.Queries) error {
+	// Get token from Authorization HTTP header.
+	token := strings.TrimPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+
+	// Skip, if it's not a personal access token.
+	if !helpers.IsPersonalAccessToken(token) {
+		return nil
+	}
+
+	// Get personal access token by hash.
+	foundedToken, _, err := db.GetPersonalAccessTokenByHash(c.Context(), helpers.HashToken(token))
+	if err != nil {
+		return fmt.Errorf("personal access token is not valid")
+	}
+
+	// Checking, if personal access token was expired.
+	if foundedToken.ExpireAt != nil && time.Now().After(*foundedToken.ExpireAt) {
+		return fmt.Errorf("personal access token was expired")
+	}
+
+	// Get owner of the personal access token.
+	foundedUser, _, err := db.GetUserByID(c.Context(), foundedToken.UserID)
+	if err != nil || foundedUser.UserStatus != 1 {
+		return fmt.Errorf("personal access token is not valid")
+	}
+
+	// Checking, if account of the owner is pending deletion.
+	if foundedUser.DeletionRequestedAt != nil {
+		return fmt.Errorf("personal access token is not valid")
+	}
+
+	// Checking, if owner must change compromised password first.
+	if foundedUser.PasswordChangeRequired {
+		return fmt.Errorf("password change is required")
+	}
+
+	// Get actual credentials of the owner (user role may be changed after token creation).
+	userCredentials, err := helpers.GetCredentialsByRole(foundedUser.UserRole)
+	if err != nil {
+		return err
+	}
+
+	// Leave only token credentials, which are still allowed for the owner.
+	credentials := []string{}
+	for _, credential := range foundedToken.Credentials {
+		if utilities.SearchStringInArray(credential, userCredentials) {
+			credentials = append(credentials, credential)
+		}
+	}
+
+	// Update last usage time of the personal access token.
+	if err := db.UpdatePersonalAccessTokenLastUsedAt(c.Context(), foundedToken.ID); err != nil {
+		return err
+	}
+
+	// Generate a new short-lived access token for the current request.
+	accessToken, err := helpers.GenerateNewPersonalAccessJWT(
+		foundedUser.ID.String(), foundedToken.ID.String(), credentials,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Replace personal access token to the access token.
+	c.Request().Header.Set(fiber.HeaderAuthorization, "Bearer "+accessToken)
+
+	return nil
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+)
+
+// SupportedBy func for specify routes, which need the given storage. If the storage is not implemented
+// by the selected database driver (like queries for SQLite), status 501 is returned with the reason,
+// instead of failing with 400 on the first query.
+func SupportedBy(storage interface{}) func(*fiber.Ctx) error {
+	// Checking, if storage is implemented (only once, on the routes definition).
+	var err error
+	if storage, ok := storage.(interface{ Unsupported() error }); ok {
+		err = storage.Unsupported()
+	}
+
+	return func(c *fiber.Ctx) error {
+		if err != nil {
+			// Return status 501 with the reason (utilities have no message for this status).
+			return c.Status(fiber.StatusNotImplemented).JSON(fiber.Map{
+				"status": fiber.StatusNotImplemented,
+				"msg":    err.Error(),
+			})
+		}
+
+		return c.Next()
+	}
+}
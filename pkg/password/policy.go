@@ -0,0 +1,107 @@
+package password
+
+import (
+	_ "embed" // embed list of the common passwords
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/nbutton23/zxcvbn-go"
+)
+
+// commonPasswordsList is a list of the most common passwords (one per line, in lower case).
+//
+//go:embed common_passwords.txt
+var commonPasswordsList string
+
+// commonPasswords is a set of the most common passwords for fast checking.
+var commonPasswords = func() map[string]struct{} {
+	passwords := map[string]struct{}{}
+	for _, password := range strings.Split(commonPasswordsList, "\n") {
+		if password = strings.TrimSpace(password); password != "" {
+			passwords[password] = struct{}{}
+		}
+	}
+	return passwords
+}()
+
+// maxScoredLength is a max count of the first characters of the password, which are scored by strength
+// (scoring time grows fast with length, and a longer prefix doesn't change the score of a good password).
+const maxScoredLength = 100
+
+// Policy struct to describe rules for the user passwords.
+type Policy struct {
+	MinLength int // min count of characters
+	MaxLength int // max count of characters
+	MinScore  int // min strength score from 0 (too guessable) to 4 (very unguessable)
+}
+
+// Check method to check the given password by all rules of the policy.
+// User inputs (email, first and last names) are not allowed inside the password.
+// Returns list of the broken rules (empty, if password is good).
+func (p *Policy) Check(password string, userInputs ...string) []string {
+	// Define list of the broken rules.
+	violations := []string{}
+
+	// Checking length of the password.
+	length := utf8.RuneCountInString(password)
+	if length < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters long", p.MaxLength))
+	}
+
+	// Checking, if password is one of the most common passwords.
+	lowerPassword := strings.ToLower(password)
+	if _, isCommon := commonPasswords[lowerPassword]; isCommon {
+		violations = append(violations, "is too common")
+	}
+
+	// Checking, if password contains email or names of the user.
+	inputs := splitUserInputs(userInputs)
+	for _, input := range inputs {
+		if strings.Contains(lowerPassword, input) {
+			violations = append(violations, "must not contain your email or name")
+			break
+		}
+	}
+
+	// Checking strength of the password (with user inputs as a dictionary), only its first characters are scored.
+	scoredPassword := password
+	if length > maxScoredLength {
+		scoredPassword = string([]rune(password)[:maxScoredLength])
+	}
+	if score := zxcvbn.PasswordStrength(scoredPassword, inputs).Score; score < p.MinScore {
+		violations = append(violations, fmt.Sprintf("is too weak (strength score %d of 4, at least %d is required)", score, p.MinScore))
+	}
+
+	return violations
+}
+
+func splitUserInputs(userInputs []string) []string {
+	// Define list of inputs in lower case.
+	inputs := []string{}
+
+	for _, input := range userInputs {
+		input = strings.ToLower(strings.TrimSpace(input))
+
+		// Add local part of the email, if input is email.
+		if at := strings.LastIndex(input, "@"); at > 0 {
+			inputs = appendUserInput(inputs, input[:at])
+		}
+
+		inputs = appendUserInput(inputs, input)
+	}
+
+	return inputs
+}
+
+func appendUserInput(inputs []string, input string) []string {
+	// Skip too short inputs (like initials), they are matched too often.
+	if utf8.RuneCountInString(input) < 3 {
+		return inputs
+	}
+
+	return append(inputs, input)
+}
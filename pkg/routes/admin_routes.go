@@ -0,0 +1,43 @@
+package routes
+
+import (
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/pkg/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminRoutes func for describe group of admin routes.
+func AdminRoutes(a *fiber.App, h *controllers.Handler) {
+	// Create routes group (only for session tokens, not personal access or impersonation tokens).
+	route := a.Group("/v1/admin", middleware.JWTProtected(h.DB), middleware.SessionOnly())
+
+	// Define middleware for routes, which need storage of PostgreSQL (not supported by SQLite).
+	withDB := middleware.SupportedBy(h.DB)
+
+	// Routes for GET method:
+	route.Get("/roles", withDB, h.GetRoles)              // get all roles with credentials
+	route.Get("/permissions", withDB, h.GetPermissions)  // get all permissions
+	route.Get("/users", withDB, h.GetUsers)              // get list of users (with filter and pagination)
+	route.Get("/users/:id", h.GetUser)                   // get one user by ID
+	route.Get("/audit-events", withDB, h.GetAuditEvents) // get list of audit events (with filter and pagination)
+	route.Get("/jobs", h.GetJobsMetrics)                 // get metrics of the background jobs
+
+	// Routes for POST method:
+	route.Post("/roles", withDB, h.CreateNewRole)                     // create a new role
+	route.Post("/permissions", withDB, h.CreateNewPermission)         // create a new permission
+	route.Post("/users/:id/password/reset", h.ForceUserPasswordReset) // force user password reset
+	route.Post("/users/:id/impersonate", withDB, h.ImpersonateUser)   // impersonate user
+
+	// Routes for PATCH method:
+	route.Patch("/users/:id/status", h.UpdateUserStatus)     // activate, block or unblock user
+	route.Patch("/users/:id/role", withDB, h.UpdateUserRole) // change user role
+
+	// Routes for PUT method:
+	route.Put("/roles/:id/permissions", withDB, h.UpdateRolePermissions) // replace role credentials
+
+	// Routes for DELETE method:
+	route.Delete("/roles/:id", withDB, h.DeleteRole)             // delete role
+	route.Delete("/permissions/:id", withDB, h.DeletePermission) // delete permission
+	route.Delete("/users/:id/sessions", h.DeleteUserSessions)    // revoke all user sessions
+}
@@ -0,0 +1,231 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminRoutes(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define test variables.
+	userCredentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	userToken, _ := helpers.GenerateNewAccessToken(uuid.New().String(), userCredentials)
+	adminID := uuid.New().String()
+	adminToken, _ := helpers.GenerateNewAccessToken(adminID, []string{
+		utilities.GenerateCredential("roles", "manage", false),
+		utilities.GenerateCredential("users", "manage", false),
+		utilities.GenerateCredential("users", "impersonate", false),
+		utilities.GenerateCredential("audit_events", "read", false),
+	})
+	impersonatorToken, _ := helpers.GenerateNewAccessToken(uuid.New().String(), []string{
+		utilities.GenerateCredential("users", "impersonate", false),
+	})
+
+	// Define in-memory storage with another admin (admin can't be impersonated).
+	adminCredentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameAdmin)
+	adminCredentials = append(adminCredentials, utilities.GenerateCredential("roles", "manage", false))
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameAdmin, Name: "admin", Credentials: adminCredentials})
+	otherAdmin := models.User{ID: uuid.New(), Email: "admin@example.com", UserStatus: 1, UserRole: utilities.RoleNameAdmin}
+	_ = repository.CreateNewUser(context.Background(), &otherAdmin)
+	helpers.SetCredentialsRepository(repository)
+	helpers.ResetCredentialsCache()
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer helpers.ResetCredentialsCache()
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	body := map[string]string{
+		"empty":          `{}`,
+		"blocked status": `{"user_status": 2}`,
+		"invalid status": `{"user_status": 5}`,
+	}
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description  string
+		httpMethod   string
+		route        string // input route
+		tokenString  string
+		body         io.Reader
+		expectedCode int
+	}{
+		// Failed test cases:
+		{
+			"fail: get roles without JWT",
+			"GET", "/v1/admin/roles", "", nil,
+			400, // Missing or malformed JWT
+		},
+		{
+			"fail: get roles without admin credentials",
+			"GET", "/v1/admin/roles", userToken, nil,
+			401, // no required credentials
+		},
+		{
+			"fail: create role with empty JSON body",
+			"POST", "/v1/admin/roles", adminToken, bytes.NewBuffer([]byte(body["empty"])),
+			400, // validation errors
+		},
+		{
+			"fail: update role permissions with not valid role ID",
+			"PUT", "/v1/admin/roles/not-valid/permissions", adminToken, bytes.NewBuffer([]byte(body["empty"])),
+			400, // invalid syntax
+		},
+		{
+			"fail: create permission with empty JSON body",
+			"POST", "/v1/admin/permissions", adminToken, bytes.NewBuffer([]byte(body["empty"])),
+			400, // validation errors
+		},
+		{
+			"fail: delete permission with not valid ID",
+			"DELETE", "/v1/admin/permissions/not-valid", adminToken, nil,
+			400, // invalid syntax
+		},
+		{
+			"fail: get users without admin credentials",
+			"GET", "/v1/admin/users", userToken, nil,
+			401, // no required credentials
+		},
+		{
+			"fail: get users with too big limit",
+			"GET", "/v1/admin/users?limit=500", adminToken, nil,
+			400, // validation errors
+		},
+		{
+			"fail: get user with not valid ID",
+			"GET", "/v1/admin/users/not-valid", adminToken, nil,
+			400, // invalid syntax
+		},
+		{
+			"fail: block own user",
+			"PATCH", "/v1/admin/users/" + adminID + "/status", adminToken, bytes.NewBuffer([]byte(body["blocked status"])),
+			403, // can't change own status
+		},
+		{
+			"fail: update user status with not valid status",
+			"PATCH", "/v1/admin/users/" + uuid.New().String() + "/status", adminToken, bytes.NewBuffer([]byte(body["invalid status"])),
+			400, // validation errors
+		},
+		{
+			"fail: update user role with empty JSON body",
+			"PATCH", "/v1/admin/users/" + uuid.New().String() + "/role", adminToken, bytes.NewBuffer([]byte(body["empty"])),
+			400, // validation errors
+		},
+		{
+			"fail: force password reset with not valid user ID",
+			"POST", "/v1/admin/users/not-valid/password/reset", adminToken, nil,
+			400, // invalid syntax
+		},
+		{
+			"fail: revoke user sessions with not valid user ID",
+			"DELETE", "/v1/admin/users/not-valid/sessions", adminToken, nil,
+			400, // invalid syntax
+		},
+		{
+			"fail: impersonate user without admin credentials",
+			"POST", "/v1/admin/users/" + uuid.New().String() + "/impersonate", userToken, nil,
+			401, // no required credentials
+		},
+		{
+			"fail: impersonate own user",
+			"POST", "/v1/admin/users/" + adminID + "/impersonate", adminToken, nil,
+			403, // can't impersonate own user
+		},
+		{
+			"fail: impersonate user with admin credentials",
+			"POST", "/v1/admin/users/" + otherAdmin.ID.String() + "/impersonate", impersonatorToken, nil,
+			403, // can't get admin credentials by impersonation
+		},
+		{
+			"fail: impersonate user with not valid ID",
+			"POST", "/v1/admin/users/not-valid/impersonate", adminToken, nil,
+			400, // invalid syntax
+		},
+		{
+			"fail: get audit events without admin credentials",
+			"GET", "/v1/admin/audit-events", userToken, nil,
+			401, // no required credentials
+		},
+		{
+			"fail: get audit events with not valid actor ID",
+			"GET", "/v1/admin/audit-events?actor_id=not-valid", adminToken, nil,
+			400, // validation errors
+		},
+		{
+			"fail: get jobs metrics without admin credentials",
+			"GET", "/v1/admin/jobs", userToken, nil,
+			401, // no required credentials
+		},
+	}
+
+	// Define Fiber app.
+	app := fiber.New()
+
+	// Define handler with in-memory storage for users, codes and sessions
+	// and lazy database connection pool for the other queries (connected on first query).
+	handler := controllers.NewHandlerWithRepository(
+		database.NewQueries(sqlx.MustOpen("pgx", os.Getenv("DB_URL"))), repository,
+	)
+
+	// Define routes.
+	AdminRoutes(app, handler)
+
+	// Iterate through test single test cases
+	for index, test := range tests {
+		// Create a new http request with the route from the test case.
+		req := httptest.NewRequest(test.httpMethod, test.route, test.body)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", test.tokenString))
+		req.Header.Set("Content-Type", "application/json")
+
+		// Perform the request plain with the app.
+		resp, _ := app.Test(req, -1) // the -1 disables request latency
+
+		// Parse the response body.
+		body, errReadAll := io.ReadAll(resp.Body)
+		if errReadAll != nil {
+			return
+		}
+
+		// Set the response body (JSON) to simple map.
+		var result map[string]interface{}
+		if errUnmarshal := json.Unmarshal(body, &result); errUnmarshal != nil {
+			return
+		}
+
+		// Redefine index of the test case.
+		readableIndex := index + 1
+
+		// Define status & description from the response.
+		status := int(result["status"].(float64))
+		description := fmt.Sprintf(
+			"[%d] need to %s\nreal error output: %s",
+			readableIndex, test.description, result["msg"].(string),
+		)
+
+		// Checking, if the JSON field "status" from the response body has the expected status code.
+		assert.Equalf(t, test.expectedCode, status, description)
+	}
+}
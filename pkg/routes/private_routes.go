@@ -8,12 +8,33 @@ import (
 )
 
 // PrivateRoutes func for describe group of private routes.
-func PrivateRoutes(a *fiber.App) {
+func PrivateRoutes(a *fiber.App, h *controllers.Handler) {
 	// Create routes group.
-	route := a.Group("/v1", middleware.JWTProtected())
+	route := a.Group("/v1", middleware.JWTProtected(h.DB))
+
+	// Define middlewares for routes, which need storage of PostgreSQL (not supported by SQLite).
+	withDB := middleware.SupportedBy(h.DB)
+	withUsernames := middleware.SupportedBy(h.Usernames)
+	withEmailChanges := middleware.SupportedBy(h.EmailChanges)
+
+	// Routes for GET method:
+	route.Get("/user/me", h.GetCurrentUser)                                                // get profile of the current user
+	route.Get("/user/tokens", middleware.SessionOnly(), withDB, h.GetPersonalAccessTokens) // get personal access tokens
+	route.Get("/user/security-log", withDB, h.GetSecurityLog)                              // get own audit events
+
+	// Routes for POST method:
+	route.Post("/user/tokens", middleware.SessionOnly(), withDB, h.CreateNewPersonalAccessToken)         // create a new personal access token
+	route.Post("/user/export", middleware.SessionOnly(), withDB, h.CreateNewUserExport)                  // request export of personal data
+	route.Post("/user/update/email", middleware.SessionOnly(), withEmailChanges, h.CreateNewEmailChange) // request email change
 
 	// Routes for PATCH method:
-	route.Patch("/user/update/attrs", controllers.UpdateUserAttrs)       // update user attributes
-	route.Patch("/user/update/settings", controllers.UpdateUserSettings) // update user settings
-	route.Patch("/user/update/password", controllers.UpdateUserPassword) // update user password
+	route.Patch("/user/update/username", withUsernames, h.UpdateUsername)                // set or change username
+	route.Patch("/user/update/attrs", h.UpdateUserAttrs)                                 // update user attributes
+	route.Patch("/user/update/settings", h.UpdateUserSettings)                           // update user settings
+	route.Patch("/user/update/password", middleware.SessionOnly(), h.UpdateUserPassword) // update user password
+
+	// Routes for DELETE method:
+	route.Delete("/user", middleware.SessionOnly(), h.DeleteUser)                                   // request deletion of user account
+	route.Delete("/user/tokens/:id", middleware.SessionOnly(), withDB, h.DeletePersonalAccessToken) // revoke personal access token
+	route.Delete("/user/impersonation", withDB, h.EndImpersonation)                                 // end current impersonation
 }
@@ -1,19 +1,31 @@
 package routes
 
 import (
-	"Komentory/auth/pkg/helpers"
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/platform/database"
 
 	"github.com/Komentory/utilities"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestPrivateRoutes(t *testing.T) {
@@ -23,7 +35,11 @@ func TestPrivateRoutes(t *testing.T) {
 	}
 
 	// Define test variables.
-	tokens, _ := helpers.GenerateNewTokens(uuid.New().String(), utilities.RoleNameUser)
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	accessToken, _ := helpers.GenerateNewAccessToken(uuid.New().String(), credentials)
+	clientToken, _ := helpers.GenerateNewClientAccessToken("moderation-bot", []string{
+		utilities.GenerateCredential("user_password", "update", true),
+	})
 	body := map[string]string{
 		"empty":     `{}`,
 		"non-empty": `{"first_name": "Bob"}`,
@@ -46,26 +62,92 @@ func TestPrivateRoutes(t *testing.T) {
 		},
 		{
 			"fail: update user attrs without JSON body",
-			"PATCH", "/v1/user/update/attrs", tokens.Access, nil,
+			"PATCH", "/v1/user/update/attrs", accessToken, nil,
 			400, // unexpected end of JSON input
 		},
 		{
 			"fail: update user attrs with empty JSON body",
-			"PATCH", "/v1/user/update/attrs", tokens.Access, bytes.NewBuffer([]byte(body["empty"])),
+			"PATCH", "/v1/user/update/attrs", accessToken, bytes.NewBuffer([]byte(body["empty"])),
 			400, // validation errors
 		},
 		{
 			"fail: update user attrs with JSON body, but user not found in DB",
-			"PATCH", "/v1/user/update/attrs", tokens.Access, bytes.NewBuffer([]byte(body["non-empty"])),
+			"PATCH", "/v1/user/update/attrs", accessToken, bytes.NewBuffer([]byte(body["non-empty"])),
 			404, // sql: no rows in result set
 		},
+		{
+			"fail: create personal access token without JWT",
+			"POST", "/v1/user/tokens", "", nil,
+			400, // Missing or malformed JWT
+		},
+		{
+			"fail: create personal access token with empty JSON body",
+			"POST", "/v1/user/tokens", accessToken, bytes.NewBuffer([]byte(body["empty"])),
+			400, // validation errors
+		},
+		{
+			"fail: get personal access tokens with not valid personal access token",
+			"GET", "/v1/user/tokens", "pat_not-valid", nil,
+			401, // personal access token is not valid
+		},
+		{
+			"fail: revoke personal access token with not valid ID",
+			"DELETE", "/v1/user/tokens/not-valid", accessToken, nil,
+			400, // invalid UUID length
+		},
+		{
+			"fail: update user password with service client token",
+			"PATCH", "/v1/user/update/password", clientToken, nil,
+			403, // service client token is not allowed
+		},
+		{
+			"fail: end impersonation with not impersonation token",
+			"DELETE", "/v1/user/impersonation", accessToken, nil,
+			400, // token is not an impersonation token
+		},
+		{
+			"fail: request email change without needed credential",
+			"POST", "/v1/user/update/email", accessToken, bytes.NewBuffer([]byte(body["empty"])),
+			401, // no credential to update own email
+		},
+		{
+			"fail: update username without needed credential",
+			"PATCH", "/v1/user/update/username", accessToken, bytes.NewBuffer([]byte(body["empty"])),
+			401, // no credential to update own username
+		},
+		{
+			"fail: get current user without JWT",
+			"GET", "/v1/user/me", "", nil,
+			400, // Missing or malformed JWT
+		},
+		{
+			"fail: request user export without needed credential",
+			"POST", "/v1/user/export", accessToken, nil,
+			401, // no credential to export own personal data
+		},
+		{
+			"fail: delete user without needed credential",
+			"DELETE", "/v1/user", accessToken, bytes.NewBuffer([]byte(body["empty"])),
+			401, // no credential to delete own account
+		},
+		{
+			"fail: get security log with too big limit",
+			"GET", "/v1/user/security-log?limit=500", accessToken, nil,
+			400, // validation errors
+		},
 	}
 
 	// Define Fiber app.
 	app := fiber.New()
 
+	// Define handler with in-memory storage for users, codes and sessions
+	// and lazy database connection pool for the other queries (connected on first query).
+	handler := controllers.NewHandlerWithRepository(
+		database.NewQueries(sqlx.MustOpen("pgx", os.Getenv("DB_URL"))), database.NewMemoryRepository(),
+	)
+
 	// Define routes.
-	PrivateRoutes(app)
+	PrivateRoutes(app, handler)
 
 	// Iterate through test single test cases
 	for index, test := range tests {
@@ -103,3 +185,147 @@ func TestPrivateRoutes(t *testing.T) {
 		assert.Equalf(t, test.expectedCode, status, description)
 	}
 }
+
+func TestUsernameRoutes(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with two active users (the first one has username and password).
+	ctx := context.Background()
+	passwordHash, err := helpers.GeneratePasswordHash("purple-Otter-climbs-42-hills")
+	require.NoError(t, err)
+	aliceUsername := "Alice"
+	alice := models.User{
+		ID: uuid.New(), Email: "alice@example.com", PasswordHash: passwordHash,
+		UserStatus: 1, UserRole: utilities.RoleNameUser, Username: &aliceUsername,
+	}
+	bob := models.User{ID: uuid.New(), Email: "bob@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	require.NoError(t, repository.CreateNewUser(ctx, &alice))
+	require.NoError(t, repository.CreateNewUser(ctx, &bob))
+
+	// Define tokens with credential to change own username.
+	usernameCredentials := append(credentials, utilities.GenerateCredential("user_username", "update", true))
+	aliceToken, _ := helpers.GenerateNewAccessToken(alice.ID.String(), usernameCredentials)
+	bobToken, _ := helpers.GenerateNewAccessToken(bob.ID.String(), usernameCredentials)
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app with public and private routes.
+	app := fiber.New()
+	handler := controllers.NewHandlerWithRepository(nil, repository)
+	PublicRoutes(app, handler)
+	PrivateRoutes(app, handler)
+
+	// Taken username is not available in other case.
+	status, _, result := performTokenRequest(t, app, "GET", "/v1/usernames/ALICE/availability", "", "")
+	require.Equal(t, 200, status, "need to check username availability")
+	assert.Equal(t, false, result["available"], "need to report username as taken in other case")
+
+	// Username of another user can't be taken in other case.
+	status, _, _ = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "alice"}`, bobToken)
+	assert.Equal(t, 400, status, "need to fail taking username of another user in other case")
+
+	// Reserved username can't be taken.
+	status, _, _ = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "Support"}`, bobToken)
+	assert.Equal(t, 400, status, "need to fail taking reserved username")
+
+	// The first username can be set at any time.
+	status, _, result = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "bob_one"}`, bobToken)
+	require.Equal(t, 204, status, "need to set the first username (%v)", result["msg"])
+
+	// Public profile is found by username in other case.
+	status, _, _ = performTokenRequest(t, app, "GET", "/v1/usernames/BOB_ONE", "", "")
+	assert.Equal(t, 200, status, "need to get public profile by username in other case")
+
+	// Username can't be changed again before cooldown is over.
+	status, _, _ = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "bob_two"}`, bobToken)
+	assert.Equal(t, 403, status, "need to fail changing username before cooldown is over")
+
+	// Cooldown is enforced by the update of the username too (parallel changes can't pass the check together).
+	status, err = repository.UpdateUserUsername(ctx, bob.ID, "bob_two", time.Now().Add(-time.Hour))
+	assert.Error(t, err, "need to fail updating username before cooldown is over")
+	assert.Equal(t, 403, status, "need to fail updating username before cooldown is over (status)")
+
+	// Username can be changed after cooldown is over.
+	t.Setenv("USERNAME_CHANGE_COOLDOWN_DAYS", "0")
+	status, _, result = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "bob_two"}`, bobToken)
+	require.Equal(t, 204, status, "need to change username after cooldown is over (%v)", result["msg"])
+
+	// Old username is redirected to the actual one (in any case).
+	status, header, _ := performTokenRequest(t, app, "GET", "/v1/usernames/Bob_One", "", "")
+	assert.Equal(t, 301, status, "need to redirect old username")
+	assert.Equal(t, "/v1/usernames/bob_two", header.Get(fiber.HeaderLocation), "need to redirect old username to the actual one")
+
+	// Old username of another user can't be taken.
+	status, _, _ = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "bob_one"}`, aliceToken)
+	assert.Equal(t, 400, status, "need to fail taking old username of another user")
+
+	// Own old username can be taken back (redirect is replaced by the username).
+	status, _, _ = performTokenRequest(t, app, "PATCH", "/v1/user/update/username", `{"username": "Bob_One"}`, bobToken)
+	require.Equal(t, 204, status, "need to take back own old username")
+	status, _, _ = performTokenRequest(t, app, "GET", "/v1/usernames/bob_one", "", "")
+	assert.Equal(t, 200, status, "need to get public profile by the username, which was taken back")
+	status, header, _ = performTokenRequest(t, app, "GET", "/v1/usernames/bob_two", "", "")
+	assert.Equal(t, 301, status, "need to redirect the previous username")
+	assert.Equal(t, "/v1/usernames/Bob_One", header.Get(fiber.HeaderLocation), "need to redirect the previous username to the actual one")
+
+	// User can login by username in any case.
+	status, _, result = performTokenRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "aLiCe",
+		"password": "purple-Otter-climbs-42-hills"
+	}`, "")
+	assert.Equal(t, 200, status, "need to login user by username (%v)", result["msg"])
+	assert.NotEmpty(t, result["jwt"], "need to return access token after login by username")
+
+	// Login by username with wrong password fails as login by email.
+	status, _, _ = performTokenRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "Alice",
+		"password": "wrong-password"
+	}`, "")
+	assert.Equal(t, 403, status, "need to fail login by username with wrong password")
+
+	// Login by unknown username fails as login by unknown email.
+	status, _, _ = performTokenRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "nobody",
+		"password": "purple-Otter-climbs-42-hills"
+	}`, "")
+	assert.Equal(t, 404, status, "need to fail login by unknown username")
+}
+
+// performTokenRequest func for sending request with the given access token to the app
+// and parsing JSON response (if any).
+func performTokenRequest(t *testing.T, app *fiber.App, method, route, body, token string) (int, http.Header, map[string]interface{}) {
+	// Create a new http request with the given body and token.
+	req := httptest.NewRequest(method, route, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	}
+
+	// Perform the request plain with the app.
+	resp, err := app.Test(req, -1) // the -1 disables request latency
+	require.NoError(t, err)
+
+	// Parse the response body (empty for 204 no content and redirects, plain text for other statuses without JSON).
+	result := map[string]interface{}{}
+	if data, _ := io.ReadAll(resp.Body); len(data) > 0 && strings.HasPrefix(resp.Header.Get("Content-Type"), fiber.MIMEApplicationJSON) {
+		require.NoError(t, json.Unmarshal(data, &result))
+	}
+
+	// Define status from the JSON field "status" (errors are returned with 200 OK) or from the response.
+	status := resp.StatusCode
+	if value, ok := result["status"].(float64); ok {
+		status = int(value)
+	}
+
+	return status, resp.Header, result
+}
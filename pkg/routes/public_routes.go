@@ -2,25 +2,40 @@ package routes
 
 import (
 	"Komentory/auth/app/controllers"
+	"Komentory/auth/pkg/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // PublicRoutes func for describe group of public routes.
-func PublicRoutes(a *fiber.App) {
+func PublicRoutes(a *fiber.App, h *controllers.Handler) {
 	// Create routes group.
 	route := a.Group("/v1")
 
+	// Define middlewares for routes, which need storage of PostgreSQL (not supported by SQLite).
+	withDB := middleware.SupportedBy(h.DB)
+	withUsernames := middleware.SupportedBy(h.Usernames)
+	withEmailChanges := middleware.SupportedBy(h.EmailChanges)
+
+	// Routes for GET method:
+	route.Get("/users/:id", h.GetPublicUser)                                                   // get public user profile
+	route.Get("/usernames/:username", withUsernames, h.GetPublicUserByUsername)                // get public user profile by username
+	route.Get("/usernames/:username/availability", withUsernames, h.CheckUsernameAvailability) // check, if username can be taken
+	route.Get("/user/export/:id", withDB, h.GetUserExport)                                     // download personal data by signed link
+
 	// Routes for POST method:
-	route.Post("/user/create", controllers.CreateNewUser)         // create a new user & send activation code
-	route.Post("/user/login", controllers.UserLogin)              // auth, return Access & Refresh tokens
-	route.Post("/token/renew", controllers.RenewTokens)           // renew Access & Refresh tokens
-	route.Post("/password/reset", controllers.CreateNewResetCode) // create a new reset code
+	route.Post("/user/create", h.CreateNewUser)                 // create a new user & send activation code
+	route.Post("/user/login", h.UserLogin)                      // auth, return Access & Refresh tokens
+	route.Post("/token/renew", h.RenewTokens)                   // renew Access & Refresh tokens
+	route.Post("/token/client", withDB, h.CreateNewClientToken) // issue Access token for service client
+	route.Post("/password/reset", h.CreateNewResetCode)         // create a new reset code
 
 	// Routes for PATCH method:
-	route.Patch("/user/activate", controllers.ActivateUser)    // activate user account by code
-	route.Patch("/password/reset", controllers.ApplyResetCode) // apply code for reset password
+	route.Patch("/user/activate", h.ActivateUser)                         // activate user account by code
+	route.Patch("/password/reset", h.ApplyResetCode)                      // apply code for reset password
+	route.Patch("/email/confirm", withEmailChanges, h.ConfirmEmailChange) // confirm email change by code
+	route.Patch("/email/revert", withEmailChanges, h.RevertEmailChange)   // revert email change by code
 
 	// Routes for DELETE method:
-	route.Delete("/user/logout", controllers.UserLogout) // de-authorization user
+	route.Delete("/user/logout", h.UserLogout) // de-authorization user
 }
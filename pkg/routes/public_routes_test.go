@@ -6,9 +6,15 @@ import (
 	"fmt"
 	"io"
 	"net/http/httptest"
+	"os"
 	"testing"
 
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/platform/database"
+
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
 	"github.com/joho/godotenv"
 	"github.com/stretchr/testify/assert"
 )
@@ -23,6 +29,12 @@ func TestPublicRoutes(t *testing.T) {
 	body := map[string]string{
 		"empty":     `{"code": ""}`,
 		"not-empty": `{"code": "123456"}`,
+		"weak password": `{
+			"email": "john@example.com",
+			"password": "john12345",
+			"user_attrs": {"first_name": "John"},
+			"user_settings": {}
+		}`,
 	}
 
 	// Define a structure for specifying input and output data of a single test case.
@@ -34,6 +46,21 @@ func TestPublicRoutes(t *testing.T) {
 		expectedCode int
 	}{
 		// Failed test cases:
+		{
+			"fail: create user with weak password",
+			"POST", "/v1/user/create", bytes.NewBuffer([]byte(body["weak password"])),
+			400, // password is too short, contains name and too weak
+		},
+		{
+			"fail: get public user profile with not valid ID",
+			"GET", "/v1/users/not-valid", nil,
+			400, // invalid UUID length
+		},
+		{
+			"fail: download user export with not valid signature",
+			"GET", "/v1/user/export/" + uuid.New().String() + "?expires=4102444800&signature=not-valid", nil,
+			403, // signature is not valid
+		},
 		{
 			"fail: apply activation code with no JSON body",
 			"PATCH", "/v1/account/activate", nil,
@@ -64,13 +91,29 @@ func TestPublicRoutes(t *testing.T) {
 			"PATCH", "/v1/password/reset", bytes.NewBuffer([]byte(body["not-empty"])),
 			404, // sql: no rows in result set
 		},
+		{
+			"fail: issue client token without JSON body",
+			"POST", "/v1/token/client", nil,
+			400, // unexpected end of JSON input
+		},
+		{
+			"fail: issue client token with empty client credentials in JSON body",
+			"POST", "/v1/token/client", bytes.NewBuffer([]byte(body["empty"])),
+			400, // validation errors
+		},
 	}
 
 	// Define Fiber app.
 	app := fiber.New()
 
+	// Define handler with in-memory storage for users, codes and sessions
+	// and lazy database connection pool for the other queries (connected on first query).
+	handler := controllers.NewHandlerWithRepository(
+		database.NewQueries(sqlx.MustOpen("pgx", os.Getenv("DB_URL"))), database.NewMemoryRepository(),
+	)
+
 	// Define routes.
-	PublicRoutes(app)
+	PublicRoutes(app, handler)
 
 	// Iterate through test single test cases
 	for index, test := range tests {
@@ -0,0 +1,1054 @@
+package routes
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"Komentory/auth/app/controllers"
+	"Komentory/auth/app/models"
+	"Komentory/auth/app/queries"
+	"Komentory/auth/pkg/audit"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/pkg/helpers"
+	"Komentory/auth/pkg/jobs"
+	"Komentory/auth/pkg/mailer"
+	"Komentory/auth/platform/cache"
+	"Komentory/auth/platform/database"
+
+	"github.com/Komentory/utilities"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/joho/godotenv"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// nopRecorder struct to skip audit events in tests.
+type nopRecorder struct{}
+
+// Record method to skip the given audit event.
+func (r *nopRecorder) Record(event *models.AuditEvent) error {
+	return nil
+}
+
+func TestUserFlow(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with the default user role.
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+
+	runUserFlow(t, repository)
+}
+
+func TestUserFlowCached(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with cache of the users (changes must be visible at once).
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+
+	runUserFlow(t, database.NewCachedRepository(repository, cache.NewMemoryStore(100), time.Hour, time.Hour))
+}
+
+func TestCachedUserPassword(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with cache of the users and active user with password.
+	ctx := context.Background()
+	passwordHash, err := helpers.GeneratePasswordHash("purple-Otter-climbs-42-hills")
+	require.NoError(t, err)
+	user := models.User{
+		ID: uuid.New(), Email: "cached@example.com", PasswordHash: passwordHash,
+		UserStatus: 1, UserRole: utilities.RoleNameUser,
+	}
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	require.NoError(t, repository.CreateNewUser(ctx, &user))
+	cached := database.NewCachedRepository(repository, cache.NewMemoryStore(100), time.Hour, time.Hour)
+
+	// Password hash is never kept in the cache, but read from the storage.
+	_, _, err = cached.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	found, _, err := cached.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, user.Email, found.Email, "need to read user from the cache")
+	assert.Empty(t, found.PasswordHash, "need to not cache password hash")
+	foundHash, status, err := cached.GetUserPasswordHash(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 200, status, "need to find password hash")
+	assert.Equal(t, passwordHash, foundHash, "need to read password hash from the storage")
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app with private routes.
+	app := fiber.New()
+	PrivateRoutes(app, controllers.NewHandlerWithRepository(nil, cached))
+	token, _ := helpers.GenerateNewAccessToken(user.ID.String(), credentials)
+
+	// Password of the cached user is compared with hash from the storage.
+	status, _, _ = performTokenRequest(
+		t, app, "PATCH", "/v1/user/update/password",
+		`{"old_password": "wrong-Otter-climbs-42-hills", "new_password": "green-Walrus-swims-17-lakes"}`, token,
+	)
+	assert.Equal(t, 403, status, "need to refuse wrong old password")
+	status, _, _ = performTokenRequest(
+		t, app, "PATCH", "/v1/user/update/password",
+		`{"old_password": "purple-Otter-climbs-42-hills", "new_password": "green-Walrus-swims-17-lakes"}`, token,
+	)
+	assert.Equal(t, 204, status, "need to update password of the cached user")
+	foundHash, _, err = cached.GetUserPasswordHash(ctx, user.ID)
+	require.NoError(t, err)
+	isMatched, _ := helpers.ComparePasswordHash(foundHash, "green-Walrus-swims-17-lakes")
+	assert.True(t, isMatched, "need to store hash of the new password")
+}
+
+// laggingReplica struct to describe in-memory read replica, which returns the first read version
+// of the user (later changes are not replicated) and has no codes and sessions.
+type laggingReplica struct {
+	*database.MemoryRepository
+	primary *database.MemoryRepository
+	users   map[string]models.User
+}
+
+// Ping method to check health of the replica.
+func (r *laggingReplica) Ping(ctx context.Context) error {
+	return nil
+}
+
+// GetUserByID method to get the first read version of the user by given ID.
+func (r *laggingReplica) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
+	return r.snapshot(r.primary.GetUserByID(ctx, id))
+}
+
+// GetUserByEmail method to get the first read version of the user by given email.
+func (r *laggingReplica) GetUserByEmail(ctx context.Context, email string) (models.User, int, error) {
+	return r.snapshot(r.primary.GetUserByEmail(ctx, email))
+}
+
+// snapshot method to keep the first read version of the user.
+func (r *laggingReplica) snapshot(user models.User, status int, err error) (models.User, int, error) {
+	if err != nil {
+		return user, status, err
+	}
+	if stale, ok := r.users[user.ID.String()]; ok {
+		return stale, status, nil
+	}
+	r.users[user.ID.String()] = user
+
+	return user, status, nil
+}
+
+func TestUserFlowReplica(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory primary storage and lagging replica (own writes must be read from the primary).
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	primary := database.NewMemoryRepository()
+	primary.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	replicas := database.NewReplicaSet(&laggingReplica{
+		MemoryRepository: database.NewMemoryRepository(),
+		primary:          primary,
+		users:            map[string]models.User{},
+	})
+	defer replicas.Close()
+
+	runUserFlow(t, database.NewReplicatedRepository(primary, replicas, cache.NewMemoryStore(100), time.Minute))
+}
+
+func TestReplicaReadYourWrites(t *testing.T) {
+	// Define in-memory primary storage with a user and lagging replica.
+	ctx := context.Background()
+	primary := database.NewMemoryRepository()
+	user := models.User{ID: uuid.New(), Email: "replica@example.com", UserAttrs: models.UserAttrs{FirstName: "Old"}}
+	require.NoError(t, primary.CreateNewUser(ctx, &user))
+	replicas := database.NewReplicaSet(&laggingReplica{
+		MemoryRepository: database.NewMemoryRepository(),
+		primary:          primary,
+		users:            map[string]models.User{},
+	})
+	defer replicas.Close()
+
+	// Define storages with and without read your writes.
+	sticky := database.NewReplicatedRepository(primary, replicas, cache.NewMemoryStore(100), time.Minute)
+	nonSticky := database.NewReplicatedRepository(primary, replicas, cache.NewMemoryStore(100), 0)
+
+	// Read user from replica (the first version is replicated).
+	found, _, err := sticky.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Old", found.UserAttrs.FirstName, "need to read user from replica")
+
+	// Update user attributes by storage with read your writes.
+	require.NoError(t, sticky.UpdateUserAttrs(ctx, user.ID, &models.UserAttrs{FirstName: "New"}))
+
+	// User is read from the primary after own write, from replica otherwise.
+	found, _, err = sticky.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "New", found.UserAttrs.FirstName, "need to read own write from the primary")
+	found, _, err = sticky.GetUserByEmail(ctx, user.Email)
+	require.NoError(t, err)
+	assert.Equal(t, "New", found.UserAttrs.FirstName, "need to read own write by email from the primary")
+	found, _, err = nonSticky.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Old", found.UserAttrs.FirstName, "need to read stale user from replica without stickiness")
+}
+
+// standbyExecutor struct to describe connection to hot standby, which serves codes
+// of the primary by queries without lock and rejects locking reads (as read-only transaction).
+type standbyExecutor struct {
+	primary *database.MemoryRepository
+	reads   int32
+}
+
+// GetContext method to get one code of the primary.
+func (e *standbyExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if strings.Contains(query, "FOR UPDATE") {
+		return errors.New("cannot execute SELECT FOR UPDATE in a read-only transaction")
+	}
+	atomic.AddInt32(&e.reads, 1)
+
+	var err error
+	switch code := dest.(type) {
+	case *models.ActivationCode:
+		*code, _, err = e.primary.GetActivationCode(ctx, args[0].(string))
+	case *models.ResetCode:
+		*code, _, err = e.primary.GetResetCode(ctx, args[0].(string))
+	default:
+		err = fmt.Errorf("unexpected query: %s", query)
+	}
+
+	return err
+}
+
+// SelectContext method to fail getting many rows.
+func (e *standbyExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return fmt.Errorf("unexpected query: %s", query)
+}
+
+// ExecContext method to reject writes.
+func (e *standbyExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return nil, errors.New("cannot execute query in a read-only transaction")
+}
+
+// standbyReplica struct to describe read replica, which reads codes by the app queries
+// from hot standby (users are not replicated, they are read from the primary).
+type standbyReplica struct {
+	*database.MemoryRepository
+	standby *standbyExecutor
+}
+
+// Ping method to check health of the replica.
+func (r *standbyReplica) Ping(ctx context.Context) error {
+	return nil
+}
+
+// GetActivationCode method to get activation code by the app query.
+func (r *standbyReplica) GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error) {
+	return (&queries.ActivationCodeQueries{Executor: r.standby}).GetActivationCode(ctx, code)
+}
+
+// GetResetCode method to get reset code by the app query.
+func (r *standbyReplica) GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error) {
+	return (&queries.ResetCodeQueries{Executor: r.standby}).GetResetCode(ctx, code)
+}
+
+func TestReplicaCodes(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory primary storage with new and active users, their codes and hot standby replica.
+	ctx := context.Background()
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	primary := database.NewMemoryRepository()
+	primary.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	newUser := models.User{ID: uuid.New(), Email: "new@example.com", UserRole: utilities.RoleNameUser}
+	activeUser := models.User{ID: uuid.New(), Email: "active@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	require.NoError(t, primary.CreateNewUser(ctx, &newUser))
+	require.NoError(t, primary.CreateNewUser(ctx, &activeUser))
+	expireAt := time.Now().Add(time.Hour)
+	require.NoError(t, primary.CreateNewActivationCode(ctx, &models.ActivationCode{Code: "activate", ExpireAt: expireAt, UserID: newUser.ID}))
+	require.NoError(t, primary.CreateNewResetCode(ctx, &models.ResetCode{Code: "reset", ExpireAt: expireAt, Email: activeUser.Email}))
+	standby := &standbyExecutor{primary: primary}
+	replicas := database.NewReplicaSet(&standbyReplica{MemoryRepository: database.NewMemoryRepository(), standby: standby})
+	defer replicas.Close()
+	repository := database.NewReplicatedRepository(primary, replicas, cache.NewMemoryStore(100), time.Minute)
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(primary)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Codes are read from the replica (it stays healthy) and consumed on the primary.
+	status, _, _ := performFlowRequest(t, app, "PATCH", "/v1/user/activate", `{"code": "activate"}`, nil)
+	assert.Equal(t, 200, status, "need to activate user by code from the replica")
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/password/reset", `{"code": "reset"}`, nil)
+	assert.Equal(t, 200, status, "need to reset password by code from the replica")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&standby.reads), "need to read both codes from the healthy replica")
+	_, _, err := primary.GetActivationCode(ctx, "activate")
+	assert.Error(t, err, "need to delete activation code on the primary")
+	_, _, err = primary.GetResetCode(ctx, "reset")
+	assert.Error(t, err, "need to delete reset code on the primary")
+}
+
+func TestUserFlowSQLite(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define SQLite storage in a temporary file.
+	t.Setenv("DB_URL", "file:"+filepath.Join(t.TempDir(), "auth.db"))
+	storage, err := database.OpenSQLiteConnection()
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// Create tables and roles by the embedded migrations.
+	migrator, err := storage.Migrator()
+	require.NoError(t, err)
+	_, err = migrator.Up(context.Background())
+	require.NoError(t, err)
+
+	runUserFlow(t, storage)
+}
+
+func TestSQLiteUnsupportedRoutes(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define SQLite storage in a temporary file.
+	ctx := context.Background()
+	t.Setenv("DB_URL", "file:"+filepath.Join(t.TempDir(), "auth.db"))
+	storage, err := database.OpenSQLiteConnection()
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// Create tables and roles by the embedded migrations.
+	migrator, err := storage.Migrator()
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Define active admin with all credentials.
+	admin := models.User{ID: uuid.New(), Email: "admin@example.com", UserStatus: 1, UserRole: utilities.RoleNameAdmin}
+	require.NoError(t, storage.CreateNewUser(ctx, &admin))
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameAdmin)
+	credentials = append(credentials,
+		utilities.GenerateCredential("user_email", "update", true),
+		utilities.GenerateCredential("user_username", "update", true),
+		utilities.GenerateCredential("user_data", "export", true),
+		utilities.GenerateCredential("users", "manage", false),
+	)
+	token, _ := helpers.GenerateNewAccessToken(admin.ID.String(), credentials)
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(storage)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app with all routes and queries, which are not supported by SQLite.
+	app := fiber.New()
+	handler := controllers.NewHandlerWithRepository(database.NewUnsupportedQueries("sqlite"), storage)
+	PublicRoutes(app, handler)
+	PrivateRoutes(app, handler)
+	AdminRoutes(app, handler)
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		method       string
+		route        string
+		expectedCode int
+	}{
+		{"GET", "/v1/user/me", 200},
+		{"GET", "/v1/admin/users/" + admin.ID.String(), 200},
+		{"GET", "/v1/admin/jobs", 200},
+		{"GET", "/v1/user/tokens", 501},
+		{"POST", "/v1/user/tokens", 501},
+		{"DELETE", "/v1/user/tokens/" + uuid.New().String(), 501},
+		{"GET", "/v1/user/security-log", 501},
+		{"POST", "/v1/user/update/email", 501},
+		{"PATCH", "/v1/email/confirm", 501},
+		{"PATCH", "/v1/email/revert", 501},
+		{"PATCH", "/v1/user/update/username", 501},
+		{"GET", "/v1/usernames/admin", 501},
+		{"GET", "/v1/usernames/admin/availability", 501},
+		{"POST", "/v1/user/export", 501},
+		{"GET", "/v1/user/export/" + uuid.New().String(), 501},
+		{"POST", "/v1/token/client", 501},
+		{"DELETE", "/v1/user/impersonation", 501},
+		{"GET", "/v1/admin/roles", 501},
+		{"GET", "/v1/admin/permissions", 501},
+		{"GET", "/v1/admin/users", 501},
+		{"GET", "/v1/admin/audit-events", 501},
+		{"POST", "/v1/admin/roles", 501},
+		{"POST", "/v1/admin/users/" + admin.ID.String() + "/impersonate", 501},
+		{"PATCH", "/v1/admin/users/" + admin.ID.String() + "/role", 501},
+		{"PUT", "/v1/admin/roles/1/permissions", 501},
+		{"DELETE", "/v1/admin/roles/1", 501},
+	}
+
+	for _, test := range tests {
+		status, _, result := performTokenRequest(t, app, test.method, test.route, "", token)
+		assert.Equal(t, test.expectedCode, status, "need to return %d for %s %s (%v)", test.expectedCode, test.method, test.route, result["msg"])
+		if test.expectedCode == 501 {
+			assert.Contains(t, result["msg"], "PostgreSQL is required", "need to explain, why %s %s is not supported", test.method, test.route)
+		}
+	}
+}
+
+// breachedChecker type to describe checker, which finds the given passwords in data breaches.
+type breachedChecker map[string]int
+
+// Check method to get count of the given password appearances in data breaches.
+func (c breachedChecker) Check(password string) (int, error) {
+	return c[password], nil
+}
+
+func TestBreachedPassword(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Wrong file of the breached passwords is refused on the app start.
+	t.Setenv("BREACHED_PASSWORDS_FILE", filepath.Join(t.TempDir(), "missing.txt"))
+	assert.Error(t, helpers.InitBreachChecker(), "need to refuse missing file of the breached passwords")
+
+	// Define in-memory storage and checker with the breached password.
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	helpers.SetBreachChecker(breachedChecker{"purple-Otter-climbs-42-hills": 3})
+	defer helpers.SetBreachChecker(nil)
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Breached password is refused by the password policy, other one is allowed.
+	status, _, result := performFlowRequest(t, app, "POST", "/v1/user/create", `{
+		"email": "breached@example.com",
+		"password": "purple-Otter-climbs-42-hills",
+		"user_attrs": {"first_name": "Breached"},
+		"user_settings": {}
+	}`, nil)
+	assert.Equal(t, 400, status, "need to refuse breached password")
+	fields, _ := result["fields"].(map[string]interface{})
+	assert.Contains(t, fields["password"], "was found in data breaches", "need to explain, why password is refused")
+	status, _, _ = performFlowRequest(t, app, "POST", "/v1/user/create", `{
+		"email": "breached@example.com",
+		"password": "green-Walrus-swims-17-lakes",
+		"user_attrs": {"first_name": "Breached"},
+		"user_settings": {}
+	}`, nil)
+	assert.Equal(t, 201, status, "need to allow password, which was not found in data breaches")
+}
+
+func TestLoginLimiterStore(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Unknown store is refused on the app start (instead of per-instance memory store).
+	t.Setenv("LIMITER_STORE", "Redis")
+	assert.Error(t, helpers.InitLoginGuard(), "need to refuse unknown limiter store")
+
+	// Unavailable Redis is refused on the app start.
+	t.Setenv("LIMITER_STORE", "redis")
+	t.Setenv("REDIS_URL", "127.0.0.1:1")
+	t.Setenv("REDIS_DB_NUMBER", "0")
+	assert.Error(t, helpers.InitLoginGuard(), "need to refuse unavailable Redis store")
+
+	// Memory store is allowed.
+	t.Setenv("LIMITER_STORE", "memory")
+	assert.NoError(t, helpers.InitLoginGuard(), "need to allow memory store")
+}
+
+func TestUserCacheStore(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+	repository := database.NewMemoryRepository()
+
+	// Unknown store is refused on the app start (instead of per-instance memory store).
+	t.Setenv("USER_CACHE_STORE", "Redis")
+	_, err := database.WithUserCache(repository)
+	assert.Error(t, err, "need to refuse unknown cache store")
+
+	// Unavailable Redis is refused on the app start.
+	t.Setenv("USER_CACHE_STORE", "redis")
+	t.Setenv("REDIS_URL", "127.0.0.1:1")
+	t.Setenv("REDIS_DB_NUMBER", "0")
+	_, err = database.WithUserCache(repository)
+	assert.Error(t, err, "need to refuse unavailable Redis store")
+
+	// Memory store is allowed, cache can be disabled.
+	for _, kind := range []string{"memory", "none"} {
+		t.Setenv("USER_CACHE_STORE", kind)
+		_, err = database.WithUserCache(repository)
+		assert.NoError(t, err, "need to allow %s cache store", kind)
+	}
+}
+
+func TestBlockedUserCodes(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with blocked user, leftover activation and reset codes.
+	ctx := context.Background()
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	user := models.User{ID: uuid.New(), Email: "blocked@example.com", UserStatus: 2, UserRole: utilities.RoleNameUser}
+	require.NoError(t, repository.CreateNewUser(ctx, &user))
+	expireAt := time.Now().Add(time.Hour)
+	require.NoError(t, repository.CreateNewActivationCode(ctx, &models.ActivationCode{Code: "activate", ExpireAt: expireAt, UserID: user.ID}))
+	require.NoError(t, repository.CreateNewResetCode(ctx, &models.ResetCode{Code: "reset", ExpireAt: expireAt, Email: user.Email}))
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Blocked user can't unblock itself by the leftover activation code (and the code is deleted).
+	status, _, _ := performFlowRequest(t, app, "PATCH", "/v1/user/activate", `{"code": "activate"}`, nil)
+	assert.Equal(t, 403, status, "need to fail activation of the blocked user")
+	found, _, err := repository.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.UserStatus, "need to keep user blocked")
+	_, status, _ = repository.GetActivationCode(ctx, "activate")
+	assert.Equal(t, 404, status, "need to delete activation code of the blocked user")
+
+	// Blocked user can't login by reset code.
+	status, cookies, _ := performFlowRequest(t, app, "PATCH", "/v1/password/reset", `{"code": "reset"}`, nil)
+	assert.Equal(t, 403, status, "need to fail password reset of the blocked user")
+	assert.Nil(t, flowCookie(cookies, "refresh_token"), "need to not set refresh token cookie")
+	sessions, _, _ := repository.GetSessionsByUserID(ctx, user.ID)
+	assert.Empty(t, sessions, "need to not create session of the blocked user")
+}
+
+func TestResetCodeCancelsDeletion(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with user, which requested deletion of the account, and reset code.
+	ctx := context.Background()
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	user := models.User{ID: uuid.New(), Email: "leaving@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	require.NoError(t, repository.CreateNewUser(ctx, &user))
+	require.NoError(t, repository.RequestUserDeletion(ctx, user.ID))
+	require.NoError(t, repository.CreateNewResetCode(ctx, &models.ResetCode{Code: "reset", ExpireAt: time.Now().Add(time.Hour), Email: user.Email}))
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Login by reset code cancels deletion of the account (as login by password).
+	status, cookies, _ := performFlowRequest(t, app, "PATCH", "/v1/password/reset", `{"code": "reset"}`, nil)
+	assert.Equal(t, 200, status, "need to reset password of the user")
+	assert.NotNil(t, flowCookie(cookies, "refresh_token"), "need to set refresh token cookie")
+	found, _, err := repository.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.DeletionRequestedAt, "need to cancel deletion of the account")
+}
+
+// failingRepository struct to describe storage, which fails on creating a session in transaction
+// (the last step of the multi-step operations), while failure is enabled.
+type failingRepository struct {
+	database.Repository
+	isFailing *int32
+}
+
+// WithTx method to run the given function in transaction with the failing storage.
+func (r *failingRepository) WithTx(ctx context.Context, fn func(r database.Repository) error) error {
+	return r.Repository.WithTx(ctx, func(tx database.Repository) error {
+		return fn(&failingRepository{Repository: tx, isFailing: r.isFailing})
+	})
+}
+
+// CreateNewSession method to fail on creating a session, while failure is enabled.
+func (r *failingRepository) CreateNewSession(ctx context.Context, s *models.Session) error {
+	if atomic.LoadInt32(r.isFailing) == 1 {
+		return fmt.Errorf("session is not created")
+	}
+
+	return r.Repository.CreateNewSession(ctx, s)
+}
+
+func TestResetCodeTransaction(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with user and reset code.
+	ctx := context.Background()
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	memory := database.NewMemoryRepository()
+	memory.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	user := models.User{ID: uuid.New(), Email: "reset@example.com", PasswordHash: "old-hash", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	require.NoError(t, memory.CreateNewUser(ctx, &user))
+	require.NoError(t, memory.CreateNewResetCode(ctx, &models.ResetCode{Code: "reset", ExpireAt: time.Now().Add(time.Hour), Email: user.Email}))
+
+	// Define storage, which fails on the last step of the password reset.
+	isFailing := int32(1)
+	repository := &failingRepository{Repository: memory, isFailing: &isFailing}
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Failure in the middle of transaction rolls back all previous writes.
+	status, cookies, _ := performFlowRequest(t, app, "PATCH", "/v1/password/reset", `{"code": "reset"}`, nil)
+	assert.Equal(t, 400, status, "need to fail password reset on the failed step")
+	assert.Nil(t, flowCookie(cookies, "refresh_token"), "need to not set refresh token cookie")
+	found, _, err := memory.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "old-hash", found.PasswordHash, "need to roll back password update")
+	_, status, err = memory.GetResetCode(ctx, "reset")
+	assert.NoError(t, err, "need to roll back deletion of the reset code")
+	assert.Equal(t, 200, status, "need to keep reset code after rollback")
+
+	// Reset code is consumed only once, even by concurrent requests.
+	atomic.StoreInt32(&isFailing, 0)
+	var wg sync.WaitGroup
+	statuses := make([]int, 5)
+	for i := range statuses {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			statuses[i], _, _ = performFlowRequest(t, app, "PATCH", "/v1/password/reset", `{"code": "reset"}`, nil)
+		}(i)
+	}
+	wg.Wait()
+	succeeded := 0
+	for _, status := range statuses {
+		if status == 200 {
+			succeeded++
+		} else {
+			assert.Equal(t, 404, status, "need to fail password reset by the consumed code")
+		}
+	}
+	assert.Equal(t, 1, succeeded, "need to apply reset code only once")
+	found, _, err = memory.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotEqual(t, "old-hash", found.PasswordHash, "need to update password by reset code")
+	sessions, _, _ := memory.GetSessionsByUserID(ctx, user.ID)
+	assert.Len(t, sessions, 1, "need to create session only once")
+}
+
+// recordingSender struct to describe sender, which keeps sent emails (to get codes from them).
+type recordingSender struct {
+	sync.Mutex
+	messages []mailer.Message
+}
+
+// Send method to keep the given email.
+func (s *recordingSender) Send(message *mailer.Message) error {
+	s.Lock()
+	defer s.Unlock()
+
+	s.messages = append(s.messages, *message)
+
+	return nil
+}
+
+// code method to get code from the last email to the given address by the given pattern.
+func (s *recordingSender) code(t *testing.T, to string, pattern *regexp.Regexp) string {
+	s.Lock()
+	defer s.Unlock()
+
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].To != to {
+			continue
+		}
+		match := pattern.FindStringSubmatch(s.messages[i].TextBody)
+		require.Len(t, match, 2, "need to send code to %s", to)
+
+		return match[1]
+	}
+	require.Fail(t, "need to send email", "no email to %s", to)
+
+	return ""
+}
+
+func TestEmailChangeFlow(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define in-memory storage with two active users, their sessions and reset code of the first one.
+	ctx := context.Background()
+	passwordHash, err := helpers.GeneratePasswordHash("purple-Otter-climbs-42-hills")
+	require.NoError(t, err)
+	alice := models.User{ID: uuid.New(), Email: "alice@example.com", PasswordHash: passwordHash, UserStatus: 1, UserRole: utilities.RoleNameUser}
+	bob := models.User{ID: uuid.New(), Email: "bob@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	repository := database.NewMemoryRepository()
+	repository.AddRole(models.Role{ID: utilities.RoleNameUser, Name: "user", Credentials: credentials})
+	for _, user := range []*models.User{&alice, &bob} {
+		require.NoError(t, repository.CreateNewUser(ctx, user))
+		require.NoError(t, repository.CreateNewSession(ctx, &models.Session{
+			ID: uuid.New(), UserID: user.ID, RefreshTokenHash: user.Email, ExpireAt: time.Now().Add(time.Hour),
+		}))
+	}
+	require.NoError(t, repository.CreateNewResetCode(ctx, &models.ResetCode{Code: "reset", ExpireAt: time.Now().Add(time.Hour), Email: alice.Email}))
+
+	// Define token with credential to change own email.
+	emailCredentials := append(credentials, utilities.GenerateCredential("user_email", "update", true))
+	aliceToken, _ := helpers.GenerateNewAccessToken(alice.ID.String(), emailCredentials)
+
+	// Set storage for app-wide helpers and sender of the emails with codes.
+	sender := &recordingSender{}
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	mailer.SetSender(sender)
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+	defer mailer.SetSender(&mailer.LogSender{})
+
+	// Define Fiber app with public and private routes.
+	app := fiber.New()
+	handler := controllers.NewHandlerWithRepository(nil, repository)
+	PublicRoutes(app, handler)
+	PrivateRoutes(app, handler)
+
+	// Define func for requesting email change of the first user (it returns confirm and revert codes).
+	confirmCodePattern, revertCodePattern := regexp.MustCompile(`account: (\w+) `), regexp.MustCompile(`\?code=(\w+)`)
+	requestEmailChange := func(newEmail string) (string, string) {
+		body := fmt.Sprintf(`{"password": "purple-Otter-climbs-42-hills", "new_email": %q}`, newEmail)
+		status, _, result := performTokenRequest(t, app, "POST", "/v1/user/update/email", body, aliceToken)
+		require.Equal(t, 201, status, "need to request email change (%v)", result["msg"])
+
+		return sender.code(t, newEmail, confirmCodePattern), sender.code(t, alice.Email, revertCodePattern)
+	}
+
+	// Email of another user can't be requested.
+	status, _, _ := performTokenRequest(t, app, "POST", "/v1/user/update/email",
+		`{"password": "purple-Otter-climbs-42-hills", "new_email": "bob@example.com"}`, aliceToken)
+	assert.Equal(t, 400, status, "need to fail requesting email of another user")
+
+	// Email, which was taken after the request, can't be confirmed (uniqueness is re-checked).
+	takenConfirmCode, _ := requestEmailChange("carol@example.com")
+	carol := models.User{ID: uuid.New(), Email: "carol@example.com", UserStatus: 1, UserRole: utilities.RoleNameUser}
+	require.NoError(t, repository.CreateNewUser(ctx, &carol))
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/email/confirm", fmt.Sprintf(`{"code": %q}`, takenConfirmCode), nil)
+	assert.Equal(t, 400, status, "need to fail confirming email, which was taken after the request")
+	found, _, err := repository.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", found.Email, "need to keep email of the user")
+	emailChanges, _, err := repository.GetEmailChangesByUserID(ctx, alice.ID)
+	require.NoError(t, err)
+	if assert.Len(t, emailChanges, 1, "need to keep email change") {
+		assert.Nil(t, emailChanges[0].ConfirmedAt, "need to not confirm email change")
+	}
+
+	// A new request replaces not confirmed one.
+	confirmCode, revertCode := requestEmailChange("alice.new@example.com")
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/email/confirm", fmt.Sprintf(`{"code": %q}`, takenConfirmCode), nil)
+	assert.Equal(t, 404, status, "need to fail confirming replaced email change")
+
+	// Confirm code replaces email of the user and deletes reset codes of the old email (only once).
+	status, _, result := performFlowRequest(t, app, "PATCH", "/v1/email/confirm", fmt.Sprintf(`{"code": %q}`, confirmCode), nil)
+	require.Equal(t, 204, status, "need to confirm email change (%v)", result["msg"])
+	found, _, err = repository.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice.new@example.com", found.Email, "need to replace email of the user")
+	_, _, err = repository.GetResetCode(ctx, "reset")
+	assert.Error(t, err, "need to delete reset code of the old email")
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/email/confirm", fmt.Sprintf(`{"code": %q}`, confirmCode), nil)
+	assert.Equal(t, 404, status, "need to fail confirming email change twice")
+
+	// Revert code returns the old email and revokes all sessions of the user (only once).
+	status, _, result = performFlowRequest(t, app, "PATCH", "/v1/email/revert", fmt.Sprintf(`{"code": %q}`, revertCode), nil)
+	require.Equal(t, 204, status, "need to revert email change (%v)", result["msg"])
+	found, _, err = repository.GetUserByID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", found.Email, "need to return the old email of the user")
+	sessions, _, err := repository.GetSessionsByUserID(ctx, alice.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions, "need to revoke sessions of the user")
+	sessions, _, err = repository.GetSessionsByUserID(ctx, bob.ID)
+	require.NoError(t, err)
+	assert.Len(t, sessions, 1, "need to keep sessions of another user")
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/email/revert", fmt.Sprintf(`{"code": %q}`, revertCode), nil)
+	assert.Equal(t, 404, status, "need to fail reverting email change twice")
+}
+
+func TestAccountDeletionFlow(t *testing.T) {
+	// Load .env.test file from the root folder
+	if err := godotenv.Load("../../.env.test"); err != nil {
+		panic(err)
+	}
+
+	// Define SQLite storage in a temporary file.
+	ctx := context.Background()
+	t.Setenv("DB_URL", "file:"+filepath.Join(t.TempDir(), "auth.db"))
+	storage, err := database.OpenSQLiteConnection()
+	require.NoError(t, err)
+	defer storage.Close()
+
+	// Create tables and roles by the embedded migrations.
+	migrator, err := storage.Migrator()
+	require.NoError(t, err)
+	_, err = migrator.Up(ctx)
+	require.NoError(t, err)
+
+	// Define active user with password and session.
+	passwordHash, err := helpers.GeneratePasswordHash("purple-Otter-climbs-42-hills")
+	require.NoError(t, err)
+	user := models.User{ID: uuid.New(), Email: "leaving@example.com", PasswordHash: passwordHash, UserStatus: 1, UserRole: utilities.RoleNameUser}
+	require.NoError(t, storage.CreateNewUser(ctx, &user))
+	require.NoError(t, storage.CreateNewSession(ctx, &models.Session{
+		ID: uuid.New(), UserID: user.ID, RefreshTokenHash: "leaving", ExpireAt: time.Now().Add(time.Hour),
+	}))
+
+	// Define token with credential to delete own account.
+	credentials, _ := utilities.GenerateCredentialsByRole(utilities.RoleNameUser)
+	deleteCredentials := append(credentials, utilities.GenerateCredential("user_account", "delete", true))
+	token, _ := helpers.GenerateNewAccessToken(user.ID.String(), deleteCredentials)
+
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(storage)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app with public and private routes.
+	app := fiber.New()
+	handler := controllers.NewHandlerWithRepository(nil, storage)
+	PublicRoutes(app, handler)
+	PrivateRoutes(app, handler)
+
+	// Define job for deleting accounts after the grace period.
+	var deletion *jobs.Janitor
+	for _, janitor := range jobs.NewJanitors(storage, database.NopUserCache{}) {
+		if janitor.Name == "account_deletion" {
+			deletion = janitor
+		}
+	}
+	require.NotNil(t, deletion, "need to define job for deleting accounts")
+	afterGracePeriod := time.Now().Add(configs.AccountDeletionGracePeriod() + time.Hour)
+
+	// Define func for requesting deletion of the account.
+	requestDeletion := func() {
+		status, _, result := performTokenRequest(t, app, "DELETE", "/v1/user", `{"password": "purple-Otter-climbs-42-hills"}`, token)
+		require.Equal(t, 200, status, "need to request deletion of the account (%v)", result["msg"])
+	}
+
+	// Deletion request marks account as pending deletion and revokes its sessions.
+	requestDeletion()
+	found, _, err := storage.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, found.DeletionRequestedAt, "need to mark account as pending deletion")
+	sessions, _, err := storage.GetSessionsByUserID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Empty(t, sessions, "need to revoke sessions of the account")
+
+	// Account is kept during the grace period.
+	deleted, err := deletion.Purge(ctx, time.Now(), deletion.BatchSize)
+	require.NoError(t, err)
+	assert.Zero(t, deleted, "need to keep account during the grace period")
+
+	// Login during the grace period cancels the deletion.
+	status, _, result := performFlowRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "leaving@example.com",
+		"password": "purple-Otter-climbs-42-hills"
+	}`, nil)
+	require.Equal(t, 200, status, "need to login user (%v)", result["msg"])
+	found, _, err = storage.GetUserByID(ctx, user.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.DeletionRequestedAt, "need to cancel deletion of the account by login")
+	deleted, err = deletion.Purge(ctx, afterGracePeriod, deletion.BatchSize)
+	require.NoError(t, err)
+	assert.Zero(t, deleted, "need to keep account after cancelled deletion")
+
+	// Account is deleted after the grace period of the new request (with its sessions).
+	requestDeletion()
+	deleted, err = deletion.Purge(ctx, afterGracePeriod, deletion.BatchSize)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted, "need to delete account after the grace period")
+	_, _, err = storage.GetUserByID(ctx, user.ID)
+	assert.Error(t, err, "need to delete account after the grace period")
+}
+
+// runUserFlow func for checking the whole auth flow of the user with the given storage.
+func runUserFlow(t *testing.T, repository database.Repository) {
+	// Set storage for app-wide helpers.
+	helpers.SetCredentialsRepository(repository)
+	audit.SetRecorder(&nopRecorder{})
+	defer helpers.SetCredentialsRepository(nil)
+	defer audit.SetRecorder(&audit.DatabaseRecorder{})
+
+	// Define Fiber app.
+	app := fiber.New()
+
+	// Define routes.
+	PublicRoutes(app, controllers.NewHandlerWithRepository(nil, repository))
+
+	// Create a new user.
+	status, cookies, result := performFlowRequest(t, app, "POST", "/v1/user/create", `{
+		"email": "flow@example.com",
+		"password": "purple-Otter-climbs-42-hills",
+		"user_attrs": {"first_name": "Flow"},
+		"user_settings": {}
+	}`, nil)
+	require.Equal(t, 201, status, "need to create a new user")
+	activationCode, _ := result["activation_code"].(string)
+	require.NotEmpty(t, activationCode, "need to return activation code")
+
+	// Activate user account by code.
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/user/activate", fmt.Sprintf(`{"code": %q}`, activationCode), nil)
+	require.Equal(t, 200, status, "need to activate user account")
+
+	// Activation code can be applied only once.
+	status, _, _ = performFlowRequest(t, app, "PATCH", "/v1/user/activate", fmt.Sprintf(`{"code": %q}`, activationCode), nil)
+	assert.Equal(t, 404, status, "need to fail on second activation by the same code")
+
+	// Login with wrong password.
+	status, _, _ = performFlowRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "flow@example.com",
+		"password": "wrong-password"
+	}`, nil)
+	assert.Equal(t, 403, status, "need to fail login with wrong password")
+
+	// Login with email and password.
+	status, cookies, result = performFlowRequest(t, app, "POST", "/v1/user/login", `{
+		"email": "flow@example.com",
+		"password": "purple-Otter-climbs-42-hills"
+	}`, nil)
+	require.Equal(t, 200, status, "need to login user")
+	assert.NotEmpty(t, result["jwt"], "need to return access token")
+	refreshToken := flowCookie(cookies, "refresh_token")
+	require.NotNil(t, refreshToken, "need to set refresh token cookie")
+
+	// Renew tokens by refresh token.
+	status, cookies, result = performFlowRequest(t, app, "POST", "/v1/token/renew", "", refreshToken)
+	require.Equal(t, 200, status, "need to renew tokens")
+	assert.NotEmpty(t, result["jwt"], "need to return a new access token")
+	renewedRefreshToken := flowCookie(cookies, "refresh_token")
+	require.NotNil(t, renewedRefreshToken, "need to set a new refresh token cookie")
+	assert.NotEqual(t, refreshToken.Value, renewedRefreshToken.Value, "need to rotate refresh token")
+
+	// Old refresh token can't be used after rotation.
+	status, _, _ = performFlowRequest(t, app, "POST", "/v1/token/renew", "", refreshToken)
+	assert.Equal(t, 401, status, "need to fail renewal by rotated refresh token")
+
+	// Logout ends the session.
+	status, _, _ = performFlowRequest(t, app, "DELETE", "/v1/user/logout", "", renewedRefreshToken)
+	assert.Equal(t, 204, status, "need to logout user")
+	status, _, _ = performFlowRequest(t, app, "POST", "/v1/token/renew", "", renewedRefreshToken)
+	assert.Equal(t, 401, status, "need to fail renewal after logout")
+}
+
+// performFlowRequest func for sending request to the app and parsing JSON response (if any).
+func performFlowRequest(t *testing.T, app *fiber.App, method, route, body string, cookie *http.Cookie) (int, []*http.Cookie, map[string]interface{}) {
+	// Create a new http request with the given body and cookie.
+	req := httptest.NewRequest(method, route, bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	if cookie != nil {
+		req.AddCookie(&http.Cookie{Name: cookie.Name, Value: cookie.Value})
+	}
+
+	// Perform the request plain with the app.
+	resp, err := app.Test(req, -1) // the -1 disables request latency
+	require.NoError(t, err)
+
+	// Parse the response body (empty for 204 no content).
+	result := map[string]interface{}{}
+	if data, _ := io.ReadAll(resp.Body); len(data) > 0 {
+		require.NoError(t, json.Unmarshal(data, &result))
+	}
+
+	// Define status from the JSON field "status" (errors are returned with 200 OK) or from the response.
+	status := resp.StatusCode
+	if value, ok := result["status"].(float64); ok {
+		status = int(value)
+	}
+
+	return status, resp.Cookies(), result
+}
+
+// flowCookie func for getting cookie by name from the response cookies.
+func flowCookie(cookies []*http.Cookie, name string) *http.Cookie {
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie
+		}
+	}
+	return nil
+}
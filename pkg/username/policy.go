@@ -0,0 +1,78 @@
+package username
+
+import (
+	_ "embed" // embed list of the reserved usernames
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// reservedUsernamesList is a list of the usernames, which can't be taken by users (one per line, in lower case).
+//
+//go:embed reserved_usernames.txt
+var reservedUsernamesList string
+
+// reservedUsernames is a set of the reserved usernames for fast checking.
+var reservedUsernames = func() map[string]struct{} {
+	usernames := map[string]struct{}{}
+	for _, username := range strings.Split(reservedUsernamesList, "\n") {
+		if username = strings.TrimSpace(username); username != "" {
+			usernames[username] = struct{}{}
+		}
+	}
+	return usernames
+}()
+
+var (
+	// allowedChars describes allowed charset: latin letters, digits and underscore.
+	allowedChars = regexp.MustCompile(`^[a-zA-Z0-9_]+$`)
+	// startsWithLetter describes, that username must start with a latin letter.
+	startsWithLetter = regexp.MustCompile(`^[a-zA-Z]`)
+)
+
+// Policy struct to describe rules for the usernames.
+type Policy struct {
+	MinLength int // min count of characters
+	MaxLength int // max count of characters
+}
+
+// Check method to check the given username by all rules of the policy.
+// Returns list of the broken rules (empty, if username is good).
+func (p *Policy) Check(username string) []string {
+	// Define list of the broken rules.
+	violations := []string{}
+
+	// Checking length of the username (only ASCII characters are allowed).
+	if len(username) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters long", p.MinLength))
+	}
+	if p.MaxLength > 0 && len(username) > p.MaxLength {
+		violations = append(violations, fmt.Sprintf("must be at most %d characters long", p.MaxLength))
+	}
+
+	// Checking charset of the username.
+	if !allowedChars.MatchString(username) {
+		violations = append(violations, "must contain only latin letters, digits and underscores")
+	}
+	if !startsWithLetter.MatchString(username) {
+		violations = append(violations, "must start with a latin letter")
+	}
+
+	// Checking, if username is reserved.
+	if IsReserved(username) {
+		violations = append(violations, "is reserved")
+	}
+
+	return violations
+}
+
+// IsReserved func for checking, if the given username is reserved (case-insensitive).
+func IsReserved(username string) bool {
+	_, isReserved := reservedUsernames[strings.ToLower(username)]
+	return isReserved
+}
+
+// Normalize func for getting username in form, which is used for uniqueness checks (lower case).
+func Normalize(username string) string {
+	return strings.ToLower(username)
+}
@@ -0,0 +1,43 @@
+package username
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckUsername(t *testing.T) {
+	// Define policy with the default lengths.
+	policy := &Policy{MinLength: 3, MaxLength: 32}
+
+	// Define a structure for specifying input and output data of a single test case.
+	tests := []struct {
+		description        string
+		username           string
+		expectedViolations []string
+	}{
+		{"allow latin letters, digits and underscores", "Bob_Smith_42", []string{}},
+		{"allow username of the min length", "bob", []string{}},
+		{"allow username of the max length", "b" + strings.Repeat("o", 31), []string{}},
+		{"refuse too short username", "bo", []string{"must be at least 3 characters long"}},
+		{"refuse too long username", "b" + strings.Repeat("o", 32), []string{"must be at most 32 characters long"}},
+		{"refuse dash", "bob-smith", []string{"must contain only latin letters, digits and underscores"}},
+		{"refuse not latin letters", "bobé", []string{"must contain only latin letters, digits and underscores"}},
+		{"refuse username, which starts with digit", "42bob", []string{"must start with a latin letter"}},
+		{"refuse username, which starts with underscore", "_bob", []string{"must start with a latin letter"}},
+		{"refuse reserved username", "admin", []string{"is reserved"}},
+		{"refuse reserved username in other case", "AdMiN", []string{"is reserved"}},
+		{
+			"report all broken rules", "_",
+			[]string{
+				"must be at least 3 characters long",
+				"must start with a latin letter",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedViolations, policy.Check(test.username), "need to %s", test.description)
+	}
+}
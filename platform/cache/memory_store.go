@@ -0,0 +1,105 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryEntry struct to describe value of the one key in memory.
+type memoryEntry struct {
+	key      string
+	value    []byte
+	expireAt time.Time
+}
+
+// MemoryStore struct to describe in-memory LRU store for a single instance of the app.
+// The least recently used keys are removed, when store is full.
+type MemoryStore struct {
+	mu      sync.Mutex
+	size    int
+	order   *list.List // the most recently used entry is in front
+	entries map[string]*list.Element
+}
+
+// NewMemoryStore func for create a new in-memory store with the given max count of keys.
+func NewMemoryStore(size int) *MemoryStore {
+	// Store must keep at least one key.
+	if size < 1 {
+		size = 1
+	}
+
+	return &MemoryStore{
+		size:    size,
+		order:   list.New(),
+		entries: map[string]*list.Element{},
+	}
+}
+
+// Get method to get value of the key (false, if key is not found or expired).
+func (s *MemoryStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Checking, if key exists.
+	element, ok := s.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+
+	// Remove expired key.
+	entry := element.Value.(*memoryEntry)
+	if time.Now().After(entry.expireAt) {
+		s.remove(element)
+		return nil, false, nil
+	}
+
+	// Mark key as the most recently used.
+	s.order.MoveToFront(element)
+
+	return entry.value, true, nil
+}
+
+// Set method to set value of the key for the given time to live.
+func (s *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Update value of the existing key.
+	if element, ok := s.entries[key]; ok {
+		element.Value = &memoryEntry{key: key, value: value, expireAt: time.Now().Add(ttl)}
+		s.order.MoveToFront(element)
+		return nil
+	}
+
+	// Remove the least recently used key, if store is full.
+	if s.order.Len() >= s.size {
+		s.remove(s.order.Back())
+	}
+
+	// Add a new key.
+	s.entries[key] = s.order.PushFront(&memoryEntry{key: key, value: value, expireAt: time.Now().Add(ttl)})
+
+	return nil
+}
+
+// Delete method to remove the given keys.
+func (s *MemoryStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys {
+		if element, ok := s.entries[key]; ok {
+			s.remove(element)
+		}
+	}
+
+	return nil
+}
+
+// remove method to remove the given entry from the store.
+func (s *MemoryStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*memoryEntry).key)
+}
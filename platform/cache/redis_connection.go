@@ -0,0 +1,40 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Komentory/utilities/cache"
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	redisClient   *redis.Client
+	redisClientMu sync.Mutex
+)
+
+// RedisConnection func for getting client of the Redis server from .env file, which is shared
+// by all Redis stores of the app. Connection is checked on the first successful call.
+func RedisConnection() (*redis.Client, error) {
+	redisClientMu.Lock()
+	defer redisClientMu.Unlock()
+
+	// Return already connected client.
+	if redisClient != nil {
+		return redisClient, nil
+	}
+
+	// Create Redis client and check connection.
+	client, err := cache.RedisConnection()
+	if err != nil {
+		return nil, err
+	}
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("redis is not available: %w", err)
+	}
+	redisClient = client
+
+	return redisClient, nil
+}
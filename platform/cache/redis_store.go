@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"Komentory/auth/pkg/limiter"
+
+	"github.com/go-redis/redis/v8"
+)
+
+// RedisStore struct to describe Redis store (shared between all instances of the app).
+// It stores cached objects and hits of the limiter with locks.
+type RedisStore struct {
+	client *redis.Client
+	prefix string
+}
+
+// Checking, if Redis store satisfies interfaces of the cache and limiter stores.
+var (
+	_ Store         = (*RedisStore)(nil)
+	_ limiter.Store = (*RedisStore)(nil)
+)
+
+// NewRedisStore func for create a new Redis store with the given client and prefix of the keys.
+func NewRedisStore(client *redis.Client, prefix string) *RedisStore {
+	return &RedisStore{
+		client: client,
+		prefix: prefix,
+	}
+}
+
+// Get method to get value of the key (false, if key is not found or expired).
+func (s *RedisStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	switch err {
+	case nil:
+		return value, true, nil
+	case redis.Nil:
+		return nil, false, nil
+	default:
+		return nil, false, err
+	}
+}
+
+// Set method to set value of the key for the given time to live.
+func (s *RedisStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+key, value, ttl).Err()
+}
+
+// Delete method to remove the given keys.
+func (s *RedisStore) Delete(ctx context.Context, keys ...string) error {
+	// Add prefix to the keys.
+	prefixedKeys := make([]string, 0, len(keys))
+	for _, key := range keys {
+		prefixedKeys = append(prefixedKeys, s.prefix+key)
+	}
+
+	return s.client.Del(ctx, prefixedKeys...).Err()
+}
+
+// Add method to add a new hit for the key and remove hits, which are older than window.
+func (s *RedisStore) Add(key string, at time.Time, window time.Duration) error {
+	// Define context and key of the sorted set.
+	ctx := context.Background()
+	hitsKey := s.prefix + "hits:" + key
+
+	// Define score of the hit (timestamp in nanoseconds).
+	score := at.UnixNano()
+
+	// Send all commands in one transaction.
+	_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.ZRemRangeByScore(ctx, hitsKey, "-inf", strconv.FormatInt(at.Add(-window).UnixNano(), 10))
+		pipe.ZAdd(ctx, hitsKey, &redis.Z{Score: float64(score), Member: strconv.FormatInt(score, 10)})
+		pipe.PExpire(ctx, hitsKey, window)
+		return nil
+	})
+
+	return err
+}
+
+// Hits method to get all hits of the key after the given time (sorted by time).
+func (s *RedisStore) Hits(key string, since time.Time) ([]time.Time, error) {
+	// Define context and key of the sorted set.
+	ctx := context.Background()
+	hitsKey := s.prefix + "hits:" + key
+
+	// Get hits after the given time.
+	members, err := s.client.ZRangeByScore(ctx, hitsKey, &redis.ZRangeBy{
+		Min: fmt.Sprintf("(%d", since.UnixNano()),
+		Max: "+inf",
+	}).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert members (timestamps in nanoseconds) to time.
+	hits := make([]time.Time, 0, len(members))
+	for _, member := range members {
+		nanoseconds, err := strconv.ParseInt(member, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, time.Unix(0, nanoseconds))
+	}
+
+	return hits, nil
+}
+
+// Remove method to remove one hit of the key, which was added at the given time.
+func (s *RedisStore) Remove(key string, at time.Time) error {
+	return s.client.ZRem(context.Background(), s.prefix+"hits:"+key, strconv.FormatInt(at.UnixNano(), 10)).Err()
+}
+
+// Reset method to remove all hits of the key.
+func (s *RedisStore) Reset(key string) error {
+	return s.client.Del(context.Background(), s.prefix+"hits:"+key).Err()
+}
+
+// Lock method to lock the key for the given duration.
+func (s *RedisStore) Lock(key string, duration time.Duration) error {
+	return s.client.Set(context.Background(), s.prefix+"lock:"+key, 1, duration).Err()
+}
+
+// LockedFor method to get remaining duration of the lock (0, if key is not locked).
+func (s *RedisStore) LockedFor(key string) (time.Duration, error) {
+	// Get remaining time to live of the lock.
+	ttl, err := s.client.PTTL(context.Background(), s.prefix+"lock:"+key).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	// Negative TTL means, that key is not exists (or has no expiration).
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return ttl, nil
+}
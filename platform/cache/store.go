@@ -0,0 +1,38 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Store interface to describe key-value storage of the cached objects with expiration.
+type Store interface {
+	// Get method to get value of the key (false, if key is not found or expired).
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// Set method to set value of the key for the given time to live.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// Delete method to remove the given keys.
+	Delete(ctx context.Context, keys ...string) error
+}
+
+// NewStore func for creating store of the given kind: "memory" (with the given max count of keys)
+// or "redis" (for many instances of the app). Unknown kind or unavailable Redis is an error,
+// because memory store of one instance is not shared with other ones.
+func NewStore(kind string, size int) (Store, error) {
+	switch kind {
+	case "", "memory":
+		return NewMemoryStore(size), nil
+	case "redis":
+		// Get shared Redis client.
+		client, err := RedisConnection()
+		if err != nil {
+			return nil, err
+		}
+		return NewRedisStore(client, "cache:"), nil
+	default:
+		return nil, fmt.Errorf("unknown cache store %q (want \"memory\" or \"redis\")", kind)
+	}
+}
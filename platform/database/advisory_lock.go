@@ -0,0 +1,37 @@
+package database
+
+import (
+	"context"
+	"fmt"
+)
+
+// WithAdvisoryLock method to run the given function, only if advisory lock with the given name
+// is acquired (so only one app instance runs it at the same time). The lock is held by a dedicated
+// connection until the function returns. It returns false, if the lock is held by another instance.
+func (q *Queries) WithAdvisoryLock(ctx context.Context, name string, fn func(ctx context.Context) error) (bool, error) {
+	// Checking, if queries have connection pool (locks can't be taken in transaction).
+	if q.db == nil {
+		return false, fmt.Errorf("advisory lock is not supported without connection pool")
+	}
+
+	// Get a dedicated connection from the pool (session lock is bound to it).
+	conn, err := q.db.Connx(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	// Try to acquire the lock without waiting.
+	isLocked := false
+	if err := conn.GetContext(ctx, &isLocked, `SELECT pg_try_advisory_lock(hashtext($1::text))`, name); err != nil {
+		return false, err
+	}
+	if !isLocked {
+		return false, nil
+	}
+
+	// Release the lock, even if request is cancelled (connection is returned to the pool).
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtext($1::text))`, name)
+
+	return true, fn(ctx)
+}
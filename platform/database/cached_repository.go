@@ -0,0 +1,258 @@
+package database
+
+import (
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/platform/cache"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// UserCache interface to describe cache of the users, which must be invalidated
+// after changes of the user outside of the repository (like username or email change).
+type UserCache interface {
+	InvalidateUser(ctx context.Context, id uuid.UUID) error
+}
+
+// NopUserCache struct to describe storage without cache of the users.
+type NopUserCache struct{}
+
+// InvalidateUser method to do nothing (users are not cached).
+func (c NopUserCache) InvalidateUser(ctx context.Context, id uuid.UUID) error {
+	return nil
+}
+
+// CachedRepository struct to describe storage with read-through cache of the users by ID.
+// Not found users are cached too (for a shorter time). Cached user is removed on each change
+// by this storage (in transaction, after its end), so other changes are visible after TTL only.
+// Users are cached without password hash (it's got by GetUserPasswordHash from storage, when it's compared).
+// Memory store is local for each app instance: changes by another instance are visible after TTL only,
+// so Redis store must be used for many instances of the app.
+type CachedRepository struct {
+	Repository
+
+	cache       cache.Store
+	ttl         time.Duration // time to live of the cached user
+	notFoundTTL time.Duration // time to live of the cached not found user
+	changed     *[]uuid.UUID  // IDs of the users, changed in the current transaction (nil outside of it)
+}
+
+// Checking, if cached storage satisfies repository and cache interfaces.
+var (
+	_ Repository = (*CachedRepository)(nil)
+	_ UserCache  = (*CachedRepository)(nil)
+)
+
+// NewCachedRepository func for creating storage with cache of the users in the given store.
+func NewCachedRepository(repository Repository, store cache.Store, ttl, notFoundTTL time.Duration) *CachedRepository {
+	return &CachedRepository{Repository: repository, cache: store, ttl: ttl, notFoundTTL: notFoundTTL}
+}
+
+// WithTx method to run the given function with the storage in one transaction.
+// Users, changed in the transaction, are removed from cache after its end.
+func (r *CachedRepository) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	// Collect changed users to the current transaction, if storage is already in transaction.
+	if r.changed != nil {
+		return r.Repository.WithTx(ctx, func(tx Repository) error {
+			return fn(r.withRepository(tx, r.changed))
+		})
+	}
+
+	// Run function in a new transaction.
+	changed := []uuid.UUID{}
+	err := r.Repository.WithTx(ctx, func(tx Repository) error {
+		return fn(r.withRepository(tx, &changed))
+	})
+
+	// Remove changed users from cache (after commit or rollback).
+	for _, id := range changed {
+		r.deleteUser(id)
+	}
+
+	return err
+}
+
+// WithUserCache func for wrapping the given storage with cache of the users from .env file.
+// Store is defined by USER_CACHE_STORE ("memory" by default, "redis" for many instances of the app,
+// or "none" to disable cache). Memory store of one instance is not invalidated by changes on other ones.
+// Unknown store or unavailable Redis is an error.
+func WithUserCache(repository Repository) (Repository, error) {
+	// Return storage without cache, if it's disabled.
+	kind := configs.UserCacheStore()
+	if kind == "none" {
+		return repository, nil
+	}
+
+	store, err := cache.NewStore(kind, configs.UserCacheSize())
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCachedRepository(repository, store, configs.UserCacheTTL(), configs.UserCacheNotFoundTTL()), nil
+}
+
+// Unwrap method to get storage without cache.
+func (r *CachedRepository) Unwrap() Repository {
+	return r.Repository
+}
+
+// withRepository method to create cached storage in transaction with the given storage.
+func (r *CachedRepository) withRepository(repository Repository, changed *[]uuid.UUID) *CachedRepository {
+	return &CachedRepository{Repository: repository, cache: r.cache, ttl: r.ttl, notFoundTTL: r.notFoundTTL, changed: changed}
+}
+
+// InvalidateUser method to remove user from cache (after changes outside of the storage).
+// Wrapped storage is notified too, if it has own cache of the users.
+func (r *CachedRepository) InvalidateUser(ctx context.Context, id uuid.UUID) error {
+	if userCache, ok := r.Repository.(UserCache); ok {
+		if err := userCache.InvalidateUser(ctx, id); err != nil {
+			return err
+		}
+	}
+
+	return r.cache.Delete(ctx, userCacheKey(id))
+}
+
+// GetUserByID method to get one user by given ID from cache or storage.
+// In transaction, user is always got from storage (with uncommitted changes).
+func (r *CachedRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
+	// Get user from storage in transaction.
+	if r.changed != nil {
+		return r.Repository.GetUserByID(ctx, id)
+	}
+
+	// Get user from cache (errors of cache are only logged, storage is used instead).
+	value, ok, err := r.cache.Get(ctx, userCacheKey(id))
+	if err != nil {
+		log.Printf("Oops... User is not got from cache! Reason: %v", err)
+	}
+	if ok {
+		// Empty value means, that user is not found.
+		if len(value) == 0 {
+			return models.User{}, fiber.StatusNotFound, sql.ErrNoRows
+		}
+
+		user := models.User{}
+		if err := json.Unmarshal(value, &user); err == nil {
+			return user, fiber.StatusOK, nil
+		}
+	}
+
+	// Get user from storage.
+	user, status, err := r.Repository.GetUserByID(ctx, id)
+	switch {
+	case err == nil:
+		// Store found user without password hash for TTL.
+		cachedUser := user
+		cachedUser.PasswordHash = ""
+		if value, err := json.Marshal(cachedUser); err == nil {
+			r.setUser(ctx, id, value, r.ttl)
+		}
+	case status == fiber.StatusNotFound:
+		// Store empty value for not found TTL.
+		r.setUser(ctx, id, []byte{}, r.notFoundTTL)
+	}
+
+	return user, status, err
+}
+
+// CreateNewUser method to store a new user (and remove not found user from cache).
+func (r *CachedRepository) CreateNewUser(ctx context.Context, u *models.User) error {
+	defer r.invalidate(ctx, u.ID)
+	return r.Repository.CreateNewUser(ctx, u)
+}
+
+// UpdateUserAttrs method to update user attributes by given ID.
+func (r *CachedRepository) UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserAttrs(ctx, id, u)
+}
+
+// UpdateUserSettings method to update user settings by given ID.
+func (r *CachedRepository) UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserSettings(ctx, id, u)
+}
+
+// UpdateUserPassword method to update user password by given ID.
+func (r *CachedRepository) UpdateUserPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserPassword(ctx, id, passwordHash)
+}
+
+// UpdateUserPasswordHash method to replace hash of the same password by given ID.
+func (r *CachedRepository) UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserPasswordHash(ctx, id, passwordHash)
+}
+
+// UpdateUserPasswordChangeRequired method to set or reset password change requirement by given ID.
+func (r *CachedRepository) UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserPasswordChangeRequired(ctx, id, required)
+}
+
+// UpdateUserStatus method to update user status by given ID.
+func (r *CachedRepository) UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserStatus(ctx, id, status)
+}
+
+// ActivateUser method to activate user by given ID, only if user is not confirmed yet.
+func (r *CachedRepository) ActivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	defer r.invalidate(ctx, id)
+	return r.Repository.ActivateUser(ctx, id)
+}
+
+// UpdateUserRole method to update user role by given ID.
+func (r *CachedRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.UpdateUserRole(ctx, id, role)
+}
+
+// RequestUserDeletion method to mark user account as pending deletion by given ID.
+func (r *CachedRepository) RequestUserDeletion(ctx context.Context, id uuid.UUID) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.RequestUserDeletion(ctx, id)
+}
+
+// CancelUserDeletion method to cancel pending deletion of user account by given ID.
+func (r *CachedRepository) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	defer r.invalidate(ctx, id)
+	return r.Repository.CancelUserDeletion(ctx, id)
+}
+
+// invalidate method to remove changed user from cache now or after the end of the current transaction.
+func (r *CachedRepository) invalidate(ctx context.Context, id uuid.UUID) {
+	if r.changed != nil {
+		*r.changed = append(*r.changed, id)
+		return
+	}
+
+	r.deleteUser(id)
+}
+
+// setUser method to store user (or empty value for not found user) in cache.
+func (r *CachedRepository) setUser(ctx context.Context, id uuid.UUID, value []byte, ttl time.Duration) {
+	if err := r.cache.Set(ctx, userCacheKey(id), value, ttl); err != nil {
+		log.Printf("Oops... User is not stored in cache! Reason: %v", err)
+	}
+}
+
+// deleteUser method to remove user from cache, even if request is cancelled.
+func (r *CachedRepository) deleteUser(id uuid.UUID) {
+	if err := r.cache.Delete(context.Background(), userCacheKey(id)); err != nil {
+		log.Printf("Oops... User is not removed from cache! Reason: %v", err)
+	}
+}
+
+// userCacheKey func for getting cache key of the user by given ID.
+func userCacheKey(id uuid.UUID) string {
+	return "user:" + id.String()
+}
@@ -0,0 +1,659 @@
+package database
+
+import (
+	"Komentory/auth/app/models"
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// MemoryRepository struct to describe thread-safe in-memory storage of the users, usernames, email changes,
+// codes, sessions and roles credentials (used for tests without PostgreSQL).
+type MemoryRepository struct {
+	sync.RWMutex
+	txMutex           sync.Mutex // transactions are running one by one
+	users             map[uuid.UUID]models.User
+	usernameRedirects map[string]models.UsernameRedirect // by old username in lower case
+	emailChanges      map[uuid.UUID]models.EmailChange
+	activationCodes   map[string]models.ActivationCode
+	resetCodes        map[string]models.ResetCode
+	sessions          map[uuid.UUID]models.Session
+	roles             map[int]models.Role
+}
+
+// Checking, if in-memory storage satisfies all repository interfaces.
+var (
+	_ Repository            = (*MemoryRepository)(nil)
+	_ UsernameRepository    = (*MemoryRepository)(nil)
+	_ EmailChangeRepository = (*MemoryRepository)(nil)
+)
+
+// NewMemoryRepository func for create a new empty in-memory storage.
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{
+		users:             map[uuid.UUID]models.User{},
+		usernameRedirects: map[string]models.UsernameRedirect{},
+		emailChanges:      map[uuid.UUID]models.EmailChange{},
+		activationCodes:   map[string]models.ActivationCode{},
+		resetCodes:        map[string]models.ResetCode{},
+		sessions:          map[uuid.UUID]models.Session{},
+		roles:             map[int]models.Role{},
+	}
+}
+
+// WithTx method to run the given function in transaction.
+// Transactions are running one by one, all changes are discarded, if function returns error.
+func (r *MemoryRepository) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	r.txMutex.Lock()
+	defer r.txMutex.Unlock()
+
+	// Keep copy of the storage to restore it on error.
+	snapshot := r.snapshot()
+
+	// Run function with the storage in transaction.
+	if err := fn(&memoryTx{r}); err != nil {
+		r.Lock()
+		r.users, r.usernameRedirects, r.emailChanges = snapshot.users, snapshot.usernameRedirects, snapshot.emailChanges
+		r.activationCodes, r.resetCodes, r.sessions = snapshot.activationCodes, snapshot.resetCodes, snapshot.sessions
+		r.Unlock()
+
+		return err
+	}
+
+	return nil
+}
+
+// memoryTx struct to describe in-memory storage in transaction.
+type memoryTx struct {
+	*MemoryRepository
+}
+
+// WithTx method to run the given function in the current transaction.
+func (t *memoryTx) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	return fn(t)
+}
+
+// AddRole method to add role with its credentials (instead of roles migrations).
+func (r *MemoryRepository) AddRole(role models.Role) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.roles[role.ID] = role
+}
+
+// GetUserByID method to get one user by given ID.
+func (r *MemoryRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return models.User{}, fiber.StatusNotFound, sql.ErrNoRows
+	}
+
+	return user, fiber.StatusOK, nil
+}
+
+// GetUserByEmail method to get one user by given email.
+func (r *MemoryRepository) GetUserByEmail(ctx context.Context, email string) (models.User, int, error) {
+	return r.findUser(func(u *models.User) bool { return u.Email == email })
+}
+
+// GetUserByUsername method to get one user by given username (case-insensitive).
+func (r *MemoryRepository) GetUserByUsername(ctx context.Context, username string) (models.User, int, error) {
+	return r.findUser(func(u *models.User) bool {
+		return u.Username != nil && strings.EqualFold(*u.Username, username)
+	})
+}
+
+// GetUserPasswordHash method to get password hash of the user by given ID.
+func (r *MemoryRepository) GetUserPasswordHash(ctx context.Context, id uuid.UUID) (string, int, error) {
+	user, status, err := r.GetUserByID(ctx, id)
+	return user.PasswordHash, status, err
+}
+
+// CreateNewUser method to store a new user (email must be unique).
+func (r *MemoryRepository) CreateNewUser(ctx context.Context, u *models.User) error {
+	r.Lock()
+	defer r.Unlock()
+
+	// Checking unique constraints, like in users table.
+	if _, ok := r.users[u.ID]; ok {
+		return fmt.Errorf("user with ID %s already exists", u.ID)
+	}
+	for _, user := range r.users {
+		if user.Email == u.Email {
+			return fmt.Errorf("user with email %s already exists", u.Email)
+		}
+	}
+
+	r.users[u.ID] = *u
+
+	return nil
+}
+
+// UpdateUserAttrs method to update user attributes by given ID.
+func (r *MemoryRepository) UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error {
+	return r.updateUser(id, func(user *models.User) { user.UserAttrs = *u })
+}
+
+// UpdateUserSettings method to update user settings by given ID.
+func (r *MemoryRepository) UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error {
+	return r.updateUser(id, func(user *models.User) { user.UserSettings = *u })
+}
+
+// UpdateUserPassword method to update user password (and reset password change requirement) by given ID.
+func (r *MemoryRepository) UpdateUserPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.updateUser(id, func(user *models.User) {
+		user.PasswordHash = passwordHash
+		user.PasswordChangeRequired = false
+	})
+}
+
+// UpdateUserPasswordHash method to replace hash of the same password by given ID (updated_at is not changed).
+func (r *MemoryRepository) UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	if user, ok := r.users[id]; ok {
+		user.PasswordHash = passwordHash
+		r.users[id] = user
+	}
+
+	return nil
+}
+
+// UpdateUserPasswordChangeRequired method to set or reset password change requirement by given ID.
+func (r *MemoryRepository) UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error {
+	return r.updateUser(id, func(user *models.User) { user.PasswordChangeRequired = required })
+}
+
+// UpdateUserStatus method to update user status by given ID.
+func (r *MemoryRepository) UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error {
+	return r.updateUser(id, func(user *models.User) { user.UserStatus = status })
+}
+
+// ActivateUser method to activate user by given ID, only if user is not confirmed yet (status 0).
+func (r *MemoryRepository) ActivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	user, ok := r.users[id]
+	if !ok || user.UserStatus != 0 {
+		return false, nil
+	}
+
+	now := time.Now()
+	user.UpdatedAt = &now
+	user.UserStatus = 1
+	r.users[id] = user
+
+	return true, nil
+}
+
+// UpdateUserRole method to update user role by given ID.
+func (r *MemoryRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error {
+	return r.updateUser(id, func(user *models.User) { user.UserRole = role })
+}
+
+// RequestUserDeletion method to mark user account as pending deletion by given ID.
+func (r *MemoryRepository) RequestUserDeletion(ctx context.Context, id uuid.UUID) error {
+	return r.updateUser(id, func(user *models.User) { user.DeletionRequestedAt = user.UpdatedAt })
+}
+
+// CancelUserDeletion method to cancel pending deletion of user account by given ID.
+func (r *MemoryRepository) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	return r.updateUser(id, func(user *models.User) { user.DeletionRequestedAt = nil })
+}
+
+// IsUsernameAvailable method to check, if username is not taken (case-insensitive) by another user,
+// including old usernames of other users (uuid.Nil means anyone).
+func (r *MemoryRepository) IsUsernameAvailable(ctx context.Context, username string, userID uuid.UUID) (bool, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	return r.isUsernameAvailable(username, userID), nil
+}
+
+// LockUsername method to do nothing (transactions are running one by one).
+func (r *MemoryRepository) LockUsername(ctx context.Context, username string) error {
+	return nil
+}
+
+// UpdateUserUsername method to change username of the user (old username is kept as redirect to the user),
+// only if it was not changed after the given time.
+func (r *MemoryRepository) UpdateUserUsername(ctx context.Context, id uuid.UUID, username string, changedBefore time.Time) (int, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	// Get user by ID and check cooldown after the last change (nothing is changed, like in UPDATE query).
+	user, ok := r.users[id]
+	if !ok || (user.UsernameChangedAt != nil && user.UsernameChangedAt.After(changedBefore)) {
+		return fiber.StatusForbidden, fmt.Errorf("username was changed recently")
+	}
+
+	// Keep the old username (if it's set) as redirect to the user.
+	now := time.Now()
+	if user.Username != nil && !strings.EqualFold(*user.Username, username) {
+		oldUsername := strings.ToLower(*user.Username)
+		if _, ok := r.usernameRedirects[oldUsername]; !ok {
+			r.usernameRedirects[oldUsername] = models.UsernameRedirect{Username: oldUsername, CreatedAt: &now, UserID: id}
+		}
+	}
+
+	// Delete redirect of the new username, if user takes back own old username.
+	if redirect, ok := r.usernameRedirects[strings.ToLower(username)]; ok && redirect.UserID == id {
+		delete(r.usernameRedirects, strings.ToLower(username))
+	}
+
+	// Update username.
+	user.UpdatedAt, user.Username, user.UsernameChangedAt = &now, &username, &now
+	r.users[id] = user
+
+	return fiber.StatusOK, nil
+}
+
+// GetUsernameRedirect method to get redirect by given old username (case-insensitive).
+func (r *MemoryRepository) GetUsernameRedirect(ctx context.Context, username string) (models.UsernameRedirect, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	usernameRedirect, ok := r.usernameRedirects[strings.ToLower(username)]
+	if !ok {
+		return models.UsernameRedirect{}, fiber.StatusNotFound, sql.ErrNoRows
+	}
+
+	return usernameRedirect, fiber.StatusOK, nil
+}
+
+// GetEmailChangeByConfirmCode method to get email change by given confirm code.
+func (r *MemoryRepository) GetEmailChangeByConfirmCode(ctx context.Context, code string) (models.EmailChange, int, error) {
+	return r.findEmailChange(func(ec *models.EmailChange) bool { return ec.ConfirmCode == code })
+}
+
+// GetEmailChangeByRevertCode method to get email change by given revert code.
+func (r *MemoryRepository) GetEmailChangeByRevertCode(ctx context.Context, code string) (models.EmailChange, int, error) {
+	return r.findEmailChange(func(ec *models.EmailChange) bool { return ec.RevertCode == code })
+}
+
+// GetEmailChangesByUserID method to get all email changes by given user ID (newest first).
+func (r *MemoryRepository) GetEmailChangesByUserID(ctx context.Context, userID uuid.UUID) ([]models.EmailChange, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	emailChanges := []models.EmailChange{}
+	for _, emailChange := range r.emailChanges {
+		if emailChange.UserID == userID {
+			emailChanges = append(emailChanges, emailChange)
+		}
+	}
+
+	// Sort email changes by creation time, like in the query.
+	sort.Slice(emailChanges, func(i, j int) bool {
+		return createdAt(emailChanges[i].CreatedAt).After(createdAt(emailChanges[j].CreatedAt))
+	})
+
+	return emailChanges, fiber.StatusOK, nil
+}
+
+// CreateNewEmailChange method to store a new email change
+// (not confirmed email changes of the user are deleted, like in the query).
+func (r *MemoryRepository) CreateNewEmailChange(ctx context.Context, ec *models.EmailChange) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for id, emailChange := range r.emailChanges {
+		if emailChange.UserID == ec.UserID && emailChange.ConfirmedAt == nil && emailChange.RevertedAt == nil {
+			delete(r.emailChanges, id)
+		}
+	}
+	r.emailChanges[ec.ID] = *ec
+
+	return nil
+}
+
+// MarkEmailChangeConfirmed method to mark email change as confirmed.
+func (r *MemoryRepository) MarkEmailChangeConfirmed(ctx context.Context, id uuid.UUID) error {
+	return r.updateEmailChange(id, func(ec *models.EmailChange, now *time.Time) { ec.ConfirmedAt = now })
+}
+
+// MarkEmailChangeReverted method to mark email change as reverted.
+func (r *MemoryRepository) MarkEmailChangeReverted(ctx context.Context, id uuid.UUID) error {
+	return r.updateEmailChange(id, func(ec *models.EmailChange, now *time.Time) { ec.RevertedAt = now })
+}
+
+// LockEmail method to do nothing (transactions are running one by one).
+func (r *MemoryRepository) LockEmail(ctx context.Context, email string) error {
+	return nil
+}
+
+// UpdateUserEmail method to replace email of the user by the given one (only, if it's not changed again).
+// Returns false, if email of the user is not the given old one.
+func (r *MemoryRepository) UpdateUserEmail(ctx context.Context, id uuid.UUID, oldEmail, newEmail string) (bool, error) {
+	r.Lock()
+	defer r.Unlock()
+
+	// Get user by ID and check the old email (nothing is changed, like in UPDATE query).
+	user, ok := r.users[id]
+	if !ok || user.Email != oldEmail {
+		return false, nil
+	}
+
+	// Checking unique constraint, like in users table.
+	for userID, u := range r.users {
+		if userID != id && u.Email == newEmail {
+			return false, fmt.Errorf("user with email %s already exists", newEmail)
+		}
+	}
+
+	// Replace email of the user.
+	now := time.Now()
+	user.UpdatedAt, user.Email = &now, newEmail
+	r.users[id] = user
+
+	return true, nil
+}
+
+// GetActivationCode method to get activation code by given string.
+func (r *MemoryRepository) GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	activationCode, ok := r.activationCodes[code]
+	if !ok {
+		return models.ActivationCode{}, fiber.StatusNotFound, sql.ErrNoRows
+	}
+
+	return activationCode, fiber.StatusOK, nil
+}
+
+// CreateNewActivationCode method to store a new activation code.
+func (r *MemoryRepository) CreateNewActivationCode(ctx context.Context, ac *models.ActivationCode) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.activationCodes[ac.Code] = *ac
+
+	return nil
+}
+
+// DeleteActivationCode method to delete activation code.
+func (r *MemoryRepository) DeleteActivationCode(ctx context.Context, code string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.activationCodes, code)
+
+	return nil
+}
+
+// GetResetCode method to get reset code by given string.
+func (r *MemoryRepository) GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	resetCode, ok := r.resetCodes[code]
+	if !ok {
+		return models.ResetCode{}, fiber.StatusNotFound, sql.ErrNoRows
+	}
+
+	return resetCode, fiber.StatusOK, nil
+}
+
+// CreateNewResetCode method to store a new reset code.
+func (r *MemoryRepository) CreateNewResetCode(ctx context.Context, rc *models.ResetCode) error {
+	r.Lock()
+	defer r.Unlock()
+
+	r.resetCodes[rc.Code] = *rc
+
+	return nil
+}
+
+// DeleteResetCode method to delete reset code.
+func (r *MemoryRepository) DeleteResetCode(ctx context.Context, code string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.resetCodes, code)
+
+	return nil
+}
+
+// DeleteResetCodesByEmail method to delete all reset codes by given email.
+func (r *MemoryRepository) DeleteResetCodesByEmail(ctx context.Context, email string) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for code, resetCode := range r.resetCodes {
+		if resetCode.Email == email {
+			delete(r.resetCodes, code)
+		}
+	}
+
+	return nil
+}
+
+// GetSessionByRefreshTokenHash method to get session by given refresh token hash.
+func (r *MemoryRepository) GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (models.Session, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, session := range r.sessions {
+		if session.RefreshTokenHash == refreshTokenHash {
+			return session, fiber.StatusOK, nil
+		}
+	}
+
+	return models.Session{}, fiber.StatusNotFound, sql.ErrNoRows
+}
+
+// GetSessionsByUserID method to get all sessions by given user ID (newest first).
+func (r *MemoryRepository) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	sessions := []models.Session{}
+	for _, session := range r.sessions {
+		if session.UserID == userID {
+			sessions = append(sessions, session)
+		}
+	}
+
+	// Sort sessions by creation time, like in the query.
+	sort.Slice(sessions, func(i, j int) bool {
+		return createdAt(sessions[i].CreatedAt).After(createdAt(sessions[j].CreatedAt))
+	})
+
+	return sessions, fiber.StatusOK, nil
+}
+
+// CreateNewSession method to store a new session.
+func (r *MemoryRepository) CreateNewSession(ctx context.Context, s *models.Session) error {
+	r.Lock()
+	defer r.Unlock()
+
+	// Checking unique constraint, like in sessions table.
+	if _, ok := r.sessions[s.ID]; ok {
+		return fmt.Errorf("session with ID %s already exists", s.ID)
+	}
+
+	r.sessions[s.ID] = *s
+
+	return nil
+}
+
+// DeleteSession method to delete session by given ID.
+func (r *MemoryRepository) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	r.Lock()
+	defer r.Unlock()
+
+	delete(r.sessions, id)
+
+	return nil
+}
+
+// DeleteSessionsByUserID method to delete (revoke) all sessions by given user ID.
+func (r *MemoryRepository) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) error {
+	r.Lock()
+	defer r.Unlock()
+
+	for id, session := range r.sessions {
+		if session.UserID == userID {
+			delete(r.sessions, id)
+		}
+	}
+
+	return nil
+}
+
+// GetRoles method to get all roles (ordered by ID).
+func (r *MemoryRepository) GetRoles(ctx context.Context) ([]models.Role, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	roles := make([]models.Role, 0, len(r.roles))
+	for _, role := range r.roles {
+		roles = append(roles, role)
+	}
+	sort.Slice(roles, func(i, j int) bool { return roles[i].ID < roles[j].ID })
+
+	return roles, fiber.StatusOK, nil
+}
+
+// GetRoleCredentials method to get credentials of all roles.
+func (r *MemoryRepository) GetRoleCredentials(ctx context.Context) ([]models.RoleCredential, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	credentials := []models.RoleCredential{}
+	for _, role := range r.roles {
+		for _, credential := range role.Credentials {
+			credentials = append(credentials, models.RoleCredential{RoleID: role.ID, Credential: credential})
+		}
+	}
+
+	return credentials, fiber.StatusOK, nil
+}
+
+// findUser method to get the first user, which matches the given condition.
+func (r *MemoryRepository) findUser(match func(u *models.User) bool) (models.User, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, user := range r.users {
+		if match(&user) {
+			return user, fiber.StatusOK, nil
+		}
+	}
+
+	return models.User{}, fiber.StatusNotFound, sql.ErrNoRows
+}
+
+// findEmailChange method to get the first email change, which matches the given condition.
+func (r *MemoryRepository) findEmailChange(match func(ec *models.EmailChange) bool) (models.EmailChange, int, error) {
+	r.RLock()
+	defer r.RUnlock()
+
+	for _, emailChange := range r.emailChanges {
+		if match(&emailChange) {
+			return emailChange, fiber.StatusOK, nil
+		}
+	}
+
+	return models.EmailChange{}, fiber.StatusNotFound, sql.ErrNoRows
+}
+
+// updateEmailChange method to change email change by given ID with the current time
+// (nothing is changed for unknown ID, like in UPDATE query).
+func (r *MemoryRepository) updateEmailChange(id uuid.UUID, update func(ec *models.EmailChange, now *time.Time)) error {
+	r.Lock()
+	defer r.Unlock()
+
+	emailChange, ok := r.emailChanges[id]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	update(&emailChange, &now)
+	r.emailChanges[id] = emailChange
+
+	return nil
+}
+
+// isUsernameAvailable method to check, if username is not taken by another user (storage must be locked).
+func (r *MemoryRepository) isUsernameAvailable(username string, userID uuid.UUID) bool {
+	for id, user := range r.users {
+		if id != userID && user.Username != nil && strings.EqualFold(*user.Username, username) {
+			return false
+		}
+	}
+	if redirect, ok := r.usernameRedirects[strings.ToLower(username)]; ok && redirect.UserID != userID {
+		return false
+	}
+
+	return true
+}
+
+// updateUser method to change user by given ID and set its update time
+// (nothing is changed for unknown ID, like in UPDATE query).
+func (r *MemoryRepository) updateUser(id uuid.UUID, update func(user *models.User)) error {
+	r.Lock()
+	defer r.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	user.UpdatedAt = &now
+	update(&user)
+	r.users[id] = user
+
+	return nil
+}
+
+// snapshot method to copy users, usernames, email changes, codes and sessions of the storage.
+func (r *MemoryRepository) snapshot() *MemoryRepository {
+	r.RLock()
+	defer r.RUnlock()
+
+	snapshot := NewMemoryRepository()
+	for id, user := range r.users {
+		snapshot.users[id] = user
+	}
+	for username, usernameRedirect := range r.usernameRedirects {
+		snapshot.usernameRedirects[username] = usernameRedirect
+	}
+	for id, emailChange := range r.emailChanges {
+		snapshot.emailChanges[id] = emailChange
+	}
+	for code, activationCode := range r.activationCodes {
+		snapshot.activationCodes[code] = activationCode
+	}
+	for code, resetCode := range r.resetCodes {
+		snapshot.resetCodes[code] = resetCode
+	}
+	for id, session := range r.sessions {
+		snapshot.sessions[id] = session
+	}
+
+	return snapshot
+}
+
+// createdAt func for getting time from optional creation time.
+func createdAt(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
@@ -2,29 +2,182 @@ package database
 
 import (
 	"Komentory/auth/app/queries"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/platform/migrations"
+	"context"
+	"fmt"
+	"time"
 
 	"github.com/Komentory/utilities/database"
+	"github.com/jmoiron/sqlx"
 )
 
 // Queries struct for collect all app queries.
 type Queries struct {
-	*queries.UserQueries           // load queries from User model
-	*queries.ActivationCodeQueries // load queries from ActivationCode model
-	*queries.ResetCodeQueries      // load queries from ResetCode model
+	*queries.UserQueries                // load queries from User model
+	*queries.ActivationCodeQueries      // load queries from ActivationCode model
+	*queries.ResetCodeQueries           // load queries from ResetCode model
+	*queries.PersonalAccessTokenQueries // load queries from PersonalAccessToken model
+	*queries.ServiceClientQueries       // load queries from ServiceClient model
+	*queries.RoleQueries                // load queries from Role and Permission models
+	*queries.SessionQueries             // load queries from Session model
+	*queries.ImpersonationQueries       // load queries from Impersonation model
+	*queries.AuditEventQueries          // load queries from AuditEvent model
+	*queries.EmailChangeQueries         // load queries from EmailChange model
+	*queries.UsernameRedirectQueries    // load queries from UsernameRedirect model
+	*queries.UserExportQueries          // load queries from UserExportArchive model
+	*queries.SchemaQueries              // load queries to database schema
+
+	db      *sqlx.DB      // shared connection pool of all queries (nil in transaction)
+	timeout time.Duration // max duration of one query
+
+	unsupported error // not nil, if queries are not implemented by the database driver
 }
 
-// OpenDBConnection func for opening database connection.
+// OpenDBConnection func for opening database connection pool.
+// It must be called once on the app start, the pool is shared by all requests.
 func OpenDBConnection() (*Queries, error) {
-	// Define a new PostgreSQL connection.
+	// Define a new PostgreSQL connection pool.
 	db, err := database.PostgreSQLConnection()
 	if err != nil {
 		return nil, err
 	}
 
+	return NewQueries(db), nil
+}
+
+// Open func for opening database of the driver from DB_DRIVER: all app queries and storage
+// of the users, codes, sessions and roles credentials. For SQLite, only storage is implemented
+// and other queries fail with error. For PostgreSQL, storage reads from replicas (if any),
+// their health is checked in background until the given context is done.
+func Open(ctx context.Context) (*Queries, Storage, error) {
+	switch driver := configs.DatabaseDriver(); driver {
+	case "postgres":
+		// Define a new PostgreSQL connection pool.
+		db, err := OpenDBConnection()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		// Read users and codes from replicas, if DB_REPLICA_URLS is set.
+		storage, err := WithReplicas(ctx, db)
+		if err != nil {
+			db.Close()
+			return nil, nil, err
+		}
+		return db, storage, nil
+	case "sqlite":
+		// Define a new SQLite database file.
+		storage, err := OpenSQLiteConnection()
+		if err != nil {
+			return nil, nil, err
+		}
+		return NewUnsupportedQueries(driver), storage, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown database driver: %s", driver)
+	}
+}
+
+// NewQueries func for creating all app queries with the given connection pool.
+// Each query is cancelled after DB_QUERY_TIMEOUT_SECONDS (5 seconds by default).
+func NewQueries(db *sqlx.DB) *Queries {
+	timeout := configs.QueryTimeout()
+
+	q := newQueries(queries.WithTimeout(db, timeout))
+	q.db, q.timeout = db, timeout
+
+	return q
+}
+
+// NewUnsupportedQueries func for creating all app queries, which fail with error
+// (for database driver, which implements only repositories, like SQLite).
+func NewUnsupportedQueries(driver string) *Queries {
+	q := newQueries(queries.Unsupported(fmt.Errorf("query is not supported by %s database driver", driver)))
+	q.unsupported = fmt.Errorf("route is not supported by %s database driver, PostgreSQL is required", driver)
+
+	return q
+}
+
+// Unsupported method to get error, if the queries are not implemented by the database driver
+// (so routes, which need them, are refused before the first query). Returns nil for PostgreSQL.
+func (q *Queries) Unsupported() error {
+	if q == nil {
+		return nil
+	}
+
+	return q.unsupported
+}
+
+// newQueries func for creating all app queries with the given connection pool or transaction.
+func newQueries(db queries.Executor) *Queries {
 	return &Queries{
 		// Set queries from models:
-		UserQueries:           &queries.UserQueries{DB: db},           // from User model
-		ActivationCodeQueries: &queries.ActivationCodeQueries{DB: db}, // from ActivationCode model
-		ResetCodeQueries:      &queries.ResetCodeQueries{DB: db},      // from ResetCode model
-	}, nil
+		UserQueries:                &queries.UserQueries{Executor: db},                // from User model
+		ActivationCodeQueries:      &queries.ActivationCodeQueries{Executor: db},      // from ActivationCode model
+		ResetCodeQueries:           &queries.ResetCodeQueries{Executor: db},           // from ResetCode model
+		PersonalAccessTokenQueries: &queries.PersonalAccessTokenQueries{Executor: db}, // from PersonalAccessToken model
+		ServiceClientQueries:       &queries.ServiceClientQueries{Executor: db},       // from ServiceClient model
+		RoleQueries:                &queries.RoleQueries{Executor: db},                // from Role and Permission models
+		SessionQueries:             &queries.SessionQueries{Executor: db},             // from Session model
+		ImpersonationQueries:       &queries.ImpersonationQueries{Executor: db},       // from Impersonation model
+		AuditEventQueries:          &queries.AuditEventQueries{Executor: db},          // from AuditEvent model
+		EmailChangeQueries:         &queries.EmailChangeQueries{Executor: db},         // from EmailChange model
+		UsernameRedirectQueries:    &queries.UsernameRedirectQueries{Executor: db},    // from UsernameRedirect model
+		UserExportQueries:          &queries.UserExportQueries{Executor: db},          // from UserExportArchive model
+		SchemaQueries:              &queries.SchemaQueries{Executor: db},              // to database schema
+	}
+}
+
+// WithTx method to run the given function with all app queries in one transaction.
+// Transaction is committed, if function returns no error, or rolled back otherwise.
+func (q *Queries) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	// Run function in the current transaction, if queries are already in transaction.
+	if q.db == nil {
+		return fn(q)
+	}
+
+	// Begin a new transaction.
+	tx, err := q.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no effect after commit
+
+	// Run function with queries in the transaction (with the same timeout for each query).
+	if err := fn(newQueries(queries.WithTimeout(tx, q.timeout))); err != nil {
+		return err
+	}
+
+	// Commit transaction.
+	return tx.Commit()
+}
+
+// Migrator method to create migrator of the embedded migrations with the shared connection pool.
+func (q *Queries) Migrator() (*migrations.Migrator, error) {
+	// Checking, if queries have connection pool.
+	if q.db == nil {
+		return nil, fmt.Errorf("database is not connected")
+	}
+
+	return migrations.NewMigrator(q.db)
+}
+
+// Ping method to check connection to database (health check of the read replica).
+func (q *Queries) Ping(ctx context.Context) error {
+	// Checking, if queries have connection pool.
+	if q.db == nil {
+		return fmt.Errorf("database is not connected")
+	}
+
+	return q.db.PingContext(ctx)
+}
+
+// Close method to close connection pool (on the app shutdown).
+func (q *Queries) Close() error {
+	// Checking, if queries have connection pool.
+	if q.db == nil {
+		return nil
+	}
+
+	return q.db.Close()
 }
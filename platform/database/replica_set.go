@@ -0,0 +1,179 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// Replica interface to describe read-only copy of the storage with health check.
+type Replica interface {
+	Repository
+	Ping(ctx context.Context) error
+}
+
+// replica struct to describe one read replica with its health.
+type replica struct {
+	Replica
+	number  int   // number of the replica in DB_REPLICA_URLS (for logs, URL has password)
+	healthy int32 // 1, if replica answers to the last health check
+}
+
+// setHealthy method to change health of the replica (changes are logged).
+func (r *replica) setHealthy(healthy bool, err error) {
+	value := int32(0)
+	if healthy {
+		value = 1
+	}
+
+	if atomic.SwapInt32(&r.healthy, value) != value {
+		if healthy {
+			log.Printf("Read replica #%d is healthy again", r.number)
+		} else {
+			log.Printf("Oops... Read replica #%d is unhealthy, primary is used instead! Reason: %v", r.number, err)
+		}
+	}
+}
+
+// isHealthy method to check, if replica can be used for reading.
+func (r *replica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// ReplicaSet struct to describe read replicas, which are used by turns (round-robin).
+// Unhealthy replicas are skipped until the next successful health check.
+type ReplicaSet struct {
+	replicas []*replica
+	next     uint32
+	stop     chan struct{}
+	stopOnce sync.Once
+	checks   sync.WaitGroup // background health checks (waited on close)
+}
+
+// NewReplicaSet func for creating set of the given replicas (all of them are healthy until the first check).
+func NewReplicaSet(replicas ...Replica) *ReplicaSet {
+	s := &ReplicaSet{stop: make(chan struct{})}
+	for i, r := range replicas {
+		s.replicas = append(s.replicas, &replica{Replica: r, number: i + 1, healthy: 1})
+	}
+
+	return s
+}
+
+// OpenReplicaSet func for opening connection pools of the read replicas with the given URLs
+// (with the same settings, as the primary pool). Unavailable replicas are not used until they are healthy.
+func OpenReplicaSet(urls []string) (*ReplicaSet, error) {
+	// Define database connection settings of the primary pool.
+	maxConn, err := strconv.Atoi(os.Getenv("DB_MAX_CONNECTIONS"))
+	if err != nil {
+		return nil, err
+	}
+	maxIdleConn, err := strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNECTIONS"))
+	if err != nil {
+		return nil, err
+	}
+	maxLifetimeConn, err := strconv.Atoi(os.Getenv("DB_MAX_LIFETIME_CONNECTIONS"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Define a new connection pool for each replica (without ping, it's done by health check).
+	replicas := make([]Replica, 0, len(urls))
+	for i, url := range urls {
+		db, err := sqlx.Open("pgx", url)
+		if err != nil {
+			for _, r := range replicas {
+				r.(*Queries).Close()
+			}
+			return nil, fmt.Errorf("error, read replica #%d is not opened, %w", i+1, err)
+		}
+		db.SetMaxOpenConns(maxConn)
+		db.SetMaxIdleConns(maxIdleConn)
+		db.SetConnMaxLifetime(time.Duration(maxLifetimeConn))
+
+		replicas = append(replicas, NewQueries(db))
+	}
+
+	// Check health of the replicas before the first use.
+	s := NewReplicaSet(replicas...)
+	s.CheckHealth(context.Background())
+
+	return s, nil
+}
+
+// CheckHealth method to ping all replicas and mark them as healthy or unhealthy.
+func (s *ReplicaSet) CheckHealth(ctx context.Context) {
+	for _, r := range s.replicas {
+		err := r.Ping(ctx)
+		r.setHealthy(err == nil, err)
+	}
+}
+
+// Start method to check health of the replicas in background with the given interval
+// (until the given context is done or replicas are closed).
+func (s *ReplicaSet) Start(ctx context.Context, interval time.Duration) {
+	s.checks.Add(1)
+	go func() {
+		defer s.checks.Done()
+
+		// Create a new ticker with the check interval.
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-s.stop:
+				return
+			case <-ticker.C:
+				// Each check must end before the next one.
+				checkCtx, cancel := context.WithTimeout(ctx, interval)
+				s.CheckHealth(checkCtx)
+				cancel()
+			}
+		}
+	}()
+}
+
+// pick method to get the next healthy replica (nil, if all replicas are unhealthy).
+func (s *ReplicaSet) pick() *replica {
+	count := uint32(len(s.replicas))
+	if count == 0 {
+		return nil
+	}
+
+	start := atomic.AddUint32(&s.next, 1)
+	for i := uint32(0); i < count; i++ {
+		if r := s.replicas[(start+i)%count]; r.isHealthy() {
+			return r
+		}
+	}
+
+	return nil
+}
+
+// Close method to stop health checks and close connection pools of the replicas (on the app shutdown).
+// Running health check is waited before closing.
+func (s *ReplicaSet) Close() error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	s.checks.Wait()
+
+	var err error
+	for _, r := range s.replicas {
+		if closer, ok := r.Replica.(interface{ Close() error }); ok {
+			if closeErr := closer.Close(); closeErr != nil && err == nil {
+				err = closeErr
+			}
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,284 @@
+package database
+
+import (
+	"Komentory/auth/app/models"
+	"Komentory/auth/pkg/configs"
+	"Komentory/auth/platform/cache"
+	"Komentory/auth/platform/migrations"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// stickyStoreSize is a max count of the users, which are read from the primary in memory store.
+const stickyStoreSize = 100000
+
+// ReplicatedRepository struct to describe storage, which reads users and codes from the read replicas
+// and writes them to the primary. User is read from the primary for a short window after own write
+// (read your writes). If replica has no row (it may be not replicated yet) or fails, the primary is used.
+// Password hashes are always read from the primary (they are compared with the actual password).
+type ReplicatedRepository struct {
+	Repository // primary storage (or its transaction)
+
+	replicas *ReplicaSet   // nil in transaction (all queries use the primary)
+	sticky   cache.Store   // users, which are read from the primary after own write
+	window   time.Duration // time to read user from the primary after own write
+}
+
+// Checking, if replicated storage satisfies storage and cache interfaces.
+var (
+	_ Storage   = (*ReplicatedRepository)(nil)
+	_ UserCache = (*ReplicatedRepository)(nil)
+)
+
+// NewReplicatedRepository func for creating storage with the given primary storage and read replicas.
+func NewReplicatedRepository(primary Repository, replicas *ReplicaSet, sticky cache.Store, window time.Duration) *ReplicatedRepository {
+	return &ReplicatedRepository{Repository: primary, replicas: replicas, sticky: sticky, window: window}
+}
+
+// WithReplicas func for wrapping the given primary storage with the read replicas from DB_REPLICA_URLS.
+// Storage is returned without changes, if no replicas are configured. Health of the replicas is checked
+// in background, until the given context is done (on the app shutdown) or storage is closed.
+func WithReplicas(ctx context.Context, primary Storage) (Storage, error) {
+	// Return the primary storage, if there are no replicas.
+	urls := configs.ReplicaURLs()
+	if len(urls) == 0 {
+		return primary, nil
+	}
+
+	// Define store of the users, which are read from the primary after own write.
+	sticky, err := cache.NewStore(configs.ReplicaStickyStore(), stickyStoreSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open replicas and check their health in background.
+	replicas, err := OpenReplicaSet(urls)
+	if err != nil {
+		return nil, err
+	}
+	replicas.Start(ctx, configs.ReplicaHealthCheckInterval())
+
+	return NewReplicatedRepository(primary, replicas, sticky, configs.ReplicaStickyWindow()), nil
+}
+
+// WithTx method to run the given function with the primary storage in one transaction.
+// Users, changed in the transaction, are read from the primary after its end.
+func (r *ReplicatedRepository) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	return r.Repository.WithTx(ctx, func(tx Repository) error {
+		return fn(&ReplicatedRepository{Repository: tx, sticky: r.sticky, window: r.window})
+	})
+}
+
+// Unwrap method to get the primary storage (or its transaction).
+func (r *ReplicatedRepository) Unwrap() Repository {
+	return r.Repository
+}
+
+// InvalidateUser method to read user from the primary for a short window (after changes outside of the storage).
+func (r *ReplicatedRepository) InvalidateUser(ctx context.Context, id uuid.UUID) error {
+	if r.window <= 0 {
+		return nil
+	}
+
+	return r.sticky.Set(ctx, stickyUserKey(id), []byte{1}, r.window)
+}
+
+// GetUserByID method to get one user by given ID from replica or the primary (after own write).
+func (r *ReplicatedRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
+	if replica := r.replica(ctx, id); replica != nil {
+		user, status, err := replica.GetUserByID(ctx, id)
+		if r.isReplicated(ctx, replica, err) {
+			return user, status, err
+		}
+	}
+
+	return r.Repository.GetUserByID(ctx, id)
+}
+
+// GetUserByEmail method to get one user by given email from replica or the primary (after own write).
+func (r *ReplicatedRepository) GetUserByEmail(ctx context.Context, email string) (models.User, int, error) {
+	if replica := r.replica(ctx, uuid.Nil); replica != nil {
+		user, status, err := replica.GetUserByEmail(ctx, email)
+		if r.isReplicated(ctx, replica, err) && !r.isSticky(ctx, user.ID) {
+			return user, status, err
+		}
+	}
+
+	return r.Repository.GetUserByEmail(ctx, email)
+}
+
+// GetActivationCode method to get activation code from replica or the primary (if it's not replicated yet).
+// Code is read from replica without lock, it's locked only by the re-read in transaction (on the primary).
+func (r *ReplicatedRepository) GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error) {
+	if replica := r.replica(ctx, uuid.Nil); replica != nil {
+		activationCode, status, err := replica.GetActivationCode(ctx, code)
+		if r.isReplicated(ctx, replica, err) {
+			return activationCode, status, err
+		}
+	}
+
+	return r.Repository.GetActivationCode(ctx, code)
+}
+
+// GetResetCode method to get reset code from replica or the primary (if it's not replicated yet).
+// Code is read from replica without lock, it's locked only by the re-read in transaction (on the primary).
+func (r *ReplicatedRepository) GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error) {
+	if replica := r.replica(ctx, uuid.Nil); replica != nil {
+		resetCode, status, err := replica.GetResetCode(ctx, code)
+		if r.isReplicated(ctx, replica, err) {
+			return resetCode, status, err
+		}
+	}
+
+	return r.Repository.GetResetCode(ctx, code)
+}
+
+// CreateNewUser method to store a new user in the primary.
+func (r *ReplicatedRepository) CreateNewUser(ctx context.Context, u *models.User) error {
+	r.stick(ctx, u.ID)
+	return r.Repository.CreateNewUser(ctx, u)
+}
+
+// UpdateUserAttrs method to update user attributes by given ID.
+func (r *ReplicatedRepository) UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserAttrs(ctx, id, u)
+}
+
+// UpdateUserSettings method to update user settings by given ID.
+func (r *ReplicatedRepository) UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserSettings(ctx, id, u)
+}
+
+// UpdateUserPassword method to update user password by given ID.
+func (r *ReplicatedRepository) UpdateUserPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserPassword(ctx, id, passwordHash)
+}
+
+// UpdateUserPasswordHash method to replace hash of the same password by given ID.
+func (r *ReplicatedRepository) UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserPasswordHash(ctx, id, passwordHash)
+}
+
+// UpdateUserPasswordChangeRequired method to set or reset password change requirement by given ID.
+func (r *ReplicatedRepository) UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserPasswordChangeRequired(ctx, id, required)
+}
+
+// UpdateUserStatus method to update user status by given ID.
+func (r *ReplicatedRepository) UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserStatus(ctx, id, status)
+}
+
+// ActivateUser method to activate user by given ID, only if user is not confirmed yet.
+func (r *ReplicatedRepository) ActivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	r.stick(ctx, id)
+	return r.Repository.ActivateUser(ctx, id)
+}
+
+// UpdateUserRole method to update user role by given ID.
+func (r *ReplicatedRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error {
+	r.stick(ctx, id)
+	return r.Repository.UpdateUserRole(ctx, id, role)
+}
+
+// RequestUserDeletion method to mark user account as pending deletion by given ID.
+func (r *ReplicatedRepository) RequestUserDeletion(ctx context.Context, id uuid.UUID) error {
+	r.stick(ctx, id)
+	return r.Repository.RequestUserDeletion(ctx, id)
+}
+
+// CancelUserDeletion method to cancel pending deletion of user account by given ID.
+func (r *ReplicatedRepository) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	r.stick(ctx, id)
+	return r.Repository.CancelUserDeletion(ctx, id)
+}
+
+// Migrator method to create migrator of the primary database.
+func (r *ReplicatedRepository) Migrator() (*migrations.Migrator, error) {
+	primary, ok := r.Repository.(Storage)
+	if !ok {
+		return nil, fmt.Errorf("database is not connected")
+	}
+
+	return primary.Migrator()
+}
+
+// Close method to close read replicas and the primary database (on the app shutdown).
+func (r *ReplicatedRepository) Close() error {
+	if r.replicas != nil {
+		if err := r.replicas.Close(); err != nil {
+			log.Printf("Oops... Read replicas are not closed! Reason: %v", err)
+		}
+	}
+
+	primary, ok := r.Repository.(Storage)
+	if !ok {
+		return nil
+	}
+
+	return primary.Close()
+}
+
+// replica method to get healthy replica for reading the user with the given ID
+// (nil, if the primary must be used: in transaction, after own write or without healthy replicas).
+func (r *ReplicatedRepository) replica(ctx context.Context, id uuid.UUID) *replica {
+	if r.replicas == nil || (id != uuid.Nil && r.isSticky(ctx, id)) {
+		return nil
+	}
+
+	return r.replicas.pick()
+}
+
+// isReplicated method to check, if result of the replica can be returned. Not found rows are read
+// from the primary (they may be not replicated yet), other errors mark replica as unhealthy.
+func (r *ReplicatedRepository) isReplicated(ctx context.Context, replica *replica, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, sql.ErrNoRows), ctx.Err() != nil:
+		return false
+	default:
+		replica.setHealthy(false, err)
+		return false
+	}
+}
+
+// isSticky method to check, if user with the given ID must be read from the primary
+// (errors of the store are only logged, the primary is used).
+func (r *ReplicatedRepository) isSticky(ctx context.Context, id uuid.UUID) bool {
+	if r.window <= 0 {
+		return false
+	}
+
+	_, ok, err := r.sticky.Get(ctx, stickyUserKey(id))
+	if err != nil {
+		log.Printf("Oops... Sticky user is not got from store! Reason: %v", err)
+		return true
+	}
+
+	return ok
+}
+
+// stick method to read user with the given ID from the primary for a short window (before own write).
+func (r *ReplicatedRepository) stick(ctx context.Context, id uuid.UUID) {
+	if err := r.InvalidateUser(ctx, id); err != nil {
+		log.Printf("Oops... Sticky user is not stored! Reason: %v", err)
+	}
+}
+
+// stickyUserKey func for getting key of the user, which is read from the primary.
+func stickyUserKey(id uuid.UUID) string {
+	return "sticky:user:" + id.String()
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"Komentory/auth/app/models"
+	"Komentory/auth/platform/migrations"
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserRepository interface to describe storage of the users.
+type UserRepository interface {
+	GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error)
+	GetUserByEmail(ctx context.Context, email string) (models.User, int, error)
+	GetUserByUsername(ctx context.Context, username string) (models.User, int, error)
+	GetUserPasswordHash(ctx context.Context, id uuid.UUID) (string, int, error)
+	CreateNewUser(ctx context.Context, u *models.User) error
+	UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error
+	UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error
+	UpdateUserPassword(ctx context.Context, id uuid.UUID, passwordHash string) error
+	UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error
+	UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error
+	UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error
+	ActivateUser(ctx context.Context, id uuid.UUID) (bool, error)
+	UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error
+	RequestUserDeletion(ctx context.Context, id uuid.UUID) error
+	CancelUserDeletion(ctx context.Context, id uuid.UUID) error
+}
+
+// UsernameRepository interface to describe storage of the usernames and redirects from the old ones
+// (not a part of Repository, it's supported by PostgreSQL and in-memory storage only).
+type UsernameRepository interface {
+	IsUsernameAvailable(ctx context.Context, username string, userID uuid.UUID) (bool, error)
+	LockUsername(ctx context.Context, username string) error
+	UpdateUserUsername(ctx context.Context, id uuid.UUID, username string, changedBefore time.Time) (int, error)
+	GetUsernameRedirect(ctx context.Context, username string) (models.UsernameRedirect, int, error)
+}
+
+// EmailChangeRepository interface to describe storage of the email changes
+// (not a part of Repository, it's supported by PostgreSQL and in-memory storage only).
+type EmailChangeRepository interface {
+	GetEmailChangeByConfirmCode(ctx context.Context, code string) (models.EmailChange, int, error)
+	GetEmailChangeByRevertCode(ctx context.Context, code string) (models.EmailChange, int, error)
+	GetEmailChangesByUserID(ctx context.Context, userID uuid.UUID) ([]models.EmailChange, int, error)
+	CreateNewEmailChange(ctx context.Context, ec *models.EmailChange) error
+	MarkEmailChangeConfirmed(ctx context.Context, id uuid.UUID) error
+	MarkEmailChangeReverted(ctx context.Context, id uuid.UUID) error
+	LockEmail(ctx context.Context, email string) error
+	UpdateUserEmail(ctx context.Context, id uuid.UUID, oldEmail, newEmail string) (bool, error)
+}
+
+// ActivationCodeRepository interface to describe storage of the activation codes.
+type ActivationCodeRepository interface {
+	GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error)
+	CreateNewActivationCode(ctx context.Context, ac *models.ActivationCode) error
+	DeleteActivationCode(ctx context.Context, code string) error
+}
+
+// ResetCodeRepository interface to describe storage of the reset codes.
+type ResetCodeRepository interface {
+	GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error)
+	CreateNewResetCode(ctx context.Context, rc *models.ResetCode) error
+	DeleteResetCode(ctx context.Context, code string) error
+	DeleteResetCodesByEmail(ctx context.Context, email string) error
+}
+
+// SessionRepository interface to describe storage of the sessions.
+type SessionRepository interface {
+	GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (models.Session, int, error)
+	GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, int, error)
+	CreateNewSession(ctx context.Context, s *models.Session) error
+	DeleteSession(ctx context.Context, id uuid.UUID) error
+	DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) error
+}
+
+// JanitorRepository interface to describe storage of the expired codes and sessions and accounts
+// pending deletion, which are purged by background jobs in batches (not a part of Repository).
+type JanitorRepository interface {
+	DeleteExpiredActivationCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error)
+	DeleteExpiredResetCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error)
+	DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time, limit int) (int64, error)
+	DeleteUsersPendingDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error)
+}
+
+// CredentialsRepository interface to describe storage of the roles credentials.
+type CredentialsRepository interface {
+	GetRoles(ctx context.Context) ([]models.Role, int, error)
+	GetRoleCredentials(ctx context.Context) ([]models.RoleCredential, int, error)
+}
+
+// UnitOfWork interface to describe running of the multi-step operation in one transaction.
+type UnitOfWork interface {
+	WithTx(ctx context.Context, fn func(r Repository) error) error
+}
+
+// Repository interface to describe all storages, which can be replaced (for example, in tests).
+type Repository interface {
+	UnitOfWork
+	UserRepository
+	ActivationCodeRepository
+	ResetCodeRepository
+	SessionRepository
+	CredentialsRepository
+}
+
+// Checking, if database queries satisfy all repository interfaces.
+var (
+	_ Repository            = (*Queries)(nil)
+	_ UsernameRepository    = (*Queries)(nil)
+	_ EmailChangeRepository = (*Queries)(nil)
+	_ JanitorRepository     = (*Queries)(nil)
+)
+
+// Unwrap func for getting storage, which is wrapped by the given decorators (cache, read replicas).
+// In transaction, it's used to run queries, which are not a part of Repository, in the same transaction
+// (changed users must be removed from cache after it, decorators don't know about these changes).
+func Unwrap(r Repository) Repository {
+	for {
+		decorator, ok := r.(interface{ Unwrap() Repository })
+		if !ok {
+			return r
+		}
+		r = decorator.Unwrap()
+	}
+}
+
+// Storage interface to describe opened database of the selected driver (with embedded migrations).
+type Storage interface {
+	Repository
+	Migrator() (*migrations.Migrator, error)
+	Close() error
+}
+
+// Checking, if database queries and SQLite storage satisfy storage interfaces.
+var (
+	_ Storage           = (*Queries)(nil)
+	_ Storage           = (*SQLiteRepository)(nil)
+	_ JanitorRepository = (*SQLiteRepository)(nil)
+)
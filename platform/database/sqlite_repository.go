@@ -0,0 +1,500 @@
+package database
+
+import (
+	"Komentory/auth/app/models"
+	"Komentory/auth/app/queries"
+	"Komentory/auth/platform/migrations"
+	"context"
+	"database/sql"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+
+	_ "modernc.org/sqlite" // load SQLite driver (pure Go, without cgo)
+)
+
+// sqliteParams is a list of DSN params for each connection: foreign keys, waiting for locks
+// and time format, which can be parsed back into time.Time.
+const sqliteParams = "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)&_time_format=sqlite"
+
+// Lists of the table columns, scanned into models (like in PostgreSQL queries).
+const (
+	sqliteUserColumns = `id, created_at, updated_at, email, password_hash, user_status, user_role, user_attrs, user_settings,
+		password_change_required, deletion_requested_at, username, username_changed_at`
+	sqliteActivationCodeColumns = `code, expire_at, user_id`
+	sqliteResetCodeColumns      = `code, expire_at, email`
+	sqliteSessionColumns        = `id, created_at, user_id, refresh_token_hash, expire_at, ip_address, user_agent`
+)
+
+// SQLiteRepository struct to describe storage of the users, codes, sessions and roles credentials
+// in a single SQLite file (for local development and single-node deployments).
+type SQLiteRepository struct {
+	queries.Executor
+
+	db *sqlx.DB // connection pool (nil in transaction)
+}
+
+// Checking, if SQLite storage satisfies all repository interfaces.
+var _ Repository = (*SQLiteRepository)(nil)
+
+// OpenSQLiteConnection func for opening SQLite database file from DB_URL (like "file:auth.db").
+func OpenSQLiteConnection() (*SQLiteRepository, error) {
+	// Define DSN with params for each connection.
+	dsn := os.Getenv("DB_URL")
+	if strings.Contains(dsn, "?") {
+		dsn += "&" + sqliteParams
+	} else {
+		dsn += "?" + sqliteParams
+	}
+
+	// Define a new SQLite connection.
+	db, err := sqlx.Connect("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite allows only one writer at the same time, so all queries are running
+	// one by one with a single connection (transactions are serialized too).
+	db.SetMaxOpenConns(1)
+
+	return NewSQLiteRepository(db), nil
+}
+
+// NewSQLiteRepository func for creating SQLite storage with the given connection pool.
+// Queries are not cancelled by context (waiting for locks is limited by busy_timeout instead),
+// because the driver interrupts the connection on cancel and it can hit the next query.
+func NewSQLiteRepository(db *sqlx.DB) *SQLiteRepository {
+	return &SQLiteRepository{Executor: &sqliteExecutor{Executor: db}, db: db}
+}
+
+// WithTx method to run the given function with the storage in one transaction.
+// Transaction is committed, if function returns no error, or rolled back otherwise.
+func (r *SQLiteRepository) WithTx(ctx context.Context, fn func(r Repository) error) error {
+	// Run function in the current transaction, if storage is already in transaction.
+	if r.db == nil {
+		return fn(r)
+	}
+
+	// Begin a new transaction.
+	tx, err := r.db.BeginTxx(detachedContext{ctx}, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // no effect after commit
+
+	// Run function with storage in the transaction.
+	if err := fn(&SQLiteRepository{Executor: &sqliteExecutor{Executor: tx}}); err != nil {
+		return err
+	}
+
+	// Commit transaction.
+	return tx.Commit()
+}
+
+// Migrator method to create migrator of the embedded SQLite migrations.
+func (r *SQLiteRepository) Migrator() (*migrations.Migrator, error) {
+	return migrations.NewMigrator(r.db)
+}
+
+// Close method to close database file (on the app shutdown).
+func (r *SQLiteRepository) Close() error {
+	return r.db.Close()
+}
+
+// GetUserByID method to get one user by given ID.
+func (r *SQLiteRepository) GetUserByID(ctx context.Context, id uuid.UUID) (models.User, int, error) {
+	return r.getUser(ctx, `SELECT `+sqliteUserColumns+` FROM users WHERE id = ? LIMIT 1`, id)
+}
+
+// GetUserByEmail method to get one user by given email.
+func (r *SQLiteRepository) GetUserByEmail(ctx context.Context, email string) (models.User, int, error) {
+	return r.getUser(ctx, `SELECT `+sqliteUserColumns+` FROM users WHERE email = ? LIMIT 1`, email)
+}
+
+// GetUserByUsername method to get one user by given username (case-insensitive).
+func (r *SQLiteRepository) GetUserByUsername(ctx context.Context, username string) (models.User, int, error) {
+	return r.getUser(ctx, `SELECT `+sqliteUserColumns+` FROM users WHERE lower(username) = lower(?) LIMIT 1`, username)
+}
+
+// getUser method to get one user by the given query.
+func (r *SQLiteRepository) getUser(ctx context.Context, query string, args ...interface{}) (models.User, int, error) {
+	// Define User variable.
+	user := models.User{}
+
+	// Send query to database.
+	err := r.GetContext(ctx, &user, query, args...)
+
+	// Get query result.
+	return user, resultStatus(err), err
+}
+
+// GetUserPasswordHash method to get password hash of the user by given ID.
+func (r *SQLiteRepository) GetUserPasswordHash(ctx context.Context, id uuid.UUID) (string, int, error) {
+	// Define password hash variable.
+	passwordHash := ""
+
+	// Send query to database.
+	err := r.GetContext(ctx, &passwordHash, `SELECT password_hash FROM users WHERE id = ? LIMIT 1`, id)
+
+	// Get query result.
+	return passwordHash, resultStatus(err), err
+}
+
+// CreateNewUser method to store a new user (email must be unique).
+func (r *SQLiteRepository) CreateNewUser(ctx context.Context, u *models.User) error {
+	_, err := r.ExecContext(ctx, `
+	INSERT INTO users (
+		id, created_at, updated_at,
+		email, password_hash, user_status,
+		user_role, user_attrs, user_settings
+	)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		u.ID, u.CreatedAt, u.UpdatedAt,
+		u.Email, u.PasswordHash, u.UserStatus,
+		u.UserRole, &u.UserAttrs, &u.UserSettings,
+	)
+	return err
+}
+
+// UpdateUserAttrs method to update user attributes by given ID.
+func (r *SQLiteRepository) UpdateUserAttrs(ctx context.Context, id uuid.UUID, u *models.UserAttrs) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, user_attrs = ? WHERE id = ?`, time.Now(), u, id)
+}
+
+// UpdateUserSettings method to update user settings by given ID.
+func (r *SQLiteRepository) UpdateUserSettings(ctx context.Context, id uuid.UUID, u *models.UserSettings) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, user_settings = ? WHERE id = ?`, time.Now(), u, id)
+}
+
+// UpdateUserPassword method to update user password (and reset password change requirement) by given ID.
+func (r *SQLiteRepository) UpdateUserPassword(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.exec(ctx,
+		`UPDATE users SET updated_at = ?, password_hash = ?, password_change_required = FALSE WHERE id = ?`,
+		time.Now(), passwordHash, id,
+	)
+}
+
+// UpdateUserPasswordHash method to replace hash of the same password by given ID (updated_at is not changed).
+func (r *SQLiteRepository) UpdateUserPasswordHash(ctx context.Context, id uuid.UUID, passwordHash string) error {
+	return r.exec(ctx, `UPDATE users SET password_hash = ? WHERE id = ?`, passwordHash, id)
+}
+
+// UpdateUserPasswordChangeRequired method to set or reset password change requirement by given ID.
+func (r *SQLiteRepository) UpdateUserPasswordChangeRequired(ctx context.Context, id uuid.UUID, required bool) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, password_change_required = ? WHERE id = ?`, time.Now(), required, id)
+}
+
+// UpdateUserStatus method to update user status by given ID.
+func (r *SQLiteRepository) UpdateUserStatus(ctx context.Context, id uuid.UUID, status int) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, user_status = ? WHERE id = ?`, time.Now(), status, id)
+}
+
+// ActivateUser method to activate user by given ID, only if user is not confirmed yet (status 0).
+func (r *SQLiteRepository) ActivateUser(ctx context.Context, id uuid.UUID) (bool, error) {
+	result, err := r.ExecContext(
+		ctx, `UPDATE users SET updated_at = ?, user_status = 1 WHERE id = ? AND user_status = 0`, time.Now(), id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpdateUserRole method to update user role by given ID.
+func (r *SQLiteRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role int) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, user_role = ? WHERE id = ?`, time.Now(), role, id)
+}
+
+// RequestUserDeletion method to mark user account as pending deletion by given ID.
+func (r *SQLiteRepository) RequestUserDeletion(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, deletion_requested_at = ? WHERE id = ?`, now, now, id)
+}
+
+// CancelUserDeletion method to cancel pending deletion of user account by given ID.
+func (r *SQLiteRepository) CancelUserDeletion(ctx context.Context, id uuid.UUID) error {
+	return r.exec(ctx, `UPDATE users SET updated_at = ?, deletion_requested_at = NULL WHERE id = ?`, time.Now(), id)
+}
+
+// DeleteUsersPendingDeletion method to delete up to the given count of user accounts, which deletion
+// was requested before the given time, with their reset codes (other user data is deleted by cascade).
+// It returns IDs of the deleted users.
+func (r *SQLiteRepository) DeleteUsersPendingDeletion(ctx context.Context, requestedBefore time.Time, limit int) ([]uuid.UUID, error) {
+	// Define IDs of the deleted users.
+	ids := []uuid.UUID{}
+
+	// Define query for the batch of users (the same rows in transaction, because transactions are serialized).
+	batch := `SELECT id FROM users WHERE deletion_requested_at < ? ORDER BY id LIMIT ?`
+
+	// Delete users and their reset codes in one transaction.
+	err := r.WithTx(ctx, func(tx Repository) error {
+		storage := tx.(*SQLiteRepository)
+		if err := storage.SelectContext(ctx, &ids, batch, requestedBefore, limit); err != nil {
+			return err
+		}
+		if err := storage.exec(ctx,
+			`DELETE FROM reset_codes WHERE email IN (SELECT email FROM users WHERE id IN (`+batch+`))`, requestedBefore, limit,
+		); err != nil {
+			return err
+		}
+		return storage.exec(ctx, `DELETE FROM users WHERE id IN (`+batch+`)`, requestedBefore, limit)
+	})
+	if err != nil {
+		return []uuid.UUID{}, err
+	}
+
+	return ids, nil
+}
+
+// GetActivationCode method to get activation code by given string.
+// In transaction, the code can't be changed by others until its end (transactions are serialized).
+func (r *SQLiteRepository) GetActivationCode(ctx context.Context, code string) (models.ActivationCode, int, error) {
+	// Define ActivationCode variable.
+	activationCode := models.ActivationCode{}
+
+	// Send query to database.
+	err := r.GetContext(ctx, &activationCode, `SELECT `+sqliteActivationCodeColumns+` FROM activation_codes WHERE code = ? LIMIT 1`, code)
+
+	// Get query result.
+	return activationCode, resultStatus(err), err
+}
+
+// CreateNewActivationCode method to store a new activation code.
+func (r *SQLiteRepository) CreateNewActivationCode(ctx context.Context, ac *models.ActivationCode) error {
+	return r.exec(ctx,
+		`INSERT INTO activation_codes (`+sqliteActivationCodeColumns+`) VALUES (?, ?, ?)`,
+		ac.Code, ac.ExpireAt, ac.UserID,
+	)
+}
+
+// DeleteActivationCode method to delete activation code.
+func (r *SQLiteRepository) DeleteActivationCode(ctx context.Context, code string) error {
+	return r.exec(ctx, `DELETE FROM activation_codes WHERE code = ?`, code)
+}
+
+// DeleteExpiredActivationCodes method to delete up to the given count of activation codes, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (r *SQLiteRepository) DeleteExpiredActivationCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return r.execCount(ctx,
+		`DELETE FROM activation_codes WHERE code IN (SELECT code FROM activation_codes WHERE expire_at < ? LIMIT ?)`,
+		expiredBefore, limit,
+	)
+}
+
+// GetResetCode method to get reset code by given string.
+// In transaction, the code can't be changed by others until its end (transactions are serialized).
+func (r *SQLiteRepository) GetResetCode(ctx context.Context, code string) (models.ResetCode, int, error) {
+	// Define ResetCode variable.
+	resetCode := models.ResetCode{}
+
+	// Send query to database.
+	err := r.GetContext(ctx, &resetCode, `SELECT `+sqliteResetCodeColumns+` FROM reset_codes WHERE code = ? LIMIT 1`, code)
+
+	// Get query result.
+	return resetCode, resultStatus(err), err
+}
+
+// CreateNewResetCode method to store a new reset code.
+func (r *SQLiteRepository) CreateNewResetCode(ctx context.Context, rc *models.ResetCode) error {
+	return r.exec(ctx,
+		`INSERT INTO reset_codes (`+sqliteResetCodeColumns+`) VALUES (?, ?, ?)`,
+		rc.Code, rc.ExpireAt, rc.Email,
+	)
+}
+
+// DeleteResetCode method to delete reset code.
+func (r *SQLiteRepository) DeleteResetCode(ctx context.Context, code string) error {
+	return r.exec(ctx, `DELETE FROM reset_codes WHERE code = ?`, code)
+}
+
+// DeleteResetCodesByEmail method to delete all reset codes for the given email.
+func (r *SQLiteRepository) DeleteResetCodesByEmail(ctx context.Context, email string) error {
+	return r.exec(ctx, `DELETE FROM reset_codes WHERE email = ?`, email)
+}
+
+// DeleteExpiredResetCodes method to delete up to the given count of reset codes, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (r *SQLiteRepository) DeleteExpiredResetCodes(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return r.execCount(ctx,
+		`DELETE FROM reset_codes WHERE code IN (SELECT code FROM reset_codes WHERE expire_at < ? LIMIT ?)`,
+		expiredBefore, limit,
+	)
+}
+
+// GetSessionByRefreshTokenHash method to get session by given refresh token hash.
+// In transaction, the session can't be changed by others until its end (transactions are serialized).
+func (r *SQLiteRepository) GetSessionByRefreshTokenHash(ctx context.Context, refreshTokenHash string) (models.Session, int, error) {
+	// Define Session variable.
+	session := models.Session{}
+
+	// Send query to database.
+	err := r.GetContext(ctx, &session, `SELECT `+sqliteSessionColumns+` FROM sessions WHERE refresh_token_hash = ? LIMIT 1`, refreshTokenHash)
+
+	// Get query result.
+	return session, resultStatus(err), err
+}
+
+// GetSessionsByUserID method to get all sessions by given user ID (newest first).
+func (r *SQLiteRepository) GetSessionsByUserID(ctx context.Context, userID uuid.UUID) ([]models.Session, int, error) {
+	// Define Session variable.
+	sessions := []models.Session{}
+
+	// Send query to database.
+	err := r.SelectContext(ctx, &sessions, `SELECT `+sqliteSessionColumns+` FROM sessions WHERE user_id = ? ORDER BY created_at DESC`, userID)
+	if err != nil {
+		// Return empty object and 400 error.
+		return sessions, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return sessions, fiber.StatusOK, nil
+}
+
+// CreateNewSession method to store a new session.
+func (r *SQLiteRepository) CreateNewSession(ctx context.Context, s *models.Session) error {
+	return r.exec(ctx, `
+	INSERT INTO sessions (`+sqliteSessionColumns+`)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		s.ID, s.CreatedAt, s.UserID, s.RefreshTokenHash, s.ExpireAt, s.IPAddress, s.UserAgent,
+	)
+}
+
+// DeleteSession method to delete session by given ID.
+func (r *SQLiteRepository) DeleteSession(ctx context.Context, id uuid.UUID) error {
+	return r.exec(ctx, `DELETE FROM sessions WHERE id = ?`, id)
+}
+
+// DeleteSessionsByUserID method to delete (revoke) all sessions by given user ID.
+func (r *SQLiteRepository) DeleteSessionsByUserID(ctx context.Context, userID uuid.UUID) error {
+	return r.exec(ctx, `DELETE FROM sessions WHERE user_id = ?`, userID)
+}
+
+// DeleteExpiredSessions method to delete up to the given count of sessions, which expired before the given time.
+// It returns count of the deleted rows (less than limit means nothing is left).
+func (r *SQLiteRepository) DeleteExpiredSessions(ctx context.Context, expiredBefore time.Time, limit int) (int64, error) {
+	return r.execCount(ctx,
+		`DELETE FROM sessions WHERE id IN (SELECT id FROM sessions WHERE expire_at < ? LIMIT ?)`,
+		expiredBefore, limit,
+	)
+}
+
+// GetRoles method to get all roles (sorted by ID).
+func (r *SQLiteRepository) GetRoles(ctx context.Context) ([]models.Role, int, error) {
+	// Define Role variable.
+	roles := []models.Role{}
+
+	// Send query to database.
+	err := r.SelectContext(ctx, &roles, `SELECT id, created_at, updated_at, name FROM roles ORDER BY id`)
+	if err != nil {
+		// Return empty object and 400 error.
+		return roles, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return roles, fiber.StatusOK, nil
+}
+
+// GetRoleCredentials method to get credentials of all roles.
+func (r *SQLiteRepository) GetRoleCredentials(ctx context.Context) ([]models.RoleCredential, int, error) {
+	// Define RoleCredential variable.
+	roleCredentials := []models.RoleCredential{}
+
+	// Send query to database.
+	err := r.SelectContext(ctx, &roleCredentials, `
+	SELECT rp.role_id, p.credential
+	FROM role_permissions AS rp JOIN permissions AS p ON p.id = rp.permission_id
+	ORDER BY rp.role_id, p.id
+	`)
+	if err != nil {
+		// Return empty object and 400 error.
+		return roleCredentials, fiber.StatusBadRequest, err
+	}
+
+	// Return objects and 200 OK.
+	return roleCredentials, fiber.StatusOK, nil
+}
+
+// exec method to run the given query without rows.
+func (r *SQLiteRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	_, err := r.ExecContext(ctx, query, args...)
+	return err
+}
+
+// execCount method to run the given query without rows and get count of the affected rows.
+func (r *SQLiteRepository) execCount(ctx context.Context, query string, args ...interface{}) (int64, error) {
+	result, err := r.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// resultStatus func for getting status of the query for one row (like in PostgreSQL queries).
+func resultStatus(err error) int {
+	switch err {
+	case nil:
+		// Return 200 OK.
+		return fiber.StatusOK
+	case sql.ErrNoRows:
+		// Return 404 error.
+		return fiber.StatusNotFound
+	default:
+		// Return 400 error.
+		return fiber.StatusBadRequest
+	}
+}
+
+// sqliteExecutor struct to describe executor, which runs queries without cancellation by context.
+type sqliteExecutor struct {
+	queries.Executor
+}
+
+// GetContext method to get one row without cancellation.
+func (e *sqliteExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return e.Executor.GetContext(detachedContext{ctx}, dest, query, args...)
+}
+
+// SelectContext method to get many rows without cancellation.
+func (e *sqliteExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return e.Executor.SelectContext(detachedContext{ctx}, dest, query, args...)
+}
+
+// ExecContext method to run the query without rows without cancellation.
+func (e *sqliteExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return e.Executor.ExecContext(detachedContext{ctx}, query, args...)
+}
+
+// detachedContext struct to describe context with values of the given one, but without deadline and cancellation.
+// The SQLite driver interrupts the connection, when context is done, even after the end of the query
+// (so the next query of the single connection may be interrupted instead).
+type detachedContext struct {
+	context.Context
+}
+
+// Deadline method to return no deadline.
+func (c detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+// Done method to return channel, which is never closed.
+func (c detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+// Err method to return no error.
+func (c detachedContext) Err() error {
+	return nil
+}
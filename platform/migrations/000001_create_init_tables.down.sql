@@ -0,0 +1,4 @@
+-- Delete tables
+DROP TABLE IF EXISTS reset_codes;
+DROP TABLE IF EXISTS activation_codes;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,34 @@
+-- Add UUID extension
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+-- Create users table
+CREATE TABLE users (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    updated_at TIMESTAMP NULL,
+    email VARCHAR (255) NOT NULL UNIQUE,
+    password_hash VARCHAR (255) NOT NULL,
+    user_status INT NOT NULL,
+    user_role INT NOT NULL,
+    user_attrs JSONB NOT NULL,
+    user_settings JSONB NOT NULL
+);
+
+-- Create activation_codes table
+CREATE TABLE activation_codes (
+    code VARCHAR (14) PRIMARY KEY,
+    expire_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Create reset_codes table
+CREATE TABLE reset_codes (
+    code VARCHAR (14) PRIMARY KEY,
+    expire_at TIMESTAMP NOT NULL,
+    email VARCHAR (255) NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX active_users ON users (id) WHERE user_status = 1;
+CREATE INDEX activation_codes_user_id ON activation_codes (user_id);
+CREATE INDEX reset_codes_email ON reset_codes (email);
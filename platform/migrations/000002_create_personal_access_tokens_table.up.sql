@@ -0,0 +1,14 @@
+-- Create personal_access_tokens table
+CREATE TABLE personal_access_tokens (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    name VARCHAR (64) NOT NULL,
+    token_hash VARCHAR (64) NOT NULL UNIQUE,
+    credentials JSONB NOT NULL,
+    expire_at TIMESTAMP NULL,
+    last_used_at TIMESTAMP NULL
+);
+
+-- Add indexes
+CREATE INDEX personal_access_tokens_user_id ON personal_access_tokens (user_id);
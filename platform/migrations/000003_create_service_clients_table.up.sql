@@ -0,0 +1,13 @@
+-- Create service_clients table
+CREATE TABLE service_clients (
+    client_id VARCHAR (64) PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    updated_at TIMESTAMP NULL,
+    name VARCHAR (255) NOT NULL,
+    secret_hash VARCHAR (255) NOT NULL,
+    scopes JSONB NOT NULL,
+    client_status INT NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX active_service_clients ON service_clients (client_id) WHERE client_status = 1;
@@ -0,0 +1,7 @@
+-- Delete foreign key for user roles
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_user_role_fkey;
+
+-- Delete tables
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
@@ -0,0 +1,5 @@
+-- Delete permission to manage users
+DELETE FROM permissions WHERE credential = 'users:manage';
+
+-- Delete tables
+DROP TABLE IF EXISTS sessions;
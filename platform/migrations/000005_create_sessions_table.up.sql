@@ -0,0 +1,18 @@
+-- Create sessions table
+CREATE TABLE sessions (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR (64) NOT NULL UNIQUE,
+    expire_at TIMESTAMP NOT NULL,
+    ip_address VARCHAR (45) NOT NULL,
+    user_agent VARCHAR (255) NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX sessions_user_id ON sessions (user_id);
+
+-- Add permission to manage users (for admin role)
+INSERT INTO permissions (credential) VALUES ('users:manage');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 3, id FROM permissions WHERE credential = 'users:manage';
@@ -0,0 +1,5 @@
+-- Delete permission to impersonate users
+DELETE FROM permissions WHERE credential = 'users:impersonate';
+
+-- Delete tables
+DROP TABLE IF EXISTS impersonations;
@@ -0,0 +1,20 @@
+-- Create impersonations table (audit trail of all admin impersonations)
+CREATE TABLE impersonations (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    admin_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expire_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP NULL,
+    ip_address VARCHAR (45) NOT NULL,
+    user_agent VARCHAR (255) NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX impersonations_admin_id ON impersonations (admin_id);
+CREATE INDEX impersonations_user_id ON impersonations (user_id);
+
+-- Add permission to impersonate users (for admin role)
+INSERT INTO permissions (credential) VALUES ('users:impersonate');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 3, id FROM permissions WHERE credential = 'users:impersonate';
@@ -0,0 +1,8 @@
+-- Delete permission to read audit events
+DELETE FROM permissions WHERE credential = 'audit_events:read';
+
+-- Delete tables
+DROP TABLE IF EXISTS audit_events;
+
+-- Delete functions
+DROP FUNCTION IF EXISTS audit_events_append_only ();
@@ -0,0 +1,33 @@
+-- Create audit_events table (append-only log of security events)
+CREATE TABLE audit_events (
+    id BIGSERIAL PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    event VARCHAR (64) NOT NULL,
+    outcome VARCHAR (16) NOT NULL,
+    actor_id UUID NULL,
+    target_id UUID NULL,
+    ip_address VARCHAR (45) NOT NULL,
+    user_agent VARCHAR (255) NOT NULL,
+    details JSONB NOT NULL DEFAULT '{}'
+);
+
+-- Add indexes
+CREATE INDEX audit_events_actor_id ON audit_events (actor_id, created_at DESC);
+CREATE INDEX audit_events_target_id ON audit_events (target_id, created_at DESC);
+CREATE INDEX audit_events_event ON audit_events (event, created_at DESC);
+
+-- Add trigger to forbid updating and deleting of audit events
+CREATE FUNCTION audit_events_append_only () RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events table is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER audit_events_append_only
+BEFORE UPDATE OR DELETE OR TRUNCATE ON audit_events
+FOR EACH STATEMENT EXECUTE PROCEDURE audit_events_append_only ();
+
+-- Add permission to read audit events (for admin role)
+INSERT INTO permissions (credential) VALUES ('audit_events:read');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 3, id FROM permissions WHERE credential = 'audit_events:read';
@@ -0,0 +1,2 @@
+-- Delete column
+ALTER TABLE users DROP COLUMN IF EXISTS password_change_required;
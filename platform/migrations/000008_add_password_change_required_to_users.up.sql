@@ -0,0 +1,2 @@
+-- Add column to force user to change compromised password
+ALTER TABLE users ADD COLUMN password_change_required BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,5 @@
+-- Delete permission to change own email
+DELETE FROM permissions WHERE credential = 'user_email:own:update';
+
+-- Delete tables
+DROP TABLE IF EXISTS email_changes;
@@ -0,0 +1,22 @@
+-- Create email_changes table
+CREATE TABLE email_changes (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    old_email VARCHAR (255) NOT NULL,
+    new_email VARCHAR (255) NOT NULL,
+    confirm_code VARCHAR (14) NOT NULL UNIQUE,
+    confirm_expire_at TIMESTAMP NOT NULL,
+    revert_code VARCHAR (32) NOT NULL UNIQUE,
+    revert_expire_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP NULL,
+    reverted_at TIMESTAMP NULL
+);
+
+-- Add indexes
+CREATE INDEX email_changes_user_id ON email_changes (user_id);
+
+-- Add permission to change own email (for all roles)
+INSERT INTO permissions (credential) VALUES ('user_email:own:update');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions WHERE permissions.credential = 'user_email:own:update';
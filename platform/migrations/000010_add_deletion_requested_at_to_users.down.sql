@@ -0,0 +1,5 @@
+-- Delete permission to delete own account
+DELETE FROM permissions WHERE credential = 'user_account:own:delete';
+
+-- Delete column
+ALTER TABLE users DROP COLUMN IF EXISTS deletion_requested_at;
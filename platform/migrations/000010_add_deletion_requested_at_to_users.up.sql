@@ -0,0 +1,10 @@
+-- Add time of the account deletion request (account is deleted after grace period)
+ALTER TABLE users ADD COLUMN deletion_requested_at TIMESTAMP NULL;
+
+-- Add indexes
+CREATE INDEX users_deletion_requested_at ON users (deletion_requested_at) WHERE deletion_requested_at IS NOT NULL;
+
+-- Add permission to delete own account (for all roles)
+INSERT INTO permissions (credential) VALUES ('user_account:own:delete');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions WHERE permissions.credential = 'user_account:own:delete';
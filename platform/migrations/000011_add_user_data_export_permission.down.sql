@@ -0,0 +1,2 @@
+-- Delete permission to export own personal data
+DELETE FROM permissions WHERE credential = 'user_data:own:export';
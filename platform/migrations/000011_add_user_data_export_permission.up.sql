@@ -0,0 +1,4 @@
+-- Add permission to export own personal data (for all roles)
+INSERT INTO permissions (credential) VALUES ('user_data:own:export');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions WHERE permissions.credential = 'user_data:own:export';
@@ -0,0 +1,10 @@
+-- Delete permission to change own username
+DELETE FROM permissions WHERE credential = 'user_username:own:update';
+
+-- Delete tables
+DROP TABLE IF EXISTS username_redirects;
+
+-- Delete columns
+DROP INDEX IF EXISTS users_username_lower;
+ALTER TABLE users DROP COLUMN IF EXISTS username_changed_at;
+ALTER TABLE users DROP COLUMN IF EXISTS username;
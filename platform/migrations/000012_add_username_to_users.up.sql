@@ -0,0 +1,21 @@
+-- Add username (handle) of the user
+ALTER TABLE users ADD COLUMN username VARCHAR (64) NULL;
+ALTER TABLE users ADD COLUMN username_changed_at TIMESTAMP NULL;
+
+-- Add case-insensitive uniqueness of usernames
+CREATE UNIQUE INDEX users_username_lower ON users (lower (username));
+
+-- Create username_redirects table (old usernames stay reserved for redirects to their owners)
+CREATE TABLE username_redirects (
+    username VARCHAR (64) PRIMARY KEY, -- in lower case
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Add indexes
+CREATE INDEX username_redirects_user_id ON username_redirects (user_id);
+
+-- Add permission to change own username (for all roles)
+INSERT INTO permissions (credential) VALUES ('user_username:own:update');
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.id, permissions.id FROM roles, permissions WHERE permissions.credential = 'user_username:own:update';
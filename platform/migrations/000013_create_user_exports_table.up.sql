@@ -0,0 +1,12 @@
+-- Create user_exports table (archive is NULL, while the export is pending)
+CREATE TABLE user_exports (
+    id UUID DEFAULT uuid_generate_v4 () PRIMARY KEY,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW (),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    expire_at TIMESTAMP NOT NULL,
+    archive BYTEA NULL
+);
+
+-- Add indexes (only one pending export of the user at the same time)
+CREATE UNIQUE INDEX user_exports_pending_user_id ON user_exports (user_id) WHERE archive IS NULL;
+CREATE INDEX user_exports_expire_at ON user_exports (expire_at);
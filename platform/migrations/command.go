@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// RunCommand func for running migrate command with the given arguments:
+//
+//	up          apply all not applied migrations
+//	down [N]    revert N last applied migrations (1 by default)
+//	status      print current version and all migrations
+func RunCommand(ctx context.Context, m *Migrator, args []string, w io.Writer) error {
+	// Checking, if command is given.
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up | down [N] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		// Apply all migrations.
+		count, err := m.Up(ctx)
+		fmt.Fprintf(w, "%d migration(s) applied\n", count)
+		return err
+	case "down":
+		// Define count of migrations to revert.
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("wrong count of migrations to revert: %s", args[1])
+			}
+			steps = n
+		}
+
+		// Revert migrations.
+		count, err := m.Down(ctx, steps)
+		fmt.Fprintf(w, "%d migration(s) reverted\n", count)
+		return err
+	case "status":
+		// Get current version and migrations.
+		status, err := m.Status(ctx)
+		if err != nil {
+			return err
+		}
+
+		// Print status.
+		fmt.Fprintf(w, "version: %d (dirty: %t)\n", status.Version, status.Dirty)
+		for _, migration := range status.Migrations {
+			state := "pending"
+			if migration.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(w, "%06d_%s\t%s\n", migration.Version, migration.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command: %s", args[0])
+	}
+}
@@ -0,0 +1,308 @@
+package migrations
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// files with versioned SQL migrations (NNNNNN_name.up.sql and NNNNNN_name.down.sql),
+// embedded into the binary.
+//
+//go:embed *.sql
+var files embed.FS
+
+// sqliteFiles with versioned SQL migrations for SQLite (only tables of the users, codes, sessions and roles).
+//
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
+// nilVersion is a version of the database without applied migrations.
+const nilVersion = -1
+
+// lockID is a key of the advisory lock, which allows only one migrator at the same time.
+const lockID = 7_160_524_518
+
+// fileNamePattern is a pattern of the migration file name (like golang-migrate/migrate tool).
+var fileNamePattern = regexp.MustCompile(`^([0-9]+)_(.+)\.(up|down)\.sql$`)
+
+// Migration struct to describe one versioned migration.
+type Migration struct {
+	Version int64  `json:"version"`
+	Name    string `json:"name"`
+	Applied bool   `json:"applied"`
+	up      string
+	down    string
+}
+
+// Status struct to describe current version of the database and all known migrations.
+type Status struct {
+	Version    int64        `json:"version"` // -1, if no migrations are applied
+	Dirty      bool         `json:"dirty"`
+	Migrations []*Migration `json:"migrations"`
+}
+
+// Migrator struct to apply embedded migrations to the database.
+// Applied version is stored in the schema_migrations table, compatible with golang-migrate/migrate tool.
+type Migrator struct {
+	db         *sqlx.DB
+	migrations []*Migration
+}
+
+// NewMigrator func for creating a new migrator with the embedded migrations of the database driver.
+func NewMigrator(db *sqlx.DB) (*Migrator, error) {
+	// Define migrations of the database driver.
+	fsys := fs.FS(files)
+	if db.DriverName() == "sqlite" {
+		sqliteFS, err := fs.Sub(sqliteFiles, "sqlite")
+		if err != nil {
+			return nil, err
+		}
+		fsys = sqliteFS
+	}
+
+	// Load migrations from the embedded files.
+	migrations, err := load(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// load func for reading migrations from the given files, sorted by version.
+func load(fsys fs.FS) ([]*Migration, error) {
+	// Get all SQL files.
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	// Collect up and down migrations by version.
+	byVersion := map[int64]*Migration{}
+	for _, name := range names {
+		// Parse version, name and direction from the file name.
+		match := fileNamePattern.FindStringSubmatch(path.Base(name))
+		if match == nil {
+			return nil, fmt.Errorf("migration file %s has wrong name", name)
+		}
+		version, err := strconv.ParseInt(match[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration file %s has wrong version: %w", name, err)
+		}
+
+		// Read SQL statements.
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = migration
+		}
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has different names: %s and %s", version, migration.Name, match[2])
+		}
+
+		if match[3] == "up" {
+			migration.up = string(data)
+		} else {
+			migration.down = string(data)
+		}
+	}
+
+	// Sort migrations by version.
+	migrations := make([]*Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.up == "" {
+			return nil, fmt.Errorf("migration %d has no up file", migration.Version)
+		}
+		migrations = append(migrations, migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up method to apply all not applied migrations. It returns count of the applied migrations.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	count := 0
+	for {
+		// Apply the next migration (each one in its own transaction).
+		applied, err := m.step(ctx, true)
+		if err != nil || !applied {
+			return count, err
+		}
+		count++
+	}
+}
+
+// Down method to revert the given count of the last applied migrations.
+// It returns count of the reverted migrations.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	count := 0
+	for count < steps {
+		// Revert the last migration (each one in its own transaction).
+		reverted, err := m.step(ctx, false)
+		if err != nil || !reverted {
+			return count, err
+		}
+		count++
+	}
+
+	return count, nil
+}
+
+// Status method to get current version of the database and all known migrations.
+func (m *Migrator) Status(ctx context.Context) (*Status, error) {
+	// Create migrations table, if not exists.
+	if err := m.createTable(ctx); err != nil {
+		return nil, err
+	}
+
+	// Get current version.
+	version, dirty, err := getVersion(ctx, m.db)
+	if err != nil {
+		return nil, err
+	}
+
+	// Mark applied migrations.
+	status := &Status{Version: version, Dirty: dirty}
+	for _, migration := range m.migrations {
+		status.Migrations = append(status.Migrations, &Migration{
+			Version: migration.Version,
+			Name:    migration.Name,
+			Applied: migration.Version <= version,
+		})
+	}
+
+	return status, nil
+}
+
+// step method to apply the next migration (up) or revert the last one (down) in one transaction.
+// It returns false, if there is nothing to do.
+func (m *Migrator) step(ctx context.Context, up bool) (bool, error) {
+	// Create migrations table, if not exists.
+	if err := m.createTable(ctx); err != nil {
+		return false, err
+	}
+
+	// Begin a new transaction.
+	tx, err := m.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback() // no effect after commit
+
+	// Lock migrations for other app instances until the end of transaction
+	// (SQLite database file is locked by the first write of the transaction).
+	if m.db.DriverName() != "sqlite" {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, lockID); err != nil {
+			return false, err
+		}
+	}
+
+	// Get current version (after the lock, it can be changed by other instance).
+	version, dirty, err := getVersion(ctx, tx)
+	if err != nil {
+		return false, err
+	}
+	if dirty {
+		return false, fmt.Errorf("database is dirty at version %d, fix it manually and force the version", version)
+	}
+
+	// Find migration to run and a new version after it.
+	migration, next := m.find(version, up)
+	if migration == nil {
+		if !up && version != nilVersion {
+			return false, fmt.Errorf("migration %d is not found in the binary", version)
+		}
+		return false, nil
+	}
+	statements := migration.up
+	if !up {
+		statements = migration.down
+		if statements == "" {
+			return false, fmt.Errorf("migration %d has no down file", migration.Version)
+		}
+	}
+
+	// Run migration statements.
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return false, fmt.Errorf("migration %d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	// Save a new version (no rows means no applied migrations).
+	if _, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations`); err != nil {
+		return false, err
+	}
+	if next != nilVersion {
+		if _, err := tx.ExecContext(
+			ctx, m.db.Rebind(`INSERT INTO schema_migrations (version, dirty) VALUES (?, false)`), next,
+		); err != nil {
+			return false, err
+		}
+	}
+
+	// Commit transaction.
+	return true, tx.Commit()
+}
+
+// find method to get migration to run from the given version and the database version after it.
+func (m *Migrator) find(version int64, up bool) (*Migration, int64) {
+	for i, migration := range m.migrations {
+		// Next migration after the current version.
+		if up && migration.Version > version {
+			return migration, migration.Version
+		}
+
+		// Migration of the current version and version before it.
+		if !up && migration.Version == version {
+			if i == 0 {
+				return migration, nilVersion
+			}
+			return migration, m.migrations[i-1].Version
+		}
+	}
+
+	return nil, version
+}
+
+// createTable method to create table with the current version (like golang-migrate/migrate tool).
+func (m *Migrator) createTable(ctx context.Context) error {
+	_, err := m.db.ExecContext(
+		ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (version bigint NOT NULL PRIMARY KEY, dirty boolean NOT NULL)`,
+	)
+	return err
+}
+
+// getVersion func for getting current version of the database (-1, if no migrations are applied).
+func getVersion(ctx context.Context, db sqlx.QueryerContext) (int64, bool, error) {
+	// Define version variables.
+	version, dirty := int64(nilVersion), false
+
+	// Send query to database.
+	rows, err := db.QueryxContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`)
+	if err != nil {
+		return version, dirty, err
+	}
+	defer rows.Close()
+
+	// Read version, if exists.
+	if rows.Next() {
+		if err := rows.Scan(&version, &dirty); err != nil {
+			return version, dirty, err
+		}
+	}
+
+	return version, dirty, rows.Err()
+}
@@ -0,0 +1,92 @@
+-- Create roles table
+CREATE TABLE roles (
+    id INTEGER PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    name VARCHAR (64) NOT NULL UNIQUE
+);
+
+-- Create permissions table
+CREATE TABLE permissions (
+    id INTEGER PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    credential VARCHAR (255) NOT NULL UNIQUE
+);
+
+-- Create role_permissions table
+CREATE TABLE role_permissions (
+    role_id INTEGER NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    permission_id INTEGER NOT NULL REFERENCES permissions (id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- Add seed data for roles (same as in utilities.GenerateCredentialsByRole)
+INSERT INTO roles (id, name)
+VALUES
+    (1, 'user'),
+    (2, 'moderator'),
+    (3, 'admin');
+
+-- Add seed data for permissions (same as after all PostgreSQL migrations)
+INSERT INTO permissions (credential)
+VALUES
+    ('projects:create'),
+    ('projects:update'),
+    ('projects:delete'),
+    ('tasks:create'),
+    ('tasks:update'),
+    ('tasks:delete'),
+    ('answers:create'),
+    ('answers:update'),
+    ('answers:delete'),
+    ('user_attrs:update'),
+    ('user_settings:update'),
+    ('user_password:update'),
+    ('projects:own:update'),
+    ('projects:own:delete'),
+    ('tasks:own:update'),
+    ('tasks:own:delete'),
+    ('answers:own:update'),
+    ('answers:own:delete'),
+    ('user_attrs:own:update'),
+    ('user_settings:own:update'),
+    ('user_password:own:update'),
+    ('roles:manage'),
+    ('users:manage'),
+    ('users:impersonate'),
+    ('audit_events:read'),
+    ('user_email:own:update'),
+    ('user_account:own:delete'),
+    ('user_data:own:export'),
+    ('user_username:own:update');
+
+-- Add seed data for role permissions: admin
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 3, id FROM permissions;
+
+-- Add seed data for role permissions: moderator
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 2, id FROM permissions
+WHERE credential IN (
+    'projects:create', 'projects:update',
+    'tasks:create', 'tasks:update',
+    'answers:create', 'answers:update',
+    'user_attrs:update',
+    'projects:own:update', 'projects:own:delete',
+    'tasks:own:update', 'tasks:own:delete',
+    'answers:own:update', 'answers:own:delete',
+    'user_attrs:own:update', 'user_settings:own:update', 'user_password:own:update',
+    'user_email:own:update', 'user_account:own:delete', 'user_data:own:export', 'user_username:own:update'
+);
+
+-- Add seed data for role permissions: user
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT 1, id FROM permissions
+WHERE credential IN (
+    'projects:create', 'tasks:create', 'answers:create',
+    'projects:own:update', 'projects:own:delete',
+    'tasks:own:update', 'tasks:own:delete',
+    'answers:own:update',
+    'user_attrs:own:update', 'user_settings:own:update', 'user_password:own:update',
+    'user_email:own:update', 'user_account:own:delete', 'user_data:own:export', 'user_username:own:update'
+);
@@ -0,0 +1,37 @@
+-- Create users table (JSON attributes are stored as BLOB, UUID as TEXT)
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NULL,
+    email VARCHAR (255) NOT NULL UNIQUE,
+    password_hash VARCHAR (255) NOT NULL,
+    user_status INTEGER NOT NULL,
+    user_role INTEGER NOT NULL REFERENCES roles (id),
+    user_attrs BLOB NOT NULL,
+    user_settings BLOB NOT NULL,
+    password_change_required BOOLEAN NOT NULL DEFAULT FALSE,
+    deletion_requested_at TIMESTAMP NULL,
+    username VARCHAR (64) NULL,
+    username_changed_at TIMESTAMP NULL
+);
+
+-- Add case-insensitive uniqueness of usernames
+CREATE UNIQUE INDEX users_username_lower ON users (lower (username));
+
+-- Create activation_codes table
+CREATE TABLE activation_codes (
+    code VARCHAR (14) PRIMARY KEY,
+    expire_at TIMESTAMP NOT NULL,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE
+);
+
+-- Create reset_codes table
+CREATE TABLE reset_codes (
+    code VARCHAR (14) PRIMARY KEY,
+    expire_at TIMESTAMP NOT NULL,
+    email VARCHAR (255) NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX activation_codes_user_id ON activation_codes (user_id);
+CREATE INDEX reset_codes_email ON reset_codes (email);
@@ -0,0 +1,13 @@
+-- Create sessions table
+CREATE TABLE sessions (
+    id TEXT PRIMARY KEY,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    user_id TEXT NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    refresh_token_hash VARCHAR (64) NOT NULL UNIQUE,
+    expire_at TIMESTAMP NOT NULL,
+    ip_address VARCHAR (45) NOT NULL,
+    user_agent VARCHAR (255) NOT NULL
+);
+
+-- Add indexes
+CREATE INDEX sessions_user_id ON sessions (user_id);